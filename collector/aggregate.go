@@ -0,0 +1,225 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package collector
+
+import (
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Aggregate represents configuration for Collector instances that count
+// events rather than forwarding them.  Events are counted by level, and by
+// message as well if ByMessage is set.  Individual events are dropped, with
+// the exception of FATAL events, which are always passed to Target.
+//
+// Every Interval, summary events are sent to Target by a background
+// goroutine, and the counts are reset.  Intervals without events are
+// skipped.  Without ByMessage, a single summary event reading, e.g.
+// "Aggregate collected 1200 INFO, 340 WARN, 12 ERROR events in 1m0s" is sent,
+// with per-level counts stored as context values.  With ByMessage, a summary
+// event is sent for each distinct level and message, carrying the original
+// level and message and a "count" context value.  Summary events use the most
+// severe level they summarize, so they may be filtered or routed like regular
+// events.  Errors returned by Target for periodic summaries are logged, and
+// the counts are retained for the next interval.  A final summary is sent on
+// Close.  Aggregate collectors also implement a Flush() error method, which
+// sends the pending summary immediately.
+type Aggregate struct {
+	// Required
+	Target cue.Collector
+
+	// Optional
+	Interval  time.Duration // Default: 1 minute
+	ByMessage bool          // If set, events are counted by level and message
+}
+
+// New returns a new collector based on the Aggregate configuration.
+func (a Aggregate) New() cue.Collector {
+	if a.Target == nil {
+		log.Warn("Aggregate.New called to created a collector, but Target param is empty.  Returning nil collector.")
+		return nil
+	}
+	if a.Interval <= 0 {
+		a.Interval = time.Minute
+	}
+	c := &aggregateCollector{
+		Aggregate: a,
+		counts:    make(map[aggregateKey]int),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go c.flushPeriodically()
+	return c
+}
+
+type aggregateKey struct {
+	level   cue.Level
+	message string
+}
+
+type aggregateCollector struct {
+	Aggregate
+
+	mu     sync.Mutex
+	counts map[aggregateKey]int
+	order  []aggregateKey // Keys in order of first occurrence
+	closed bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func (a *aggregateCollector) String() string {
+	return fmt.Sprintf("Aggregate(interval=%s, by_message=%t, target=%s)", a.Interval, a.ByMessage, a.Target)
+}
+
+func (a *aggregateCollector) Collect(event *cue.Event) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if event.Level == cue.FATAL {
+		return a.Target.Collect(event)
+	}
+
+	key := aggregateKey{level: event.Level}
+	if a.ByMessage {
+		key.message = event.Message
+	}
+	if _, present := a.counts[key]; !present {
+		a.order = append(a.order, key)
+	}
+	a.counts[key]++
+	return nil
+}
+
+func (a *aggregateCollector) Close() error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return nil
+	}
+	a.closed = true
+	a.mu.Unlock()
+
+	close(a.stop)
+	<-a.done
+
+	a.mu.Lock()
+	err := a.flush(time.Now())
+	a.mu.Unlock()
+
+	closer, ok := a.Target.(io.Closer)
+	if !ok {
+		return err
+	}
+	if closeErr := closer.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (a *aggregateCollector) Flush() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.flush(time.Now())
+}
+
+func (a *aggregateCollector) flushPeriodically() {
+	defer close(a.done)
+
+	ticker := time.NewTicker(a.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-a.stop:
+			return
+		case now := <-ticker.C:
+			a.mu.Lock()
+			err := a.flush(now)
+			a.mu.Unlock()
+			if err != nil {
+				log.Errorf(err, "Failed to send aggregate summary for %s", a)
+			}
+		}
+	}
+}
+
+// flush must be called with a.mu held.
+func (a *aggregateCollector) flush(now time.Time) error {
+	if len(a.order) == 0 {
+		return nil
+	}
+
+	var summaries []*cue.Event
+	if a.ByMessage {
+		summaries = a.messageSummaries(now)
+	} else {
+		summaries = []*cue.Event{a.levelSummary(now)}
+	}
+	for _, summary := range summaries {
+		err := a.Target.Collect(summary)
+		if err != nil {
+			return err
+		}
+	}
+
+	a.counts = make(map[aggregateKey]int)
+	a.order = nil
+	return nil
+}
+
+func (a *aggregateCollector) levelSummary(now time.Time) *cue.Event {
+	context := cue.NewContext("github.com/bobziuchkovski/cue/collector").WithValue("interval", a.Interval.String())
+	var parts []string
+	level := cue.TRACE
+	for lvl := cue.TRACE; lvl > cue.FATAL; lvl-- {
+		count := a.counts[aggregateKey{level: lvl}]
+		if count == 0 {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%d %s", count, lvl))
+		context = context.WithValue(strings.ToLower(lvl.String()), count)
+		level = lvl
+	}
+	return &cue.Event{
+		Time:    now,
+		Level:   level,
+		Context: context,
+		Message: fmt.Sprintf("Aggregate collected %s events in %s", strings.Join(parts, ", "), a.Interval),
+	}
+}
+
+func (a *aggregateCollector) messageSummaries(now time.Time) []*cue.Event {
+	var summaries []*cue.Event
+	for _, key := range a.order {
+		summaries = append(summaries, &cue.Event{
+			Time:    now,
+			Level:   key.level,
+			Context: cue.NewContext("github.com/bobziuchkovski/cue/collector").WithValue("count", a.counts[key]).WithValue("interval", a.Interval.String()),
+			Message: key.message,
+		})
+	}
+	return summaries
+}
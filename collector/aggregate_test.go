@@ -0,0 +1,162 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package collector
+
+import (
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/internal/cuetest"
+	"testing"
+	"time"
+)
+
+func levelEventAt(level cue.Level, message string, t time.Time) *cue.Event {
+	event := cuetest.GenerateEvent(level, cuetest.DebugEvent.Context, message, nil, 0)
+	event.Time = t
+	return event
+}
+
+func TestAggregateNilCollector(t *testing.T) {
+	c := Aggregate{}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when the target is missing, but got %s instead", c)
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	capture := cuetest.NewCapturingCollector()
+	c := Aggregate{Target: capture, Interval: time.Hour}.New()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		c.Collect(levelEventAt(cue.INFO, "info", start))
+	}
+	c.Collect(levelEventAt(cue.WARN, "warn", start))
+	c.Collect(levelEventAt(cue.ERROR, "error", start))
+	c.Collect(levelEventAt(cue.ERROR, "error", start))
+	if len(capture.Captured()) != 0 {
+		t.Fatalf("Expected individual events to be suppressed, but saw %d events instead", len(capture.Captured()))
+	}
+
+	err := c.(interface {
+		Flush() error
+	}).Flush()
+	if err != nil {
+		t.Fatalf("Encountered unexpected error flushing the summary: %s", err)
+	}
+	captured := capture.Captured()
+	if len(captured) != 1 {
+		t.Fatalf("Expected a single summary event after flushing, but saw %d events instead", len(captured))
+	}
+	summary := captured[0]
+	if summary.Level != cue.ERROR || summary.Message != "Aggregate collected 3 INFO, 1 WARN, 2 ERROR events in 1h0m0s" {
+		t.Errorf("Expected an ERROR summary event, but saw %s %q instead", summary.Level, summary.Message)
+	}
+	fields := summary.Context.Fields()
+	if fields["info"] != 3 || fields["warn"] != 1 || fields["error"] != 2 || fields["debug"] != nil {
+		t.Errorf("Expected the summary context to include per-level counts, but saw %v instead", fields)
+	}
+
+	c.Collect(levelEventAt(cue.DEBUG, "debug", start))
+	cuetest.CloseCollector(c)
+	captured = capture.Captured()
+	if len(captured) != 2 {
+		t.Fatalf("Expected a final summary event on close, but saw %d events instead", len(captured))
+	}
+	if captured[1].Level != cue.DEBUG || captured[1].Message != "Aggregate collected 1 DEBUG events in 1h0m0s" {
+		t.Errorf("Expected a DEBUG summary event, but saw %s %q instead", captured[1].Level, captured[1].Message)
+	}
+}
+
+func TestAggregateInterval(t *testing.T) {
+	capture := cuetest.NewCapturingCollector()
+	c := Aggregate{Target: capture, Interval: 10 * time.Millisecond}.New()
+	defer cuetest.CloseCollector(c)
+
+	// The summary is sent once the interval elapses, without waiting for
+	// further events.
+	c.Collect(cuetest.InfoEvent)
+	capture.WaitCaptured(1, 5*time.Second)
+	summary := capture.Captured()[0]
+	if summary.Level != cue.INFO || summary.Message != "Aggregate collected 1 INFO events in 10ms" {
+		t.Errorf("Expected an INFO summary event, but saw %s %q instead", summary.Level, summary.Message)
+	}
+
+	// Intervals without events are skipped
+	time.Sleep(50 * time.Millisecond)
+	if len(capture.Captured()) != 1 {
+		t.Errorf("Expected no summaries for empty intervals, but saw %d events instead", len(capture.Captured()))
+	}
+}
+
+func TestAggregateFatal(t *testing.T) {
+	capture := cuetest.NewCapturingCollector()
+	c := Aggregate{Target: capture}.New()
+
+	c.Collect(cuetest.InfoEvent)
+	c.Collect(cuetest.FatalEvent)
+	captured := capture.Captured()
+	if len(captured) != 1 || captured[0] != cuetest.FatalEvent {
+		t.Fatalf("Expected the FATAL event to be passed through, but saw %v instead", captured)
+	}
+
+	cuetest.CloseCollector(c)
+	captured = capture.Captured()
+	if len(captured) != 2 || captured[1].Context.Fields()["fatal"] != nil {
+		t.Errorf("Expected the summary to exclude FATAL events, but saw %v instead", captured)
+	}
+}
+
+func TestAggregateByMessage(t *testing.T) {
+	capture := cuetest.NewCapturingCollector()
+	c := Aggregate{Target: capture, ByMessage: true}.New()
+
+	start := time.Now()
+	c.Collect(levelEventAt(cue.INFO, "first", start))
+	c.Collect(levelEventAt(cue.WARN, "second", start))
+	c.Collect(levelEventAt(cue.INFO, "first", start))
+	cuetest.CloseCollector(c)
+
+	captured := capture.Captured()
+	if len(captured) != 2 {
+		t.Fatalf("Expected a summary event per distinct message, but saw %d events instead", len(captured))
+	}
+	expectations := []struct {
+		level   cue.Level
+		message string
+		count   int
+	}{
+		{cue.INFO, "first", 2},
+		{cue.WARN, "second", 1},
+	}
+	for i, e := range expectations {
+		if captured[i].Level != e.level || captured[i].Message != e.message || captured[i].Context.Fields()["count"] != e.count {
+			t.Errorf("Expected a %s summary for %q with count=%d, but saw %s %q with %v instead", e.level, e.message, e.count, captured[i].Level, captured[i].Message, captured[i].Context.Fields())
+		}
+	}
+}
+
+func TestAggregateString(t *testing.T) {
+	c := Aggregate{Target: cuetest.NewCapturingCollector()}.New()
+
+	// Ensure nothing panics
+	_ = fmt.Sprint(c)
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package collector
+
+import (
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"sync"
+	"time"
+)
+
+// Batch represents configuration for Collector instances that accumulate
+// events and submit them in bulk.  Events are buffered until MaxSize events
+// are pending or MaxDelay has elapsed since the first pending event was
+// collected, whichever comes first.  The pending events are then passed to
+// Flush.  This is useful for building collectors for services that support
+// batch ingestion.
+//
+// Flush is never called concurrently, so implementations needn't perform
+// their own synchronization.  The events slice passed to Flush is owned by
+// the callee and may be retained.  If Flush returns an error, the batch is
+// discarded.  When the flush was triggered by Collect, the error is returned
+// from Collect so that cue's worker may handle the failure.  When the flush
+// was triggered by MaxDelay, the error is logged instead.  Pending events are
+// flushed on Close.  Batch collectors also implement a Flush() error method,
+// so pending events are flushed periodically when registered via
+// cue.CollectAsyncWith with a FlushInterval.
+type Batch struct {
+	// Required
+	Flush func(events []*cue.Event) error
+
+	// Optional
+	MaxSize  int           // Default: 100
+	MaxDelay time.Duration // Default: 5 seconds
+}
+
+// New returns a new collector based on the Batch configuration.
+func (b Batch) New() cue.Collector {
+	if b.Flush == nil {
+		log.Warn("Batch.New called to created a collector, but Flush param is empty.  Returning nil collector.")
+		return nil
+	}
+	if b.MaxSize <= 0 {
+		b.MaxSize = 100
+	}
+	if b.MaxDelay <= 0 {
+		b.MaxDelay = 5 * time.Second
+	}
+	return &batchCollector{Batch: b}
+}
+
+type batchCollector struct {
+	Batch
+
+	mu      sync.Mutex
+	pending []*cue.Event
+	timer   *time.Timer
+	closed  bool
+}
+
+func (b *batchCollector) String() string {
+	return fmt.Sprintf("Batch(max_size=%d, max_delay=%s)", b.MaxSize, b.MaxDelay)
+}
+
+func (b *batchCollector) Collect(event *cue.Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.pending = append(b.pending, event)
+	if len(b.pending) >= b.MaxSize {
+		return b.flush()
+	}
+	if b.timer == nil {
+		b.timer = time.AfterFunc(b.MaxDelay, b.flushDelayed)
+	}
+	return nil
+}
+
+func (b *batchCollector) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	return b.flush()
+}
+
+func (b *batchCollector) Flush() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.flush()
+}
+
+func (b *batchCollector) flushDelayed() {
+	b.mu.Lock()
+	err := b.flush()
+	b.mu.Unlock()
+
+	if err != nil {
+		log.Errorf(err, "Failed to flush pending events for %s", b)
+	}
+}
+
+// flush must be called with b.mu held.
+func (b *batchCollector) flush() error {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	if len(b.pending) == 0 {
+		return nil
+	}
+
+	events := b.pending
+	b.pending = nil
+	return b.Batch.Flush(events)
+}
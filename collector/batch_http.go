@@ -0,0 +1,98 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package collector
+
+import (
+	"github.com/bobziuchkovski/cue"
+	"net/http"
+	"time"
+)
+
+// BatchHTTP represents configuration for http-based Collector instances that
+// submit events in bulk.  Events are accumulated as with Batch.  The pending
+// events are then passed to RequestFormatter to generate a single http
+// request, which is submitted as with HTTP: a cue-specific User-Agent header
+// is set, 4XX and 5XX status codes are treated as errors, and Retry-After
+// headers are honored.  If submission fails, the batch is discarded.  Pending
+// events are flushed on Close, and periodically when registered via
+// cue.CollectAsyncWith with a FlushInterval.
+type BatchHTTP struct {
+	// Required
+	RequestFormatter func(events []*cue.Event) (*http.Request, error)
+
+	// Optional
+	MaxSize       int           // Default: 100
+	MaxDelay      time.Duration // Default: 5 seconds
+	Client        *http.Client  // Default: a client shared by HTTP collectors
+	Timeout       time.Duration // Default: 30 seconds.  See HTTP for details.
+	MaxRetryAfter time.Duration // Default: 30 seconds.  See HTTP for details.
+}
+
+// New returns a new collector based on the BatchHTTP configuration.
+func (b BatchHTTP) New() cue.Collector {
+	if b.RequestFormatter == nil {
+		log.Warn("BatchHTTP.New called to created a collector, but RequestFormatter param is empty.  Returning nil collector.")
+		return nil
+	}
+	if b.Client == nil {
+		b.Client = clientWithTimeout(b.Timeout)
+	}
+	if b.MaxRetryAfter == 0 {
+		b.MaxRetryAfter = 30 * time.Second
+	}
+
+	c := &batchHTTPCollector{
+		BatchHTTP: b,
+		http:      &httpCollector{HTTP: HTTP{Client: b.Client, MaxRetryAfter: b.MaxRetryAfter}},
+	}
+	c.batch = Batch{Flush: c.flush, MaxSize: b.MaxSize, MaxDelay: b.MaxDelay}.New().(*batchCollector)
+	return c
+}
+
+type batchHTTPCollector struct {
+	BatchHTTP
+	http  *httpCollector
+	batch *batchCollector
+}
+
+func (b *batchHTTPCollector) String() string {
+	return "BatchHTTP(unknown, please wrap the BatchHTTP collector and implement String())"
+}
+
+func (b *batchHTTPCollector) Collect(event *cue.Event) error {
+	return b.batch.Collect(event)
+}
+
+func (b *batchHTTPCollector) Close() error {
+	return b.batch.Close()
+}
+
+func (b *batchHTTPCollector) Flush() error {
+	return b.batch.Flush()
+}
+
+func (b *batchHTTPCollector) flush(events []*cue.Event) error {
+	request, err := b.RequestFormatter(events)
+	if err != nil {
+		return err
+	}
+	return b.http.send(request)
+}
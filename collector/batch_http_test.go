@@ -0,0 +1,110 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package collector
+
+import (
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/internal/cuetest"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBatchHTTPNilCollector(t *testing.T) {
+	c := BatchHTTP{}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when the http request formatter is missing, but got %s instead", c)
+	}
+}
+
+func TestBatchHTTP(t *testing.T) {
+	recorder := cuetest.NewHTTPRequestRecorder()
+	s := httptest.NewServer(recorder)
+	defer s.Close()
+
+	c := BatchHTTP{RequestFormatter: newBatchRequestFormatter(s.URL), MaxSize: 2, MaxDelay: time.Hour}.New()
+	c.Collect(cuetest.DebugEvent)
+	if len(recorder.Requests()) != 0 {
+		t.Errorf("Expected no requests before the batch is full, but saw %d", len(recorder.Requests()))
+	}
+	err := c.Collect(cuetest.InfoEvent)
+	if err != nil {
+		t.Errorf("Encountered unexpected error: %s", err)
+	}
+	c.Collect(cuetest.WarnEvent)
+	cuetest.CloseCollector(c)
+
+	requests := recorder.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("Expected exactly 2 requests to be sent but saw %d instead", len(requests))
+	}
+	checkBatchRequest(t, requests[0], "debug event\ninfo event")
+	checkBatchRequest(t, requests[1], "warn event")
+	if !strings.HasPrefix(requests[0].Header.Get("User-Agent"), "github.com/bobziuchkovski/cue") {
+		t.Errorf("Expected a cue User-Agent header, but saw %q instead", requests[0].Header.Get("User-Agent"))
+	}
+}
+
+func TestBatchHTTPErrorCode(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer s.Close()
+
+	c := BatchHTTP{RequestFormatter: newBatchRequestFormatter(s.URL), MaxSize: 1}.New()
+	defer cuetest.CloseCollector(c)
+	err := c.Collect(cuetest.DebugEvent)
+	if err == nil {
+		t.Error("Expected to receive an error for a 4XX status code but didn't")
+	}
+}
+
+func TestBatchHTTPString(t *testing.T) {
+	c := BatchHTTP{RequestFormatter: newBatchRequestFormatter("http://localhost")}.New()
+	defer cuetest.CloseCollector(c)
+
+	// Ensure nothing panics
+	_ = fmt.Sprint(c)
+}
+
+func newBatchRequestFormatter(url string) func([]*cue.Event) (*http.Request, error) {
+	return func(events []*cue.Event) (*http.Request, error) {
+		messages := make([]string, len(events))
+		for i, event := range events {
+			messages[i] = event.Message
+		}
+		return http.NewRequest("POST", url, strings.NewReader(strings.Join(messages, "\n")))
+	}
+}
+
+func checkBatchRequest(t *testing.T, req *http.Request, expected string) {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		t.Fatalf("Failed to read request body: %s", err)
+	}
+	if string(body) != expected {
+		t.Errorf("Expected request body %q, but saw %q instead", expected, string(body))
+	}
+}
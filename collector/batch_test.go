@@ -0,0 +1,158 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/internal/cuetest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type batchRecorder struct {
+	mu      sync.Mutex
+	fail    bool
+	batches [][]*cue.Event
+}
+
+func (br *batchRecorder) Flush(events []*cue.Event) error {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	if br.fail {
+		return errors.New("flush failed")
+	}
+	br.batches = append(br.batches, events)
+	return nil
+}
+
+func (br *batchRecorder) Batches() [][]*cue.Event {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	return append([][]*cue.Event(nil), br.batches...)
+}
+
+func TestBatchNilCollector(t *testing.T) {
+	c := Batch{}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when the flush func is missing, but got %s instead", c)
+	}
+}
+
+func TestBatchMaxSize(t *testing.T) {
+	recorder := &batchRecorder{}
+	c := Batch{Flush: recorder.Flush, MaxSize: 2, MaxDelay: time.Hour}.New()
+	defer cuetest.CloseCollector(c)
+
+	c.Collect(cuetest.DebugEvent)
+	if len(recorder.Batches()) != 0 {
+		t.Fatalf("Expected no batches prior to reaching MaxSize but saw %d instead", len(recorder.Batches()))
+	}
+	c.Collect(cuetest.InfoEvent)
+	c.Collect(cuetest.WarnEvent)
+
+	batches := recorder.Batches()
+	if len(batches) != 1 {
+		t.Fatalf("Expected exactly 1 batch but saw %d instead", len(batches))
+	}
+	if len(batches[0]) != 2 || batches[0][0] != cuetest.DebugEvent || batches[0][1] != cuetest.InfoEvent {
+		t.Errorf("Expected the batch to contain the debug and info events, in order, but got %v instead", batches[0])
+	}
+}
+
+func TestBatchFlush(t *testing.T) {
+	recorder := &batchRecorder{}
+	c := Batch{Flush: recorder.Flush, MaxDelay: time.Hour}.New()
+	defer cuetest.CloseCollector(c)
+
+	flusher := c.(interface {
+		Flush() error
+	})
+	if err := flusher.Flush(); err != nil || len(recorder.Batches()) != 0 {
+		t.Fatalf("Expected flushing an empty batch to be a no-op, but saw err=%v and %d batches", err, len(recorder.Batches()))
+	}
+	c.Collect(cuetest.DebugEvent)
+	if err := flusher.Flush(); err != nil {
+		t.Fatalf("Encountered unexpected error flushing batch: %s", err)
+	}
+	batches := recorder.Batches()
+	if len(batches) != 1 || len(batches[0]) != 1 || batches[0][0] != cuetest.DebugEvent {
+		t.Errorf("Expected Flush to submit the pending debug event, but got %v instead", batches)
+	}
+}
+
+func TestBatchMaxDelay(t *testing.T) {
+	recorder := &batchRecorder{}
+	c := Batch{Flush: recorder.Flush, MaxDelay: 10 * time.Millisecond}.New()
+	defer cuetest.CloseCollector(c)
+
+	c.Collect(cuetest.DebugEvent)
+	for i := 0; i < 100 && len(recorder.Batches()) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	batches := recorder.Batches()
+	if len(batches) != 1 || len(batches[0]) != 1 {
+		t.Errorf("Expected a single batch containing a single event after MaxDelay, but got %v instead", batches)
+	}
+}
+
+func TestBatchFlushOnClose(t *testing.T) {
+	recorder := &batchRecorder{}
+	c := Batch{Flush: recorder.Flush, MaxDelay: time.Hour}.New()
+
+	c.Collect(cuetest.DebugEvent)
+	c.Collect(cuetest.InfoEvent)
+	cuetest.CloseCollector(c)
+	cuetest.CloseCollector(c)
+
+	batches := recorder.Batches()
+	if len(batches) != 1 || len(batches[0]) != 2 {
+		t.Errorf("Expected a single batch containing 2 events on close, but got %v instead", batches)
+	}
+}
+
+func TestBatchFlushError(t *testing.T) {
+	recorder := &batchRecorder{fail: true}
+	c := Batch{Flush: recorder.Flush, MaxSize: 1, MaxDelay: time.Hour}.New()
+	defer cuetest.CloseCollector(c)
+
+	err := c.Collect(cuetest.DebugEvent)
+	if err == nil {
+		t.Error("Expected Collect to return the flush error but it didn't")
+	}
+
+	recorder.fail = false
+	c.Collect(cuetest.InfoEvent)
+	batches := recorder.Batches()
+	if len(batches) != 1 || len(batches[0]) != 1 || batches[0][0] != cuetest.InfoEvent {
+		t.Errorf("Expected the failed batch to be discarded, but got %v instead", batches)
+	}
+}
+
+func TestBatchString(t *testing.T) {
+	c := Batch{Flush: (&batchRecorder{}).Flush}.New()
+
+	// Ensure nothing panics
+	_ = fmt.Sprint(c)
+}
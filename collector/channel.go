@@ -0,0 +1,84 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package collector
+
+import (
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"sync"
+	"sync/atomic"
+)
+
+// Channel returns a collector that sends events to the returned channel.  The
+// channel is buffered according to the buffer param.  Sends never block: if
+// the channel's buffer is full, the event is dropped and a drop counter is
+// incremented instead.  The channel is closed when the collector is closed.
+//
+// Channel is useful for bridging cue events into custom processing goroutines.
+// Events received from the channel are shared with other collectors and must
+// not be altered in place.
+func Channel(buffer int) (cue.Collector, <-chan *cue.Event) {
+	c := &channelCollector{
+		events: make(chan *cue.Event, buffer),
+	}
+	return c, c.events
+}
+
+type channelCollector struct {
+	// Drops is accessed via atomic operations.  It's the first field to ensure
+	// 64-bit alignment.  See the sync/atomic docs for details.
+	drops uint64
+
+	mu     sync.Mutex
+	events chan *cue.Event
+	closed bool
+}
+
+func (c *channelCollector) String() string {
+	return fmt.Sprintf("Channel(buffer=%d, drops=%d)", cap(c.events), atomic.LoadUint64(&c.drops))
+}
+
+func (c *channelCollector) Collect(event *cue.Event) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		atomic.AddUint64(&c.drops, 1)
+		return nil
+	}
+
+	select {
+	case c.events <- event:
+		// No-op...event is queued
+	default:
+		atomic.AddUint64(&c.drops, 1)
+	}
+	return nil
+}
+
+func (c *channelCollector) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.closed {
+		close(c.events)
+		c.closed = true
+	}
+	return nil
+}
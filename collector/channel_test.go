@@ -0,0 +1,79 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package collector
+
+import (
+	"fmt"
+	"github.com/bobziuchkovski/cue/internal/cuetest"
+	"strings"
+	"testing"
+)
+
+func TestChannel(t *testing.T) {
+	c, events := Channel(2)
+	c.Collect(cuetest.DebugEvent)
+	c.Collect(cuetest.ErrorEvent)
+
+	event := <-events
+	if event != cuetest.DebugEvent {
+		t.Errorf("Expected to receive %v but received %v instead", cuetest.DebugEvent, event)
+	}
+	event = <-events
+	if event != cuetest.ErrorEvent {
+		t.Errorf("Expected to receive %v but received %v instead", cuetest.ErrorEvent, event)
+	}
+
+	cuetest.CloseCollector(c)
+	_, open := <-events
+	if open {
+		t.Error("Expected the event channel to be closed, but it's still open")
+	}
+
+	// Collecting after close should be a silent no-op
+	err := c.Collect(cuetest.DebugEvent)
+	if err != nil {
+		t.Errorf("Encountered unexpected error: %s", err)
+	}
+}
+
+func TestChannelDrops(t *testing.T) {
+	c, events := Channel(1)
+	for i := 0; i < 3; i++ {
+		err := c.Collect(cuetest.DebugEvent)
+		if err != nil {
+			t.Errorf("Encountered unexpected error: %s", err)
+		}
+	}
+
+	if len(events) != 1 {
+		t.Errorf("Expected exactly 1 buffered event but saw %d instead", len(events))
+	}
+	if !strings.Contains(fmt.Sprint(c), "drops=2") {
+		t.Errorf("Expected the collector to report 2 drops, but saw %s instead", c)
+	}
+}
+
+func TestChannelString(t *testing.T) {
+	c, _ := Channel(1)
+
+	// Ensure nothing panics
+	_ = fmt.Sprint(c)
+}
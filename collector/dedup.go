@@ -0,0 +1,169 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package collector
+
+import (
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"io"
+	"sync"
+	"time"
+)
+
+// Dedup represents configuration for Collector instances that suppress
+// repeated identical events.  Events are considered identical if they share
+// the same level, message, and error string.  The first event for a given
+// combination is passed to Target immediately and, once delivered, opens a
+// window lasting Window.  Identical events collected while the window is open are counted
+// and dropped.  When the window closes, or when the collector is closed, the
+// most recent repeat is sent to Target with a "repeated" context value set to
+// the number of dropped repeats.  Windows without repeats close silently.
+//
+// Windows are closed by timers running on separate goroutines, so the
+// collector serializes calls to Target.  Errors returned by Target when a
+// window closes are logged.
+type Dedup struct {
+	// Required
+	Target cue.Collector
+
+	// Optional
+	Window time.Duration // Default: 1 minute
+}
+
+// New returns a new collector based on the Dedup configuration.
+func (d Dedup) New() cue.Collector {
+	if d.Target == nil {
+		log.Warn("Dedup.New called to created a collector, but Target param is empty.  Returning nil collector.")
+		return nil
+	}
+	if d.Window <= 0 {
+		d.Window = time.Minute
+	}
+	return &dedupCollector{
+		Dedup:   d,
+		windows: make(map[dedupKey]*dedupWindow),
+	}
+}
+
+type dedupKey struct {
+	level   cue.Level
+	message string
+	err     string
+}
+
+type dedupWindow struct {
+	last     *cue.Event
+	repeated int
+	timer    *time.Timer
+}
+
+type dedupCollector struct {
+	Dedup
+
+	mu      sync.Mutex
+	windows map[dedupKey]*dedupWindow
+}
+
+func (d *dedupCollector) String() string {
+	return fmt.Sprintf("Dedup(window=%s, target=%s)", d.Window, d.Target)
+}
+
+func (d *dedupCollector) Collect(event *cue.Event) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := dedupKeyFor(event)
+	window, present := d.windows[key]
+	if present {
+		window.last = event
+		window.repeated++
+		return nil
+	}
+
+	// The window is only opened once the event is delivered.  Otherwise a
+	// retry of a failed event would be counted as a repeat and dropped.
+	err := d.Target.Collect(event)
+	if err != nil {
+		return err
+	}
+	window = &dedupWindow{}
+	window.timer = time.AfterFunc(d.Window, func() {
+		d.expire(key, window)
+	})
+	d.windows[key] = window
+	return nil
+}
+
+func (d *dedupCollector) Close() error {
+	d.mu.Lock()
+	var err error
+	for key, window := range d.windows {
+		window.timer.Stop()
+		delete(d.windows, key)
+		if flushErr := d.flush(window); flushErr != nil && err == nil {
+			err = flushErr
+		}
+	}
+	d.mu.Unlock()
+
+	closer, ok := d.Target.(io.Closer)
+	if !ok {
+		return err
+	}
+	if closeErr := closer.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (d *dedupCollector) expire(key dedupKey, window *dedupWindow) {
+	d.mu.Lock()
+	if d.windows[key] != window {
+		// Already flushed via Close
+		d.mu.Unlock()
+		return
+	}
+	delete(d.windows, key)
+	err := d.flush(window)
+	d.mu.Unlock()
+
+	if err != nil {
+		log.Errorf(err, "Failed to send repeated event summary for %s", d)
+	}
+}
+
+// flush must be called with d.mu held.
+func (d *dedupCollector) flush(window *dedupWindow) error {
+	if window.repeated == 0 {
+		return nil
+	}
+	repeat := window.last.Clone()
+	repeat.Context = repeat.Context.WithValue("repeated", window.repeated)
+	return d.Target.Collect(repeat)
+}
+
+func dedupKeyFor(event *cue.Event) dedupKey {
+	key := dedupKey{level: event.Level, message: event.Message}
+	if event.Error != nil {
+		key.err = event.Error.Error()
+	}
+	return key
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/internal/cuetest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDedupNilCollector(t *testing.T) {
+	c := Dedup{}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when the target is missing, but got %s instead", c)
+	}
+}
+
+// flakyCollector fails the given number of Collect calls before succeeding.
+type flakyCollector struct {
+	mu        sync.Mutex
+	failures  int
+	calls     int
+	collected []*cue.Event
+}
+
+func (f *flakyCollector) Collect(event *cue.Event) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("collection failed")
+	}
+	f.collected = append(f.collected, event)
+	return nil
+}
+
+func (f *flakyCollector) Collected() []*cue.Event {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]*cue.Event(nil), f.collected...)
+}
+
+func TestDedupRetry(t *testing.T) {
+	defer cue.Close(time.Minute)
+	flaky := &flakyCollector{failures: 1}
+	cue.Collect(cue.INFO, Dedup{Target: flaky, Window: time.Hour}.New())
+
+	cue.NewLogger("test").Info("retried event")
+	collected := flaky.Collected()
+	if len(collected) != 1 || collected[0].Message != "retried event" {
+		t.Errorf("Expected the retried event to be delivered once, but saw %d delivered events in %d calls", len(collected), flaky.calls)
+	}
+}
+
+func TestDedup(t *testing.T) {
+	capture := cuetest.NewCapturingCollector()
+	c := Dedup{Target: capture, Window: time.Hour}.New()
+
+	for i := 0; i < 5; i++ {
+		c.Collect(cuetest.ErrorEvent)
+	}
+	c.Collect(cuetest.DebugEvent)
+
+	differentError := cuetest.ErrorEvent.Clone()
+	differentError.Error = errors.New("different error")
+	c.Collect(differentError)
+
+	captured := capture.Captured()
+	if len(captured) != 3 {
+		t.Fatalf("Expected 3 distinct events to be collected but saw %d instead", len(captured))
+	}
+	if captured[0] != cuetest.ErrorEvent || captured[1] != cuetest.DebugEvent || captured[2] != differentError {
+		t.Errorf("Expected the first occurrence of each distinct event to be passed through, but saw %v instead", captured)
+	}
+
+	cuetest.CloseCollector(c)
+	captured = capture.Captured()
+	if len(captured) != 4 {
+		t.Fatalf("Expected a single repeated event to be sent on close, but saw %d events instead", len(captured))
+	}
+	repeat := captured[3]
+	if repeat.Level != cue.ERROR || repeat.Message != cuetest.ErrorEvent.Message {
+		t.Errorf("Expected the repeated event to match the original, but saw %s %q instead", repeat.Level, repeat.Message)
+	}
+	if repeat.Context.Fields()["repeated"] != 4 {
+		t.Errorf("Expected the repeated event context to include repeated=4, but saw %v instead", repeat.Context.Fields())
+	}
+}
+
+func TestDedupWindow(t *testing.T) {
+	capture := cuetest.NewCapturingCollector()
+	c := Dedup{Target: capture, Window: 10 * time.Millisecond}.New()
+	defer cuetest.CloseCollector(c)
+
+	c.Collect(cuetest.ErrorEvent)
+	c.Collect(cuetest.ErrorEvent)
+	c.Collect(cuetest.ErrorEvent)
+	capture.WaitCaptured(2, time.Second)
+
+	captured := capture.Captured()
+	if len(captured) != 2 {
+		t.Fatalf("Expected a repeated event to be sent when the window closed, but saw %d events instead", len(captured))
+	}
+	if captured[1].Context.Fields()["repeated"] != 2 {
+		t.Errorf("Expected the repeated event context to include repeated=2, but saw %v instead", captured[1].Context.Fields())
+	}
+
+	// A new window opens for subsequent events
+	c.Collect(cuetest.ErrorEvent)
+	if len(capture.Captured()) != 3 {
+		t.Errorf("Expected the first event of a new window to be passed through, but saw %d events instead", len(capture.Captured()))
+	}
+}
+
+func TestDedupNoRepeats(t *testing.T) {
+	capture := cuetest.NewCapturingCollector()
+	c := Dedup{Target: capture, Window: time.Millisecond}.New()
+
+	c.Collect(cuetest.ErrorEvent)
+	time.Sleep(20 * time.Millisecond)
+	cuetest.CloseCollector(c)
+
+	if len(capture.Captured()) != 1 {
+		t.Errorf("Expected windows without repeats to close silently, but saw %d events instead", len(capture.Captured()))
+	}
+}
+
+func TestDedupString(t *testing.T) {
+	c := Dedup{Target: cuetest.NewCapturingCollector()}.New()
+
+	// Ensure nothing panics
+	_ = fmt.Sprint(c)
+}
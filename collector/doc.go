@@ -23,8 +23,12 @@ Package collector implements event collection.
 
 Implementations
 
-This package provides event collection to file, syslog, web servers, and
-network sockets.
+This package provides event collection to plain and rotating files, syslog,
+arbitrary io.Writer instances, web servers, network sockets, Elasticsearch,
+Graylog, in-process channels, and in-memory ring buffers.  The Prometheus
+collector counts events by level and context name.  It's only built with the
+"prometheus" build tag, so the Prometheus client library isn't a dependency
+otherwise.
 
 Nil Instances
 
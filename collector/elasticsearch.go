@@ -0,0 +1,221 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package collector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/format"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Elasticsearch represents configuration for Collector instances that index
+// events in Elasticsearch via the _bulk API.  Events are buffered and
+// submitted as newline-delimited JSON once BatchSize events are pending or
+// FlushInterval has elapsed, whichever comes first.  Each event is rendered
+// via format.JSONEvent.  Pending events are flushed on Close.
+//
+// Index is used literally.  To write events to date-based indices, set
+// IndexDateLayout to a Go time layout, such as "2006.01.02".  The event's UTC
+// time, formatted via IndexDateLayout, is then appended to Index.  For
+// example, an Index of "logs-" and an IndexDateLayout of "2006.01.02" write
+// events to indices such as "logs-2016.03.14".
+//
+// If Elasticsearch responds with a 4XX status code other than 429 (Too Many
+// Requests), the batch is dropped and an error is returned, since
+// resubmitting it won't succeed.  For other failures, including 429
+// responses, the batch is retained and resubmitted with the next flush.  If the
+// retained batch grows to twice BatchSize, it's dropped as well.  Failures for
+// individual documents within an otherwise successful bulk request aren't
+// detected.
+type Elasticsearch struct {
+	// Required
+	URL   string // Base URL, e.g. "http://localhost:9200"
+	Index string // Index name or prefix, e.g. "logs" or "logs-"
+
+	// Optional
+	IndexDateLayout string        // Time layout appended to Index, e.g. "2006.01.02".  Default: none
+	BatchSize       int           // Default: 100
+	FlushInterval   time.Duration // Default: 5 seconds
+	Client          *http.Client  // Default: a client shared by HTTP collectors
+	Timeout         time.Duration // Default: 30 seconds.  See HTTP for details.
+	MaxRetryAfter   time.Duration // Default: 30 seconds.  See HTTP for details.
+}
+
+// New returns a new collector based on the Elasticsearch configuration.
+func (e Elasticsearch) New() cue.Collector {
+	if e.URL == "" {
+		log.Warn("Elasticsearch.New called to created a collector, but URL param is empty.  Returning nil collector.")
+		return nil
+	}
+	if e.Index == "" {
+		log.Warn("Elasticsearch.New called to created a collector, but Index param is empty.  Returning nil collector.")
+		return nil
+	}
+	if e.BatchSize <= 0 {
+		e.BatchSize = 100
+	}
+	if e.FlushInterval <= 0 {
+		e.FlushInterval = 5 * time.Second
+	}
+	if e.Client == nil {
+		e.Client = clientWithTimeout(e.Timeout)
+	}
+	if e.MaxRetryAfter == 0 {
+		e.MaxRetryAfter = 30 * time.Second
+	}
+
+	c := &elasticsearchCollector{
+		Elasticsearch: e,
+		http:          &httpCollector{HTTP: HTTP{Client: e.Client, MaxRetryAfter: e.MaxRetryAfter}},
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	go c.flushPeriodically()
+	return c
+}
+
+type elasticsearchCollector struct {
+	Elasticsearch
+	http *httpCollector
+
+	mu     sync.Mutex
+	batch  []*cue.Event
+	closed bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func (e *elasticsearchCollector) String() string {
+	return fmt.Sprintf("Elasticsearch(url=%s, index=%s)", e.URL, e.Index)
+}
+
+func (e *elasticsearchCollector) Collect(event *cue.Event) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// Workers retry failed Collect calls with the same event, in which case
+	// it's already pending.
+	if len(e.batch) == 0 || e.batch[len(e.batch)-1] != event {
+		e.batch = append(e.batch, event)
+	}
+	if len(e.batch) < e.BatchSize {
+		return nil
+	}
+	return e.flush()
+}
+
+func (e *elasticsearchCollector) Close() error {
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return nil
+	}
+	e.closed = true
+	e.mu.Unlock()
+
+	close(e.stop)
+	<-e.done
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.flush()
+}
+
+func (e *elasticsearchCollector) flushPeriodically() {
+	defer close(e.done)
+
+	ticker := time.NewTicker(e.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.stop:
+			return
+		case <-ticker.C:
+			e.mu.Lock()
+			err := e.flush()
+			e.mu.Unlock()
+			if err != nil {
+				log.Errorf(err, "Failed to flush pending events for %s", e)
+			}
+		}
+	}
+}
+
+// flush must be called with e.mu held.
+func (e *elasticsearchCollector) flush() error {
+	if len(e.batch) == 0 {
+		return nil
+	}
+
+	request, err := http.NewRequest("POST", strings.TrimRight(e.URL, "/")+"/_bulk", bytes.NewReader(e.bulkBody()))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/x-ndjson")
+
+	err = e.http.send(request)
+	if err == nil {
+		e.batch = nil
+		return nil
+	}
+
+	count := len(e.batch)
+	if status, ok := err.(*httpStatusError); ok && status.code < 500 && status.code != http.StatusTooManyRequests {
+		e.batch = nil
+		return fmt.Errorf("cue/collector: elasticsearch rejected batch, %d events dropped: %s", count, err)
+	}
+	if count >= 2*e.BatchSize {
+		e.batch = nil
+		return fmt.Errorf("cue/collector: elasticsearch batch limit exceeded, %d events dropped: %s", count, err)
+	}
+	return err
+}
+
+func (e *elasticsearchCollector) bulkBody() []byte {
+	buf := format.GetBuffer()
+	defer format.ReleaseBuffer(buf)
+
+	for _, event := range e.batch {
+		buf.AppendString(`{"index":{"_index":`)
+		buf.Append(e.indexFor(event))
+		buf.AppendString("}}\n")
+		format.JSONEvent(buf, event)
+		buf.AppendRune('\n')
+	}
+	return append([]byte(nil), buf.Bytes()...)
+}
+
+// indexFor returns the JSON-encoded index name for event.
+func (e *elasticsearchCollector) indexFor(event *cue.Event) []byte {
+	index := e.Index
+	if e.IndexDateLayout != "" {
+		index += event.Time.UTC().Format(e.IndexDateLayout)
+	}
+	encoded, _ := json.Marshal(index)
+	return encoded
+}
@@ -0,0 +1,313 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package collector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/bobziuchkovski/cue/internal/cuetest"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// statusTransport records request bodies and responds with a fixed status.
+type statusTransport struct {
+	mu     sync.Mutex
+	code   int
+	bodies []string
+}
+
+func (st *statusTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.bodies = append(st.bodies, string(body))
+	w := httptest.NewRecorder()
+	w.WriteHeader(st.code)
+	return w.Result(), nil
+}
+
+func (st *statusTransport) setCode(code int) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.code = code
+}
+
+func (st *statusTransport) Bodies() []string {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return append([]string(nil), st.bodies...)
+}
+
+func TestElasticsearchNilCollector(t *testing.T) {
+	c := Elasticsearch{Index: "logs"}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when the URL is missing, but got %s instead", c)
+	}
+	c = Elasticsearch{URL: "http://localhost:9200"}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when the index is missing, but got %s instead", c)
+	}
+}
+
+func TestElasticsearch(t *testing.T) {
+	recorder := cuetest.NewHTTPRequestRecorder()
+	c := Elasticsearch{
+		URL:             "http://localhost:9200/",
+		Index:           "logs-",
+		IndexDateLayout: "2006.01.02",
+		BatchSize:       2,
+		Client:          &http.Client{Transport: recorder},
+	}.New()
+	defer cuetest.CloseCollector(c)
+
+	c.Collect(cuetest.DebugEvent)
+	if len(recorder.Requests()) != 0 {
+		t.Fatalf("Expected no requests prior to filling the batch but saw %d instead", len(recorder.Requests()))
+	}
+	err := c.Collect(cuetest.ErrorEvent)
+	if err != nil {
+		t.Errorf("Encountered unexpected error: %s", err)
+	}
+	if len(recorder.Requests()) != 1 {
+		t.Fatalf("Expected exactly 1 request to be sent but saw %d instead", len(recorder.Requests()))
+	}
+
+	req := recorder.Requests()[0]
+	if req.Method != "POST" {
+		t.Errorf("Expected a POST request, but saw %s instead", req.Method)
+	}
+	if req.URL.Path != "/_bulk" {
+		t.Errorf("Expected a request to /_bulk, but saw %s instead", req.URL.Path)
+	}
+	if req.Header.Get("Content-Type") != "application/x-ndjson" {
+		t.Errorf("Expected a ndjson content type, but saw %q instead", req.Header.Get("Content-Type"))
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		t.Fatalf("Encountered unexpected error reading request body: %s", err)
+	}
+	lines := strings.Split(string(body), "\n")
+	if len(lines) != 5 || lines[4] != "" {
+		t.Fatalf("Expected 4 newline-terminated lines in the bulk request body, but got %q instead", body)
+	}
+	for i, message := range []string{"debug event", "error event"} {
+		action := `{"index":{"_index":"logs-2006.01.02"}}`
+		if lines[2*i] != action {
+			t.Errorf("Expected bulk action %q but got %q instead", action, lines[2*i])
+		}
+		var doc map[string]interface{}
+		err = json.Unmarshal([]byte(lines[2*i+1]), &doc)
+		if err != nil {
+			t.Fatalf("Expected bulk document to be valid JSON, but received error: %s", err)
+		}
+		if doc["message"] != message {
+			t.Errorf("Expected document message %q but got %q instead", message, doc["message"])
+		}
+	}
+}
+
+func TestElasticsearchLiteralIndex(t *testing.T) {
+	// Index names aren't treated as time layouts, so layout tokens such as
+	// "1", "2", "Mon", and "pm" are left intact.  Names are JSON-encoded.
+	for _, index := range []string{"logs-v2", "npm-logs", "app-1", "Mon\x7f\"quoted\""} {
+		transport := &statusTransport{code: http.StatusOK}
+		c := Elasticsearch{
+			URL:       "http://localhost:9200",
+			Index:     index,
+			BatchSize: 1,
+			Client:    &http.Client{Transport: transport},
+		}.New()
+		c.Collect(eventAt(time.Date(2016, 3, 14, 21, 26, 53, 0, time.UTC)))
+		cuetest.CloseCollector(c)
+
+		bodies := transport.Bodies()
+		if len(bodies) != 1 {
+			t.Fatalf("Expected exactly 1 request to be sent but saw %d instead", len(bodies))
+		}
+		var action struct {
+			Index struct {
+				Name string `json:"_index"`
+			} `json:"index"`
+		}
+		err := json.Unmarshal([]byte(strings.SplitN(bodies[0], "\n", 2)[0]), &action)
+		if err != nil {
+			t.Fatalf("Expected the bulk action to be valid JSON, but received error: %s", err)
+		}
+		if action.Index.Name != index {
+			t.Errorf("Expected index %q, but saw %q instead", index, action.Index.Name)
+		}
+	}
+}
+
+func TestElasticsearchFlushOnClose(t *testing.T) {
+	recorder := cuetest.NewHTTPRequestRecorder()
+	c := Elasticsearch{
+		URL:    "http://localhost:9200",
+		Index:  "logs",
+		Client: &http.Client{Transport: recorder},
+	}.New()
+
+	c.Collect(cuetest.DebugEvent)
+	c.Collect(cuetest.InfoEvent)
+	cuetest.CloseCollector(c)
+
+	if len(recorder.Requests()) != 1 {
+		t.Fatalf("Expected exactly 1 request to be sent on close but saw %d instead", len(recorder.Requests()))
+	}
+	body, _ := ioutil.ReadAll(recorder.Requests()[0].Body)
+	if bytes.Count(body, []byte("\n")) != 4 {
+		t.Errorf("Expected the request body to contain 2 events, but got %q instead", body)
+	}
+}
+
+func TestElasticsearchFlushInterval(t *testing.T) {
+	recorder := cuetest.NewHTTPRequestRecorder()
+	c := Elasticsearch{
+		URL:           "http://localhost:9200",
+		Index:         "logs",
+		FlushInterval: 10 * time.Millisecond,
+		Client:        &http.Client{Transport: recorder},
+	}.New()
+	defer cuetest.CloseCollector(c)
+
+	c.Collect(cuetest.DebugEvent)
+	for i := 0; i < 100 && len(recorder.Requests()) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(recorder.Requests()) != 1 {
+		t.Errorf("Expected exactly 1 request to be sent after the flush interval but saw %d instead", len(recorder.Requests()))
+	}
+}
+
+func TestElasticsearchClientError(t *testing.T) {
+	transport := &statusTransport{code: http.StatusBadRequest}
+	c := Elasticsearch{
+		URL:       "http://localhost:9200",
+		Index:     "logs",
+		BatchSize: 1,
+		Client:    &http.Client{Transport: transport},
+	}.New()
+	defer cuetest.CloseCollector(c)
+
+	err := c.Collect(cuetest.DebugEvent)
+	if err == nil {
+		t.Error("Expected a 4XX response to return an error but it didn't")
+	}
+
+	transport.setCode(http.StatusOK)
+	c.Collect(cuetest.InfoEvent)
+	bodies := transport.Bodies()
+	if len(bodies) != 2 {
+		t.Fatalf("Expected exactly 2 requests to be sent but saw %d instead", len(bodies))
+	}
+	if strings.Contains(bodies[1], "debug event") || !strings.Contains(bodies[1], "info event") {
+		t.Errorf("Expected the rejected batch to be dropped, but saw %q instead", bodies[1])
+	}
+}
+
+func TestElasticsearchTooManyRequests(t *testing.T) {
+	transport := &statusTransport{code: http.StatusTooManyRequests}
+	c := Elasticsearch{
+		URL:       "http://localhost:9200",
+		Index:     "logs",
+		BatchSize: 1,
+		Client:    &http.Client{Transport: transport},
+	}.New()
+	defer cuetest.CloseCollector(c)
+
+	err := c.Collect(cuetest.DebugEvent)
+	if err == nil {
+		t.Error("Expected a 429 response to return an error but it didn't")
+	}
+
+	transport.setCode(http.StatusOK)
+	c.Collect(cuetest.InfoEvent)
+	bodies := transport.Bodies()
+	if len(bodies) != 2 {
+		t.Fatalf("Expected exactly 2 requests to be sent but saw %d instead", len(bodies))
+	}
+	if !strings.Contains(bodies[1], "debug event") || !strings.Contains(bodies[1], "info event") {
+		t.Errorf("Expected the throttled batch to be retained and resubmitted, but saw %q instead", bodies[1])
+	}
+}
+
+func TestElasticsearchServerError(t *testing.T) {
+	transport := &statusTransport{code: http.StatusServiceUnavailable}
+	c := Elasticsearch{
+		URL:       "http://localhost:9200",
+		Index:     "logs",
+		BatchSize: 2,
+		Client:    &http.Client{Transport: transport},
+	}.New()
+	defer cuetest.CloseCollector(c)
+
+	c.Collect(cuetest.DebugEvent)
+	err := c.Collect(cuetest.InfoEvent)
+	if err == nil {
+		t.Error("Expected a 5XX response to return an error but it didn't")
+	}
+
+	// Retries of the same event mustn't duplicate it within the batch.
+	transport.setCode(http.StatusOK)
+	err = c.Collect(cuetest.InfoEvent)
+	if err != nil {
+		t.Errorf("Encountered unexpected error: %s", err)
+	}
+	bodies := transport.Bodies()
+	if len(bodies) != 2 {
+		t.Fatalf("Expected exactly 2 requests to be sent but saw %d instead", len(bodies))
+	}
+	if bodies[0] != bodies[1] {
+		t.Errorf("Expected the failed batch to be resubmitted unaltered, but saw %q instead of %q", bodies[1], bodies[0])
+	}
+}
+
+func TestElasticsearchHTTPDefaults(t *testing.T) {
+	c := Elasticsearch{URL: "http://localhost:9200", Index: "logs", Timeout: time.Second}.New().(*elasticsearchCollector)
+	defer cuetest.CloseCollector(c)
+
+	if c.Client.Timeout != time.Second {
+		t.Errorf("Expected a client timeout of 1s, but saw %s instead", c.Client.Timeout)
+	}
+	if c.http.MaxRetryAfter != 30*time.Second {
+		t.Errorf("Expected a default MaxRetryAfter of 30s, but saw %s instead", c.http.MaxRetryAfter)
+	}
+}
+
+func TestElasticsearchString(t *testing.T) {
+	c := Elasticsearch{URL: "http://localhost:9200", Index: "logs"}.New()
+	defer cuetest.CloseCollector(c)
+
+	// Ensure nothing panics
+	_ = fmt.Sprint(c)
+}
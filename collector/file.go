@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"github.com/bobziuchkovski/cue"
 	"github.com/bobziuchkovski/cue/format"
+	"io"
 	"os"
 	"os/signal"
 	"sync"
@@ -50,6 +51,14 @@ type File struct {
 	// If set, reopen the file if it's missing.  The file path will be checked
 	// at the time interval specified.
 	ReopenMissing time.Duration
+
+	// If set, check whether the file has been truncated at the time interval
+	// specified.  If the file is smaller than the position of our last write,
+	// subsequent writes are moved to the new end of the file.  This is needed
+	// to coordinate with the "copytruncate" option of logrotate when the
+	// O_APPEND flag isn't used.  Otherwise, writes continue at the old offset,
+	// leaving a large sparse gap at the start of the file.
+	CheckTruncated time.Duration
 }
 
 // New returns a new collector based on the File configuration.
@@ -71,15 +80,17 @@ func (f File) New() cue.Collector {
 	fc := &fileCollector{File: f}
 	fc.watchSignal()
 	fc.watchRemoval()
+	fc.watchTruncation()
 	return fc
 }
 
 type fileCollector struct {
 	File
 
-	mu     sync.Mutex
-	file   *os.File
-	opened bool
+	mu       sync.Mutex
+	file     *os.File
+	opened   bool
+	position int64 // Offset following our last write.  Only tracked if CheckTruncated is set.
 }
 
 func (f *fileCollector) String() string {
@@ -90,23 +101,36 @@ func (f *fileCollector) Collect(event *cue.Event) error {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 
-	err := f.ensureOpen()
-	if err != nil {
-		f.ensureClosed()
-		return err
-	}
-
 	buf := format.GetBuffer()
 	defer format.ReleaseBuffer(buf)
-	f.Formatter(buf, event)
+	f.render(buf, event)
+	return f.write(buf.Bytes())
+}
 
+// render formats the event to buf, ensuring the result is newline-terminated.
+func (f *fileCollector) render(buf format.Buffer, event *cue.Event) {
+	f.Formatter(buf, event)
 	bytes := buf.Bytes()
-	if bytes[len(bytes)-1] != byte('\n') {
-		bytes = append(bytes, byte('\n'))
+	if len(bytes) == 0 || bytes[len(bytes)-1] != byte('\n') {
+		buf.AppendByte('\n')
+	}
+}
+
+// write writes bytes to the file, opening it if needed.  The caller must hold
+// f.mu.
+func (f *fileCollector) write(bytes []byte) error {
+	err := f.ensureOpen()
+	if err != nil {
+		f.ensureClosed()
+		return err
 	}
 	_, err = f.file.Write(bytes)
 	if err != nil {
 		f.ensureClosed()
+		return err
+	}
+	if f.CheckTruncated != 0 {
+		f.position, err = f.file.Seek(0, io.SeekCurrent)
 	}
 	return err
 }
@@ -128,6 +152,24 @@ func (f *fileCollector) reopen() error {
 	return f.ensureOpen()
 }
 
+func (f *fileCollector) checkTruncated() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	stat, err := f.file.Stat()
+	if err != nil {
+		return err
+	}
+	if stat.Size() >= f.position {
+		return nil
+	}
+	f.position, err = f.file.Seek(0, io.SeekEnd)
+	return err
+}
+
 func (f *fileCollector) ensureOpen() error {
 	if f.file != nil {
 		return nil
@@ -137,6 +179,7 @@ func (f *fileCollector) ensureOpen() error {
 	f.file, err = os.OpenFile(f.Path, f.Flags, f.Perms)
 	if err == nil {
 		f.opened = true
+		f.position = 0
 	}
 	return err
 }
@@ -178,3 +221,15 @@ func (f *fileCollector) watchRemoval() {
 		}
 	}()
 }
+
+func (f *fileCollector) watchTruncation() {
+	if f.CheckTruncated == 0 {
+		return
+	}
+	go func() {
+		for {
+			time.Sleep(f.CheckTruncated)
+			f.checkTruncated()
+		}
+	}()
+}
@@ -196,6 +196,31 @@ func TestFileReopenMissing(t *testing.T) {
 	checkFileContents(t, file, fileEventStr)
 }
 
+func TestFileCheckTruncated(t *testing.T) {
+	tmp := tmpDir()
+	defer os.RemoveAll(tmp)
+
+	file := path.Join(tmp, "file")
+	c := File{
+		Path:           file,
+		Flags:          os.O_CREATE | os.O_WRONLY,
+		CheckTruncated: time.Millisecond,
+	}.New()
+	c.Collect(cuetest.DebugEvent)
+	c.Collect(cuetest.DebugEvent)
+
+	// Simulate logrotate's copytruncate
+	err := os.Truncate(file, 0)
+	if err != nil {
+		t.Errorf("Encountered unexpected error truncating file: %s", err)
+	}
+	waitTruncationHandled(c.(*fileCollector), 5*time.Second)
+
+	c.Collect(cuetest.DebugEvent)
+	cuetest.CloseCollector(c)
+	checkFileContents(t, file, fileEventStr)
+}
+
 func TestFileString(t *testing.T) {
 	tmp := tmpDir()
 	defer os.RemoveAll(tmp)
@@ -228,6 +253,22 @@ func waitExists(path string, timeout time.Duration) {
 	}
 }
 
+func waitTruncationHandled(f *fileCollector, timeout time.Duration) {
+	timer := time.AfterFunc(timeout, func() {
+		panic("timeout waiting for file truncation to be handled")
+	})
+	for {
+		f.mu.Lock()
+		position := f.position
+		f.mu.Unlock()
+		if position == 0 {
+			timer.Stop()
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
 func checkFileContents(t *testing.T, path string, expected string) {
 	bytes, err := ioutil.ReadFile(path)
 	if err != nil {
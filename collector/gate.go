@@ -0,0 +1,94 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package collector
+
+import (
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"io"
+	"runtime"
+	"sync/atomic"
+)
+
+// LoadGated represents configuration for load-gated Collector instances.
+// Events are passed to Target only while the gating predicate allows it.
+// Otherwise, events are dropped and a drop counter is incremented.  This is
+// useful for suppressing verbose collectors while the system is saturated.
+//
+// If ShouldCollect is set, it's used as the gating predicate.  Otherwise,
+// events are collected while the number of running goroutines is less than or
+// equal to MaxGoroutines.  The predicate is evaluated for every event, so it
+// should be cheap to call.
+type LoadGated struct {
+	// Required
+	Target cue.Collector
+
+	// Required: one of the following
+	ShouldCollect func() bool // Collect events while ShouldCollect returns true
+	MaxGoroutines int         // Collect events while runtime.NumGoroutine() <= MaxGoroutines
+}
+
+// New returns a new collector based on the LoadGated configuration.
+func (l LoadGated) New() cue.Collector {
+	if l.Target == nil {
+		log.Warn("LoadGated.New called to created a collector, but Target param is empty.  Returning nil collector.")
+		return nil
+	}
+	if l.ShouldCollect == nil && l.MaxGoroutines <= 0 {
+		log.Warn("LoadGated.New called to created a collector, but ShouldCollect and MaxGoroutines params are empty.  Returning nil collector.")
+		return nil
+	}
+	if l.ShouldCollect == nil {
+		max := l.MaxGoroutines
+		l.ShouldCollect = func() bool {
+			return runtime.NumGoroutine() <= max
+		}
+	}
+	return &loadGatedCollector{LoadGated: l}
+}
+
+type loadGatedCollector struct {
+	// Drops is accessed via atomic operations.  It's the first field to ensure
+	// 64-bit alignment.  See the sync/atomic docs for details.
+	drops uint64
+
+	LoadGated
+}
+
+func (l *loadGatedCollector) String() string {
+	return fmt.Sprintf("LoadGated(drops=%d, target=%s)", atomic.LoadUint64(&l.drops), l.Target)
+}
+
+func (l *loadGatedCollector) Collect(event *cue.Event) error {
+	if !l.ShouldCollect() {
+		atomic.AddUint64(&l.drops, 1)
+		return nil
+	}
+	return l.Target.Collect(event)
+}
+
+func (l *loadGatedCollector) Close() error {
+	closer, ok := l.Target.(io.Closer)
+	if !ok {
+		return nil
+	}
+	return closer.Close()
+}
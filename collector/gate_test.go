@@ -0,0 +1,89 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package collector
+
+import (
+	"fmt"
+	"github.com/bobziuchkovski/cue/internal/cuetest"
+	"strings"
+	"testing"
+)
+
+func TestLoadGatedNilCollector(t *testing.T) {
+	c := LoadGated{MaxGoroutines: 1}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when the target is missing, but got %s instead", c)
+	}
+
+	c = LoadGated{Target: cuetest.NewCapturingCollector()}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when the gating params are missing, but got %s instead", c)
+	}
+}
+
+func TestLoadGated(t *testing.T) {
+	allowed := true
+	capture := cuetest.NewCapturingCollector()
+	c := LoadGated{
+		Target:        capture,
+		ShouldCollect: func() bool { return allowed },
+	}.New()
+
+	c.Collect(cuetest.DebugEvent)
+	allowed = false
+	c.Collect(cuetest.DebugEvent)
+	c.Collect(cuetest.DebugEvent)
+	allowed = true
+	c.Collect(cuetest.DebugEvent)
+
+	if len(capture.Captured()) != 2 {
+		t.Errorf("Expected 2 events to be collected but saw %d instead", len(capture.Captured()))
+	}
+	if !strings.Contains(fmt.Sprint(c), "drops=2") {
+		t.Errorf("Expected the collector to report 2 drops, but saw %s instead", c)
+	}
+}
+
+func TestLoadGatedMaxGoroutines(t *testing.T) {
+	capture := cuetest.NewCapturingCollector()
+	c := LoadGated{Target: capture, MaxGoroutines: 1000000}.New()
+	c.Collect(cuetest.DebugEvent)
+	if len(capture.Captured()) != 1 {
+		t.Errorf("Expected 1 event to be collected but saw %d instead", len(capture.Captured()))
+	}
+
+	capture = cuetest.NewCapturingCollector()
+	c = LoadGated{Target: capture, MaxGoroutines: 1}.New()
+	done := make(chan struct{})
+	defer close(done)
+	go func() { <-done }()
+	c.Collect(cuetest.DebugEvent)
+	if len(capture.Captured()) != 0 {
+		t.Errorf("Expected 0 events to be collected but saw %d instead", len(capture.Captured()))
+	}
+}
+
+func TestLoadGatedString(t *testing.T) {
+	c := LoadGated{Target: cuetest.NewCapturingCollector(), MaxGoroutines: 1}.New()
+
+	// Ensure nothing panics
+	_ = fmt.Sprint(c)
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package collector
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/format"
+	"net/http"
+)
+
+// GELFHTTP represents configuration for Collector instances that submit
+// events to a Graylog GELF HTTP input.  Each event is rendered via
+// format.GELFHost and POSTed to URL as JSON.
+type GELFHTTP struct {
+	// Required
+	URL string // GELF HTTP input URL, e.g. "http://graylog.example.com:12201/gelf"
+
+	// Optional
+	Host   string       // Default: the local hostname (see format.Hostname)
+	Client *http.Client // Default: a client shared by HTTP collectors
+}
+
+// New returns a new collector based on the GELFHTTP configuration.
+func (g GELFHTTP) New() cue.Collector {
+	if g.URL == "" {
+		log.Warn("GELFHTTP.New called to created a collector, but URL param is empty.  Returning nil collector.")
+		return nil
+	}
+	return &gelfHTTPCollector{
+		GELFHTTP: g,
+		http:     HTTP{RequestFormatter: g.formatRequest, Client: g.Client}.New(),
+	}
+}
+
+func (g GELFHTTP) formatRequest(event *cue.Event) (request *http.Request, err error) {
+	body := format.RenderBytes(format.GELFHost(g.Host), event)
+	request, err = http.NewRequest("POST", g.URL, bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	request.Header.Set("Content-Type", "application/json")
+	return
+}
+
+type gelfHTTPCollector struct {
+	GELFHTTP
+	http cue.Collector
+}
+
+func (g *gelfHTTPCollector) String() string {
+	return fmt.Sprintf("GELFHTTP(url=%s)", g.URL)
+}
+
+func (g *gelfHTTPCollector) Collect(event *cue.Event) error {
+	return g.http.Collect(event)
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/bobziuchkovski/cue/internal/cuetest"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestGELFHTTPNilCollector(t *testing.T) {
+	c := GELFHTTP{}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when the URL is missing, but got %s instead", c)
+	}
+}
+
+func TestGELFHTTP(t *testing.T) {
+	recorder := cuetest.NewHTTPRequestRecorder()
+	c := GELFHTTP{
+		URL:    "http://graylog.example.com:12201/gelf",
+		Host:   "example.com",
+		Client: &http.Client{Transport: recorder},
+	}.New()
+
+	err := c.Collect(cuetest.ErrorEvent)
+	if err != nil {
+		t.Errorf("Encountered unexpected error: %s", err)
+	}
+	if len(recorder.Requests()) != 1 {
+		t.Fatalf("Expected exactly 1 request to be sent but saw %d instead", len(recorder.Requests()))
+	}
+
+	req := recorder.Requests()[0]
+	if req.Method != "POST" {
+		t.Errorf("Expected a POST request, but saw %s instead", req.Method)
+	}
+	if req.URL.Path != "/gelf" {
+		t.Errorf("Expected a request to /gelf, but saw %s instead", req.URL.Path)
+	}
+	if req.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Expected a JSON content type, but saw %q instead", req.Header.Get("Content-Type"))
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		t.Fatalf("Encountered unexpected error reading request body: %s", err)
+	}
+	var gelf map[string]interface{}
+	err = json.Unmarshal(body, &gelf)
+	if err != nil {
+		t.Fatalf("Expected request body to be valid JSON, but received error: %s", err)
+	}
+	expectations := map[string]interface{}{
+		"version":       "1.1",
+		"host":          "example.com",
+		"short_message": "error event",
+		"level":         3.0,
+		"timestamp":     1136214240.0,
+		"_k1":           "some value",
+		"_k2":           2.0,
+	}
+	for k, v := range expectations {
+		if gelf[k] != v {
+			t.Errorf("GELF field %s is incorrect.  Expected: %v, Received: %v", k, v, gelf[k])
+		}
+	}
+}
+
+func TestGELFHTTPString(t *testing.T) {
+	c := GELFHTTP{URL: "http://graylog.example.com:12201/gelf"}.New()
+
+	// Ensure nothing panics
+	_ = fmt.Sprint(c)
+}
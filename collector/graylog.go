@@ -0,0 +1,150 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package collector
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/format"
+	"math/rand"
+	"net"
+	"time"
+)
+
+const (
+	gelfChunkHeaderSize = 12
+	gelfMaxChunks       = 128
+)
+
+var gelfChunkMagic = []byte{0x1e, 0x0f}
+
+// Graylog represents configuration for Collector instances that send events
+// to a Graylog GELF UDP input.  Each event is rendered via format.GELFHost and
+// sent as a single datagram.  Events that render larger than ChunkSize are
+// split into GELF chunks, each of which is sent as a separate datagram of at
+// most ChunkSize bytes.  GELF permits at most 128 chunks per message; larger
+// events are dropped and an error is returned.  Messages are sent
+// uncompressed.
+//
+// UDP delivery is unacknowledged, so events may be lost without the collector
+// returning an error.  Use GELFHTTP if reliable delivery is required.
+type Graylog struct {
+	// Required
+	Address string // GELF UDP input address, e.g. "graylog.example.com:12201"
+
+	// Optional
+	Host      string // Default: the local hostname (see format.Hostname)
+	ChunkSize int    // Maximum datagram size.  Default: 1420, suitable for WAN links
+}
+
+// New returns a new collector based on the Graylog configuration.
+func (g Graylog) New() cue.Collector {
+	if g.Address == "" {
+		log.Warn("Graylog.New called to created a collector, but Address param is empty.  Returning nil collector.")
+		return nil
+	}
+	if g.ChunkSize <= gelfChunkHeaderSize {
+		g.ChunkSize = 1420
+	}
+	return &graylogCollector{
+		Graylog:   g,
+		formatter: format.GELFHost(g.Host),
+		rand:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+type graylogCollector struct {
+	Graylog
+	formatter format.Formatter
+	rand      *rand.Rand
+	conn      net.Conn
+}
+
+func (g *graylogCollector) String() string {
+	return fmt.Sprintf("Graylog(address=%s, chunk_size=%d)", g.Address, g.ChunkSize)
+}
+
+func (g *graylogCollector) Collect(event *cue.Event) error {
+	if g.conn == nil {
+		conn, err := net.Dial("udp", g.Address)
+		if err != nil {
+			return err
+		}
+		g.conn = conn
+	}
+
+	message := format.RenderBytes(g.formatter, event)
+	datagrams, err := g.chunk(message)
+	if err != nil {
+		return err
+	}
+	for _, datagram := range datagrams {
+		_, err = g.conn.Write(datagram)
+		if err != nil {
+			g.conn.Close()
+			g.conn = nil
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *graylogCollector) Close() error {
+	if g.conn == nil {
+		return nil
+	}
+	err := g.conn.Close()
+	g.conn = nil
+	return err
+}
+
+// chunk splits message into GELF chunks if it exceeds the chunk size.  Each
+// chunk is prefixed with the chunk magic bytes, an 8-byte message ID shared by
+// all chunks of the message, and the chunk's sequence number and count.
+func (g *graylogCollector) chunk(message []byte) ([][]byte, error) {
+	if len(message) <= g.ChunkSize {
+		return [][]byte{message}, nil
+	}
+
+	payloadSize := g.ChunkSize - gelfChunkHeaderSize
+	count := (len(message) + payloadSize - 1) / payloadSize
+	if count > gelfMaxChunks {
+		return nil, fmt.Errorf("cue/collector: GELF message of %d bytes requires %d chunks, exceeding the maximum of %d", len(message), count, gelfMaxChunks)
+	}
+
+	id := make([]byte, 8)
+	binary.BigEndian.PutUint64(id, uint64(g.rand.Int63()))
+
+	chunks := make([][]byte, 0, count)
+	for seq := 0; seq < count; seq++ {
+		end := (seq + 1) * payloadSize
+		if end > len(message) {
+			end = len(message)
+		}
+		chunk := make([]byte, 0, gelfChunkHeaderSize+end-seq*payloadSize)
+		chunk = append(chunk, gelfChunkMagic...)
+		chunk = append(chunk, id...)
+		chunk = append(chunk, byte(seq), byte(count))
+		chunk = append(chunk, message[seq*payloadSize:end]...)
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
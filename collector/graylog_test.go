@@ -0,0 +1,150 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package collector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/format"
+	"github.com/bobziuchkovski/cue/internal/cuetest"
+	"strings"
+	"testing"
+)
+
+func TestGraylogNilCollector(t *testing.T) {
+	c := Graylog{}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when the address is missing, but got %s instead", c)
+	}
+}
+
+func TestGraylog(t *testing.T) {
+	recorder := cuetest.NewUDPRecorder()
+	recorder.Start()
+	defer recorder.Close()
+
+	c := Graylog{Address: recorder.Address(), Host: "testhost"}.New()
+	c.Collect(cuetest.ErrorEvent)
+	cuetest.CloseCollector(c)
+	recorder.Close()
+
+	datagrams := recorder.Datagrams()
+	if len(datagrams) != 1 {
+		t.Fatalf("Expected 1 datagram but received %d instead", len(datagrams))
+	}
+	checkGraylogMessage(t, datagrams[0], "error event", 3)
+}
+
+func TestGraylogChunking(t *testing.T) {
+	recorder := cuetest.NewUDPRecorder()
+	recorder.Start()
+	defer recorder.Close()
+
+	message := strings.Repeat("x", 5000)
+	event := cuetest.GenerateEvent(cue.INFO, cuetest.DebugEvent.Context, message, nil, 0)
+	c := Graylog{Address: recorder.Address(), Host: "testhost", ChunkSize: 1000}.New()
+	c.Collect(event)
+	cuetest.CloseCollector(c)
+	recorder.Close()
+
+	datagrams := recorder.Datagrams()
+	if len(datagrams) != 6 {
+		t.Fatalf("Expected 6 chunks but received %d instead", len(datagrams))
+	}
+
+	var reassembled []byte
+	for i, datagram := range datagrams {
+		if len(datagram) > 1000 {
+			t.Errorf("Expected chunks of at most 1000 bytes, but chunk %d has %d bytes", i, len(datagram))
+		}
+		if !bytes.Equal(datagram[:2], gelfChunkMagic) {
+			t.Errorf("Expected chunk %d to start with the GELF chunk magic bytes, but saw %x instead", i, datagram[:2])
+		}
+		if !bytes.Equal(datagram[2:10], datagrams[0][2:10]) {
+			t.Errorf("Expected all chunks to share a message id, but chunk %d has %x instead of %x", i, datagram[2:10], datagrams[0][2:10])
+		}
+		if int(datagram[10]) != i || int(datagram[11]) != len(datagrams) {
+			t.Errorf("Expected chunk %d of %d, but saw chunk %d of %d instead", i, len(datagrams), datagram[10], datagram[11])
+		}
+		reassembled = append(reassembled, datagram[gelfChunkHeaderSize:]...)
+	}
+	checkGraylogMessage(t, reassembled, message, 6)
+}
+
+func TestGraylogTooManyChunks(t *testing.T) {
+	recorder := cuetest.NewUDPRecorder()
+	recorder.Start()
+	defer recorder.Close()
+
+	c := Graylog{Address: recorder.Address(), ChunkSize: gelfChunkHeaderSize + 1}.New()
+	err := c.Collect(cuetest.DebugEvent)
+	if err == nil {
+		t.Error("Expected an error for an event requiring more than 128 chunks, but didn't see one")
+	}
+	cuetest.CloseCollector(c)
+	recorder.Close()
+
+	if len(recorder.Datagrams()) != 0 {
+		t.Errorf("Expected the oversized event to be dropped, but saw %d datagrams", len(recorder.Datagrams()))
+	}
+}
+
+func TestGraylogLevelsMatchSyslog(t *testing.T) {
+	for level := cue.FATAL; level <= cue.TRACE; level++ {
+		event := cuetest.GenerateEvent(level, cuetest.DebugEvent.Context, "test", nil, 0)
+		var decoded map[string]interface{}
+		err := json.Unmarshal(format.RenderBytes(format.GELF, event), &decoded)
+		if err != nil {
+			t.Fatalf("Failed to decode GELF message: %s", err)
+		}
+		if decoded["level"] != float64(severityFor(level)) {
+			t.Errorf("Expected GELF level for %s to match syslog severity %d, but saw %v instead", level, severityFor(level), decoded["level"])
+		}
+	}
+}
+
+func TestGraylogString(t *testing.T) {
+	c := Graylog{Address: "localhost:12201"}.New()
+
+	// Ensure nothing panics
+	_ = fmt.Sprint(c)
+}
+
+func checkGraylogMessage(t *testing.T, datagram []byte, message string, level int) {
+	var decoded map[string]interface{}
+	err := json.Unmarshal(datagram, &decoded)
+	if err != nil {
+		t.Fatalf("Failed to decode GELF message: %s", err)
+	}
+	if decoded["version"] != "1.1" || decoded["host"] != "testhost" {
+		t.Errorf("Unexpected GELF version or host: %v", decoded)
+	}
+	if decoded["short_message"] != message {
+		t.Errorf("Expected short_message %q, but saw %q instead", message, decoded["short_message"])
+	}
+	if decoded["level"] != float64(level) {
+		t.Errorf("Expected level %d, but saw %v instead", level, decoded["level"])
+	}
+	if decoded["_k1"] != "some value" {
+		t.Errorf("Expected context field _k1 to be set, but saw %v instead", decoded["_k1"])
+	}
+}
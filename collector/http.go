@@ -24,19 +24,54 @@ import (
 	"fmt"
 	"github.com/bobziuchkovski/cue"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// defaultTimeout bounds requests submitted via the default client.
+const defaultTimeout = 30 * time.Second
+
+// defaultClient is shared by all HTTP collectors that don't specify a Client.
+// This allows collectors to reuse connections from a single pool.
+var defaultClient = &http.Client{Transport: defaultTransport(), Timeout: defaultTimeout}
+
 // HTTP represents configuration for http-based Collector instances. For each
 // event, the collector calls RequestFormatter to generate a new http request.
 // It then submits the request, setting a cue-specific User-Agent header.  The
 // response status code is checked, but the content is otherwise ignored.  The
 // collector treats 4XX and 5XX status codes as errors.
+//
+// If a 429 (Too Many Requests) or 503 (Service Unavailable) response carries
+// a Retry-After header, the collector waits the indicated duration, capped at
+// MaxRetryAfter, and then retries the request once.  The header may specify
+// either a number of seconds or an HTTP date.  Requests are only retried if
+// their body can be re-read via http.Request.GetBody, which is set
+// automatically by http.NewRequest for common body types.
+//
+// Requests block until a response is received or the request times out, and
+// waiting on Retry-After blocks as well.  Registering HTTP collectors via
+// cue.Collect is dangerous, since a slow endpoint then blocks every logging
+// call that matches the collector's threshold.  HTTP collectors should be
+// registered via cue.CollectAsync instead.
 type HTTP struct {
 	// Required
 	RequestFormatter func(event *cue.Event) (*http.Request, error)
 
-	// If specified, submit the generated requests via Client
+	// If specified, submit the generated requests via Client.  Otherwise, a
+	// default client is used.  The default client is shared across HTTP
+	// collectors so that connections are pooled and reused.
 	Client *http.Client
+
+	// Timeout bounds each request, including reading the response, when
+	// Client isn't specified.  Default: 30 seconds.  If negative, requests
+	// never time out.  Ignored if Client is specified, in which case the
+	// client's own Timeout applies.
+	Timeout time.Duration
+
+	// MaxRetryAfter caps the time spent waiting on a Retry-After header.
+	// Default: 30 seconds.  If negative, Retry-After headers are ignored.
+	MaxRetryAfter time.Duration
 }
 
 // New returns a new collector based on the HTTP configuration.
@@ -46,7 +81,10 @@ func (h HTTP) New() cue.Collector {
 		return nil
 	}
 	if h.Client == nil {
-		h.Client = &http.Client{}
+		h.Client = clientWithTimeout(h.Timeout)
+	}
+	if h.MaxRetryAfter == 0 {
+		h.MaxRetryAfter = 30 * time.Second
 	}
 	return &httpCollector{HTTP: h}
 }
@@ -64,16 +102,130 @@ func (h *httpCollector) Collect(event *cue.Event) error {
 	if err != nil {
 		return err
 	}
+	return h.send(request)
+}
+
+func (h *httpCollector) send(request *http.Request) error {
 	request.Header.Set("User-Agent", fmt.Sprintf("github.com/bobziuchkovski/cue %d.%d.%d", cue.Version.Major, cue.Version.Minor, cue.Version.Patch))
+	wait, err := h.do(request)
+	if wait < 0 {
+		return err
+	}
+
+	retry, bodyErr := rewindRequest(request)
+	if bodyErr != nil {
+		return err
+	}
+	time.Sleep(wait)
+	_, err = h.do(retry)
+	return err
+}
+
+// do submits the request.  If the response indicates the request should be
+// retried after a delay, the delay is returned.  Otherwise the returned
+// delay is negative.
+func (h *httpCollector) do(request *http.Request) (time.Duration, error) {
 	resp, err := h.Client.Do(request)
 	if resp != nil && resp.Body != nil {
 		defer resp.Body.Close()
 	}
 	if err != nil {
-		return fmt.Errorf("cue/collector: http error: url=%s, error=%q", request.URL, err.Error())
+		return -1, fmt.Errorf("cue/collector: http error: url=%s, error=%q", request.URL, err.Error())
 	}
 	if resp.StatusCode >= 400 {
-		return fmt.Errorf("cue/collector: http error: url=%s, code=%d", request.URL, resp.StatusCode)
+		return h.retryAfter(resp), &httpStatusError{url: request.URL.String(), code: resp.StatusCode}
+	}
+	return -1, nil
+}
+
+func (h *httpCollector) retryAfter(resp *http.Response) time.Duration {
+	if h.MaxRetryAfter < 0 {
+		return -1
+	}
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return -1
+	}
+	wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	if !ok {
+		return -1
+	}
+	if wait > h.MaxRetryAfter {
+		wait = h.MaxRetryAfter
+	}
+	return wait
+}
+
+// parseRetryAfter parses a Retry-After header value, which is either a
+// number of seconds or an HTTP date, relative to now.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	wait := date.Sub(now)
+	if wait < 0 {
+		wait = 0
+	}
+	return wait, true
+}
+
+// rewindRequest returns a copy of request with a fresh body, suitable for
+// resubmission.
+func rewindRequest(request *http.Request) (*http.Request, error) {
+	retry := request.Clone(request.Context())
+	if request.Body == nil || request.Body == http.NoBody {
+		return retry, nil
+	}
+	if request.GetBody == nil {
+		return nil, fmt.Errorf("cue/collector: http request body can't be re-read for retry: url=%s", request.URL)
+	}
+	body, err := request.GetBody()
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	retry.Body = body
+	return retry, nil
+}
+
+// httpStatusError is returned for 4XX and 5XX responses.  It allows wrappers
+// of the HTTP collector to distinguish client errors from server errors.
+type httpStatusError struct {
+	url  string
+	code int
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("cue/collector: http error: url=%s, code=%d", e.url, e.code)
+}
+
+// clientWithTimeout returns the default client if timeout is 0.  Otherwise it
+// returns a client with the given timeout that shares the default client's
+// connection pool.  Negative timeouts disable the timeout.
+func clientWithTimeout(timeout time.Duration) *http.Client {
+	if timeout == 0 {
+		return defaultClient
+	}
+	if timeout < 0 {
+		timeout = 0
+	}
+	return &http.Client{Transport: defaultClient.Transport, Timeout: timeout}
+}
+
+func defaultTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	// The net/http default is 2 idle connections per host, which causes
+	// excessive connection churn for busy collectors.
+	transport.MaxIdleConnsPerHost = 16
+	return transport
 }
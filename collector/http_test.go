@@ -29,7 +29,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestHTTPNilCollector(t *testing.T) {
@@ -107,6 +110,143 @@ func TestHTTP5XXErrorCode(t *testing.T) {
 	}
 }
 
+func TestHTTPRetryAfter(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			w.Header().Set("Retry-After", "2")
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+		}
+	}))
+	defer s.Close()
+
+	c := HTTP{RequestFormatter: newHTTPRequestFormatter(s.URL)}.New()
+	start := time.Now()
+	err := c.Collect(cuetest.DebugEvent)
+	elapsed := time.Since(start)
+	if err != nil {
+		t.Errorf("Expected the retried request to succeed, but saw %s", err)
+	}
+	if elapsed < 2*time.Second || elapsed > 5*time.Second {
+		t.Errorf("Expected to wait approximately 2 seconds before retrying, but waited %s", elapsed)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(bodies) != 2 || bodies[0] != bodies[1] || bodies[0] == "" {
+		t.Errorf("Expected the same request body to be sent twice, but saw %q", bodies)
+	}
+}
+
+func TestHTTPRetryAfterCapped(t *testing.T) {
+	var requests int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Retry-After", "3600")
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	c := HTTP{RequestFormatter: newHTTPRequestFormatter(s.URL), MaxRetryAfter: 10 * time.Millisecond}.New()
+	start := time.Now()
+	err := c.Collect(cuetest.DebugEvent)
+	if err == nil {
+		t.Error("Expected an error after the retry failed, but didn't see one")
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("Expected the wait to be capped, but waited %s", time.Since(start))
+	}
+	if atomic.LoadInt32(&requests) != 2 {
+		t.Errorf("Expected exactly 2 requests, but saw %d", atomic.LoadInt32(&requests))
+	}
+
+	atomic.StoreInt32(&requests, 0)
+	c = HTTP{RequestFormatter: newHTTPRequestFormatter(s.URL), MaxRetryAfter: -1}.New()
+	c.Collect(cuetest.DebugEvent)
+	if atomic.LoadInt32(&requests) != 1 {
+		t.Errorf("Expected Retry-After to be ignored when disabled, but saw %d requests", atomic.LoadInt32(&requests))
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2016, 1, 2, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		Value    string
+		Expected time.Duration
+		OK       bool
+	}{
+		{Value: "2", Expected: 2 * time.Second, OK: true},
+		{Value: " 0 ", Expected: 0, OK: true},
+		{Value: "Sat, 02 Jan 2016 15:04:35 GMT", Expected: 30 * time.Second, OK: true},
+		{Value: "Sat, 02 Jan 2016 15:00:00 GMT", Expected: 0, OK: true},
+		{Value: "-1", OK: false},
+		{Value: "soon", OK: false},
+		{Value: "", OK: false},
+	}
+	for _, test := range tests {
+		wait, ok := parseRetryAfter(test.Value, now)
+		if ok != test.OK || wait != test.Expected {
+			t.Errorf("Unexpected result parsing Retry-After %q.  Expected: %s, %t, Received: %s, %t", test.Value, test.Expected, test.OK, wait, ok)
+		}
+	}
+}
+
+func TestHTTPDefaultClient(t *testing.T) {
+	c1 := HTTP{RequestFormatter: newHTTPRequestFormatter("http://bogus.private")}.New().(*httpCollector)
+	c2 := HTTP{RequestFormatter: newHTTPRequestFormatter("http://bogus.private")}.New().(*httpCollector)
+	if c1.Client != c2.Client {
+		t.Error("Expected HTTP collectors to share a default client, but they don't")
+	}
+
+	transport := c1.Client.Transport.(*http.Transport)
+	if transport.MaxIdleConnsPerHost <= http.DefaultMaxIdleConnsPerHost {
+		t.Errorf("Expected the default client to allow more than %d idle connections per host, but saw %d instead", http.DefaultMaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+	}
+}
+
+func TestHTTPTimeout(t *testing.T) {
+	unblock := make(chan struct{})
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+	}))
+	defer s.Close()
+	defer close(unblock)
+
+	c := HTTP{RequestFormatter: newHTTPRequestFormatter(s.URL), Timeout: 50 * time.Millisecond}.New().(*httpCollector)
+	if c.Client.Transport != defaultClient.Transport {
+		t.Error("Expected a client with a custom timeout to share the default transport, but it doesn't")
+	}
+	start := time.Now()
+	err := c.Collect(cuetest.DebugEvent)
+	if err == nil {
+		t.Error("Expected to receive a timeout error but didn't")
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("Expected the request to time out promptly, but it took %s", time.Since(start))
+	}
+}
+
+func TestHTTPTimeoutDefaults(t *testing.T) {
+	c := HTTP{RequestFormatter: newHTTPRequestFormatter("http://bogus.private")}.New().(*httpCollector)
+	if c.Client.Timeout != 30*time.Second {
+		t.Errorf("Expected a default timeout of 30s, but saw %s instead", c.Client.Timeout)
+	}
+	c = HTTP{RequestFormatter: newHTTPRequestFormatter("http://bogus.private"), Timeout: -1}.New().(*httpCollector)
+	if c.Client.Timeout != 0 {
+		t.Errorf("Expected a negative timeout to disable timeouts, but saw %s instead", c.Client.Timeout)
+	}
+	custom := &http.Client{}
+	c = HTTP{RequestFormatter: newHTTPRequestFormatter("http://bogus.private"), Client: custom, Timeout: time.Second}.New().(*httpCollector)
+	if c.Client != custom || custom.Timeout != 0 {
+		t.Error("Expected a custom client to be used unaltered, but it wasn't")
+	}
+}
+
 func TestHTTPStirng(t *testing.T) {
 	c := HTTP{RequestFormatter: newHTTPRequestFormatter("http://bogus.private")}.New()
 
@@ -0,0 +1,91 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package collector
+
+import (
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"io"
+	"strings"
+)
+
+// Multi returns a collector that fans events out to each of the given
+// collectors in order.  Nil collectors are ignored.  The composite collector
+// is registered, degraded, and closed as a unit, and a single Pipeline may be
+// attached in front of it.  If no non-nil collectors are provided, Multi emits
+// a WARN log event and returns a nil collector.
+//
+// Every child receives each event, even if an earlier child fails.  Collect
+// returns the first error encountered.  Note that cue re-sends failed events,
+// so children that succeeded may receive the same event more than once.
+// Close closes every child that implements io.Closer and returns the first
+// error encountered.
+func Multi(collectors ...cue.Collector) cue.Collector {
+	var children []cue.Collector
+	for _, c := range collectors {
+		if c != nil {
+			children = append(children, c)
+		}
+	}
+	if len(children) == 0 {
+		log.Warn("Multi called to create a collector, but no collectors were provided.  Returning nil collector.")
+		return nil
+	}
+	return &multiCollector{children: children}
+}
+
+type multiCollector struct {
+	children []cue.Collector
+}
+
+func (m *multiCollector) String() string {
+	names := make([]string, len(m.children))
+	for i, c := range m.children {
+		names[i] = fmt.Sprint(c)
+	}
+	return fmt.Sprintf("Multi(%s)", strings.Join(names, ", "))
+}
+
+func (m *multiCollector) Collect(event *cue.Event) error {
+	var first error
+	for _, c := range m.children {
+		err := c.Collect(event)
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func (m *multiCollector) Close() error {
+	var first error
+	for _, c := range m.children {
+		closer, ok := c.(io.Closer)
+		if !ok {
+			continue
+		}
+		err := closer.Close()
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package collector
+
+import (
+	"fmt"
+	"github.com/bobziuchkovski/cue/internal/cuetest"
+	"testing"
+)
+
+func TestMultiNilCollector(t *testing.T) {
+	c := Multi()
+	if c != nil {
+		t.Errorf("Expected a nil collector when no collectors are provided, but got %s instead", c)
+	}
+
+	c = Multi(nil, nil)
+	if c != nil {
+		t.Errorf("Expected a nil collector when only nil collectors are provided, but got %s instead", c)
+	}
+}
+
+func TestMulti(t *testing.T) {
+	c1 := cuetest.NewCapturingCollector()
+	c2 := cuetest.NewCapturingCollector()
+	c := Multi(c1, nil, c2)
+
+	c.Collect(cuetest.DebugEvent)
+	c.Collect(cuetest.InfoEvent)
+	checkRouted(t, "c1", c1, 2)
+	checkRouted(t, "c2", c2, 2)
+	if c1.Captured()[1] != cuetest.InfoEvent || c2.Captured()[1] != cuetest.InfoEvent {
+		t.Error("Expected events to be collected in order, but they weren't")
+	}
+}
+
+func TestMultiErrors(t *testing.T) {
+	failing1 := &failingCollector{fail: true}
+	failing2 := &failingCollector{fail: true}
+	capture := cuetest.NewCapturingCollector()
+	c := Multi(failing1, capture, failing2)
+
+	err := c.Collect(cuetest.DebugEvent)
+	if err == nil {
+		t.Error("Expected an error to be returned, but it wasn't")
+	}
+	checkRouted(t, "capture", capture, 1)
+
+	failing1.fail = false
+	failing2.fail = false
+	err = c.Collect(cuetest.DebugEvent)
+	if err != nil {
+		t.Errorf("Expected no error to be returned, but received %s", err)
+	}
+}
+
+func TestMultiClose(t *testing.T) {
+	closing1 := &closingCollector{}
+	closing2 := &closingCollector{}
+	c := Multi(closing1, cuetest.NewCapturingCollector(), closing2)
+
+	cuetest.CloseCollector(c)
+	if closing1.closes != 1 || closing2.closes != 1 {
+		t.Errorf("Expected each closer to be closed once, but saw %d and %d closes instead", closing1.closes, closing2.closes)
+	}
+}
+
+func TestMultiString(t *testing.T) {
+	c := Multi(cuetest.NewCapturingCollector(), &closingCollector{})
+
+	// Ensure nothing panics
+	_ = fmt.Sprint(c)
+}
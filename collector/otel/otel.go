@@ -0,0 +1,246 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build otel
+// +build otel
+
+package otel
+
+import (
+	gocontext "context"
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/collector"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"math"
+	"reflect"
+	"time"
+)
+
+var log = cue.NewLogger("github.com/bobziuchkovski/cue/collector/otel")
+
+// OTel represents configuration for Collector instances that export events
+// as OpenTelemetry log records.  Events are batched as described by
+// collector.Batch and passed to Exporter in bulk.  Events are mapped to
+// records as follows:
+//
+//	Timestamp      The event time
+//	Severity       TRACE, DEBUG, INFO, WARN, ERROR, and FATAL map to the
+//	               corresponding OpenTelemetry severity numbers
+//	SeverityText   The event level, e.g. "INFO"
+//	Body           The event message
+//	Attributes     Each context key/value pair.  Strings, bools, integers,
+//	               and floats retain their types.  Other values are
+//	               written as strings.  The event error, if any, is added
+//	               under the "exception.message" key.
+//	TraceID        The value of the TraceIDKey context key, if it's a valid
+//	               hex-encoded trace ID
+//	SpanID         The value of the SpanIDKey context key, if it's a valid
+//	               hex-encoded span ID
+//
+// Context values used for trace and span IDs aren't added as attributes.
+// Records are created by an OpenTelemetry SDK LoggerProvider, so attribute
+// limits follow the SDK defaults and may be adjusted via the standard
+// OTEL_LOGRECORD_ATTRIBUTE_COUNT_LIMIT and
+// OTEL_LOGRECORD_ATTRIBUTE_VALUE_LENGTH_LIMIT environment variables.  The
+// exporter is shut down when the collector is closed.
+type OTel struct {
+	// Required
+	Exporter sdklog.Exporter
+
+	// Optional
+	TraceIDKey    string        // Default: "trace_id"
+	SpanIDKey     string        // Default: "span_id"
+	BatchSize     int           // Default: 100
+	FlushInterval time.Duration // Default: 5 seconds
+	Timeout       time.Duration // Maximum duration of each export.  Default: 10 seconds
+}
+
+// New returns a new collector based on the OTel configuration.
+func (o OTel) New() cue.Collector {
+	if o.Exporter == nil {
+		log.Warn("OTel.New called to created a collector, but Exporter param is empty.  Returning nil collector.")
+		return nil
+	}
+	if o.TraceIDKey == "" {
+		o.TraceIDKey = "trace_id"
+	}
+	if o.SpanIDKey == "" {
+		o.SpanIDKey = "span_id"
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = 10 * time.Second
+	}
+
+	c := &otelCollector{OTel: o}
+	c.provider = sdklog.NewLoggerProvider(sdklog.WithProcessor(&c.pending))
+	c.logger = c.provider.Logger("github.com/bobziuchkovski/cue")
+	c.batch = collector.Batch{
+		Flush:    c.export,
+		MaxSize:  o.BatchSize,
+		MaxDelay: o.FlushInterval,
+	}.New()
+	return c
+}
+
+type otelCollector struct {
+	OTel
+	batch    cue.Collector
+	provider *sdklog.LoggerProvider
+	logger   otellog.Logger
+	pending  recordBuffer
+}
+
+// recordBuffer is an sdklog.Processor that retains emitted records until
+// they're exported.  Batch serializes calls to export, so records are only
+// emitted and taken by a single goroutine at a time.
+type recordBuffer struct {
+	records []sdklog.Record
+}
+
+func (r *recordBuffer) OnEmit(ctx gocontext.Context, record *sdklog.Record) error {
+	r.records = append(r.records, record.Clone())
+	return nil
+}
+
+func (r *recordBuffer) Shutdown(ctx gocontext.Context) error {
+	return nil
+}
+
+func (r *recordBuffer) ForceFlush(ctx gocontext.Context) error {
+	return nil
+}
+
+func (r *recordBuffer) take() []sdklog.Record {
+	records := r.records
+	r.records = nil
+	return records
+}
+
+func (o *otelCollector) String() string {
+	return fmt.Sprintf("OTel(exporter=%T)", o.Exporter)
+}
+
+func (o *otelCollector) Collect(event *cue.Event) error {
+	return o.batch.Collect(event)
+}
+
+func (o *otelCollector) Close() error {
+	err := o.batch.(io.Closer).Close()
+
+	ctx, cancel := gocontext.WithTimeout(gocontext.Background(), o.Timeout)
+	defer cancel()
+	if shutdownErr := o.Exporter.Shutdown(ctx); err == nil {
+		err = shutdownErr
+	}
+	if shutdownErr := o.provider.Shutdown(ctx); err == nil {
+		err = shutdownErr
+	}
+	return err
+}
+
+func (o *otelCollector) export(events []*cue.Event) error {
+	for _, event := range events {
+		o.emit(event)
+	}
+	records := o.pending.take()
+
+	ctx, cancel := gocontext.WithTimeout(gocontext.Background(), o.Timeout)
+	defer cancel()
+	return o.Exporter.Export(ctx, records)
+}
+
+// emit passes the record for event through the SDK logger, which applies
+// the provider's attribute limits and resource before the record reaches
+// o.pending.
+func (o *otelCollector) emit(event *cue.Event) {
+	var record otellog.Record
+	record.SetTimestamp(event.Time)
+	record.SetSeverity(severityFor(event.Level))
+	record.SetSeverityText(event.Level.String())
+	record.SetBody(otellog.StringValue(event.Message))
+
+	var span trace.SpanContextConfig
+	var attrs []otellog.KeyValue
+	event.Context.Each(func(key string, value interface{}) {
+		switch key {
+		case o.TraceIDKey:
+			if id, err := trace.TraceIDFromHex(fmt.Sprint(value)); err == nil {
+				span.TraceID = id
+				return
+			}
+		case o.SpanIDKey:
+			if id, err := trace.SpanIDFromHex(fmt.Sprint(value)); err == nil {
+				span.SpanID = id
+				return
+			}
+		}
+		attrs = append(attrs, attributeFor(key, value))
+	})
+	if event.Error != nil {
+		attrs = append(attrs, otellog.String("exception.message", event.Error.Error()))
+	}
+	record.AddAttributes(attrs...)
+
+	// The SDK takes trace and span IDs from the span context of ctx.
+	ctx := trace.ContextWithSpanContext(gocontext.Background(), trace.NewSpanContext(span))
+	o.logger.Emit(ctx, record)
+}
+
+func severityFor(level cue.Level) otellog.Severity {
+	switch level {
+	case cue.TRACE:
+		return otellog.SeverityTrace
+	case cue.DEBUG:
+		return otellog.SeverityDebug
+	case cue.INFO:
+		return otellog.SeverityInfo
+	case cue.WARN:
+		return otellog.SeverityWarn
+	case cue.ERROR:
+		return otellog.SeverityError
+	case cue.FATAL:
+		return otellog.SeverityFatal
+	default:
+		return otellog.SeverityUndefined
+	}
+}
+
+func attributeFor(key string, value interface{}) otellog.KeyValue {
+	rval := reflect.ValueOf(value)
+	switch rval.Kind() {
+	case reflect.String:
+		return otellog.String(key, rval.String())
+	case reflect.Bool:
+		return otellog.Bool(key, rval.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return otellog.Int64(key, rval.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if rval.Uint() <= math.MaxInt64 {
+			return otellog.Int64(key, int64(rval.Uint()))
+		}
+	case reflect.Float32, reflect.Float64:
+		return otellog.Float64(key, rval.Float())
+	}
+	return otellog.String(key, fmt.Sprint(value))
+}
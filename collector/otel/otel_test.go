@@ -0,0 +1,165 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build otel
+// +build otel
+
+package otel
+
+import (
+	gocontext "context"
+	"errors"
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/internal/cuetest"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"io"
+	"sync"
+	"testing"
+)
+
+type stubExporter struct {
+	mu       sync.Mutex
+	records  []sdklog.Record
+	shutdown bool
+}
+
+func (s *stubExporter) Export(ctx gocontext.Context, records []sdklog.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, record := range records {
+		s.records = append(s.records, record.Clone())
+	}
+	return nil
+}
+
+func (s *stubExporter) Shutdown(ctx gocontext.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.shutdown = true
+	return nil
+}
+
+func (s *stubExporter) ForceFlush(ctx gocontext.Context) error {
+	return nil
+}
+
+func TestOTelNilCollector(t *testing.T) {
+	c := OTel{}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when the exporter is missing, but got %s instead", c)
+	}
+}
+
+func TestOTelSeverity(t *testing.T) {
+	exporter := &stubExporter{}
+	c := OTel{Exporter: exporter}.New()
+
+	levels := []cue.Level{cue.TRACE, cue.DEBUG, cue.INFO, cue.WARN, cue.ERROR, cue.FATAL}
+	for _, level := range levels {
+		c.Collect(cuetest.GenerateEvent(level, cue.NewContext("test"), "test", nil, 0))
+	}
+	c.(io.Closer).Close()
+
+	expected := []otellog.Severity{
+		otellog.SeverityTrace,
+		otellog.SeverityDebug,
+		otellog.SeverityInfo,
+		otellog.SeverityWarn,
+		otellog.SeverityError,
+		otellog.SeverityFatal,
+	}
+	if len(exporter.records) != len(expected) {
+		t.Fatalf("Expected %d exported records, but saw %d instead", len(expected), len(exporter.records))
+	}
+	for i, record := range exporter.records {
+		if record.Severity() != expected[i] {
+			t.Errorf("Expected severity %s for %s, but saw %s instead", expected[i], levels[i], record.Severity())
+		}
+		if record.SeverityText() != levels[i].String() {
+			t.Errorf("Expected severity text %s, but saw %s instead", levels[i], record.SeverityText())
+		}
+	}
+	if !exporter.shutdown {
+		t.Error("Expected the exporter to be shut down on close")
+	}
+}
+
+func TestOTelAttributes(t *testing.T) {
+	exporter := &stubExporter{}
+	c := OTel{Exporter: exporter}.New()
+
+	ctx := cue.NewContext("test").
+		WithValue("str", "value").
+		WithValue("int", 42).
+		WithValue("float", 3.5).
+		WithValue("bool", true).
+		WithValue("trace_id", "0102030405060708090a0b0c0d0e0f10").
+		WithValue("span_id", "0102030405060708")
+	event := cuetest.GenerateEvent(cue.ERROR, ctx, "test message", errors.New("test error"), 0)
+	c.Collect(event)
+	c.(io.Closer).Close()
+
+	if len(exporter.records) != 1 {
+		t.Fatalf("Expected 1 exported record, but saw %d instead", len(exporter.records))
+	}
+	record := exporter.records[0]
+	if record.Body().AsString() != "test message" {
+		t.Errorf("Expected body %q, but saw %q instead", "test message", record.Body().AsString())
+	}
+	if !record.Timestamp().Equal(event.Time) {
+		t.Errorf("Expected timestamp %s, but saw %s instead", event.Time, record.Timestamp())
+	}
+	if record.TraceID().String() != "0102030405060708090a0b0c0d0e0f10" {
+		t.Errorf("Expected the trace ID to be set from context, but saw %s instead", record.TraceID())
+	}
+	if record.SpanID().String() != "0102030405060708" {
+		t.Errorf("Expected the span ID to be set from context, but saw %s instead", record.SpanID())
+	}
+
+	attrs := make(map[string]otellog.Value)
+	record.WalkAttributes(func(kv otellog.KeyValue) bool {
+		attrs[kv.Key] = kv.Value
+		return true
+	})
+	expected := map[string]otellog.Value{
+		"str":               otellog.StringValue("value"),
+		"int":               otellog.Int64Value(42),
+		"float":             otellog.Float64Value(3.5),
+		"bool":              otellog.BoolValue(true),
+		"exception.message": otellog.StringValue("test error"),
+	}
+	if len(attrs) != len(expected) {
+		t.Errorf("Expected attributes %v, but saw %v instead", expected, attrs)
+	}
+	for key, value := range expected {
+		if !attrs[key].Equal(value) {
+			t.Errorf("Expected attribute %s=%s, but saw %s instead", key, value, attrs[key])
+		}
+	}
+}
+
+func TestOTelString(t *testing.T) {
+	c := OTel{Exporter: &stubExporter{}}.New()
+
+	// Ensure nothing panics
+	_ = fmt.Sprint(c)
+}
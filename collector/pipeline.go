@@ -24,8 +24,12 @@ import (
 	"fmt"
 	"github.com/bobziuchkovski/cue"
 	"io"
+	"regexp"
 )
 
+// redactedValue replaces values masked by RedactKeys.
+const redactedValue = "[REDACTED]"
+
 // ContextFilter is used with a Pipeline to filter context key/value pairs.
 type ContextFilter func(key string, value interface{}) bool
 
@@ -33,6 +37,52 @@ type ContextFilter func(key string, value interface{}) bool
 // pairs.
 type ContextTransformer func(context cue.Context) cue.Context
 
+// RedactContext returns a ContextTransformer that replaces substrings
+// matching pattern in string context values with replacement.  The
+// replacement string may reference submatches, as with
+// regexp.Regexp.ReplaceAllString.  This is useful for masking sensitive data,
+// such as credit card numbers or auth tokens, across all context fields.
+// Non-string values are passed through unaltered.
+func RedactContext(pattern *regexp.Regexp, replacement string) ContextTransformer {
+	return func(context cue.Context) cue.Context {
+		redacted := make(cue.Fields)
+		context.Each(func(key string, value interface{}) {
+			s, ok := value.(string)
+			if !ok {
+				return
+			}
+			if replaced := pattern.ReplaceAllString(s, replacement); replaced != s {
+				redacted[key] = replaced
+			}
+		})
+		if len(redacted) == 0 {
+			return context
+		}
+		return context.WithFields(redacted)
+	}
+}
+
+// RedactKeys returns a ContextTransformer that replaces the values for the
+// named context keys with "[REDACTED]", regardless of their content or type.
+// Keys missing from the context are skipped.
+func RedactKeys(keys ...string) ContextTransformer {
+	return func(context cue.Context) cue.Context {
+		redacted := make(cue.Fields)
+		context.Each(func(key string, value interface{}) {
+			for _, k := range keys {
+				if key == k {
+					redacted[key] = redactedValue
+					return
+				}
+			}
+		})
+		if len(redacted) == 0 {
+			return context
+		}
+		return context.WithFields(redacted)
+	}
+}
+
 // EventFilter is used with a Pipeline to filter events.
 type EventFilter func(event *cue.Event) bool
 
@@ -71,7 +121,8 @@ func NewPipeline() *Pipeline {
 }
 
 // FilterContext returns an updated copy of Pipeline that drops Context
-// key/value pairs that match any of the provided filters.
+// key/value pairs that match any of the provided filters.  Retained values are
+// passed through unaltered.  See cue.FilterContext for details.
 func (p *Pipeline) FilterContext(filters ...ContextFilter) *Pipeline {
 	return &Pipeline{
 		prior:       p,
@@ -122,7 +173,7 @@ func (p *Pipeline) apply(event *cue.Event) *cue.Event {
 		return nil
 	}
 	if p.prior == nil {
-		return cloneEvent(event)
+		return event.Clone()
 	}
 	return p.transformer(p.prior.apply(event))
 }
@@ -154,16 +205,14 @@ func (p *pipelineCollector) Close() error {
 
 func filterContext(filters ...ContextFilter) EventTransformer {
 	return func(event *cue.Event) *cue.Event {
-		newContext := cue.NewContext(event.Context.Name())
-		event.Context.Each(func(key string, value interface{}) {
+		event.Context = cue.FilterContext(event.Context, func(key string, value interface{}) bool {
 			for _, filter := range filters {
 				if filter(key, value) {
-					return
+					return true
 				}
 			}
-			newContext = newContext.WithValue(key, value)
+			return false
 		})
-		event.Context = newContext
 		return event
 	}
 }
@@ -208,14 +257,3 @@ func filterNilEvent(transformer EventTransformer) EventTransformer {
 		return transformer(event)
 	}
 }
-
-func cloneEvent(e *cue.Event) *cue.Event {
-	return &cue.Event{
-		Time:    e.Time,
-		Level:   e.Level,
-		Context: e.Context,
-		Frames:  e.Frames,
-		Error:   e.Error,
-		Message: e.Message,
-	}
-}
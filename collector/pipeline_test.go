@@ -23,9 +23,13 @@ package collector
 import (
 	"fmt"
 	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/format"
 	"github.com/bobziuchkovski/cue/internal/cuetest"
 	"reflect"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestPipelineContextFilter(t *testing.T) {
@@ -59,6 +63,55 @@ func TestPipelineContextFilter(t *testing.T) {
 	}
 }
 
+// richContext is a minimal cue.Context holding values that WithValue would
+// coerce.
+type richContext cue.Fields
+
+func (r richContext) Name() string   { return "rich" }
+func (r richContext) NumValues() int { return len(r) }
+func (r richContext) Fields() cue.Fields {
+	fields := make(cue.Fields)
+	for k, v := range r {
+		fields[k] = v
+	}
+	return fields
+}
+func (r richContext) Each(fn func(key string, value interface{})) {
+	for k, v := range r {
+		fn(k, v)
+	}
+}
+func (r richContext) WithFields(fields cue.Fields) cue.Context { return r }
+func (r richContext) WithValue(key string, value interface{}) cue.Context {
+	return r
+}
+
+func TestPipelineContextFilterPreservesTypes(t *testing.T) {
+	c := cuetest.NewCapturingCollector()
+	p := NewPipeline().FilterContext(func(key string, value interface{}) bool {
+		return key == "secret"
+	})
+
+	event := cuetest.DebugEvent.Clone()
+	event.Context = richContext{
+		"ints":     []int{1, 2, 3},
+		"duration": 1500 * time.Millisecond,
+		"secret":   "s",
+	}
+	p.Attach(c).Collect(event)
+
+	expected := cue.Fields{
+		"ints":     []int{1, 2, 3},
+		"duration": 1500 * time.Millisecond,
+	}
+	if !reflect.DeepEqual(c.Captured()[0].Context.Fields(), expected) {
+		t.Errorf("Expected context values to retain their types, %#v, but saw %#v instead", expected, c.Captured()[0].Context.Fields())
+	}
+	if c.Captured()[0].Context.Name() != "rich" {
+		t.Errorf("Expected the context name to be retained, but saw %q instead", c.Captured()[0].Context.Name())
+	}
+}
+
 func TestPipelineEventFilter(t *testing.T) {
 	c1 := cuetest.NewCapturingCollector()
 	p1 := NewPipeline().FilterEvent(func(event *cue.Event) bool {
@@ -106,6 +159,56 @@ func TestPipelineContextTransformer(t *testing.T) {
 	}
 }
 
+func TestRedactContext(t *testing.T) {
+	c1 := cuetest.NewCapturingCollector()
+	ctx := cue.NewContext("test").
+		WithValue("card", "card 4111-1111-1111-1111 on file").
+		WithValue("cards", "4111111111111111,4222222222222222").
+		WithValue("clean", "nothing to see").
+		WithValue("number", 4111111111111111)
+	pattern := regexp.MustCompile(`\b(?:\d[ -]?){12,15}(\d{4})\b`)
+	p1 := NewPipeline().TransformContext(RedactContext(pattern, "****${1}"))
+	p1.Attach(c1).Collect(cuetest.GenerateEvent(cue.INFO, ctx, "info event", nil, 0))
+
+	if len(c1.Captured()) != 1 {
+		t.Fatalf("Expected to see a single event but saw %d instead", len(c1.Captured()))
+	}
+	expectation := cue.Fields{
+		"card":   "card ****1111 on file",
+		"cards":  "****1111,****2222",
+		"clean":  "nothing to see",
+		"number": 4111111111111111,
+	}
+	if !reflect.DeepEqual(c1.Captured()[0].Context.Fields(), expectation) {
+		t.Errorf("Expected to see context fields of %v but saw %v instead", expectation, c1.Captured()[0].Context.Fields())
+	}
+	if c1.Captured()[0].Context.Name() != "test" {
+		t.Errorf("Expected the context name to be retained, but saw %q instead", c1.Captured()[0].Context.Name())
+	}
+}
+
+func TestRedactKeys(t *testing.T) {
+	c1 := cuetest.NewCapturingCollector()
+	p1 := NewPipeline().TransformContext(RedactKeys("k1", "k4", "missing"))
+	p1.Attach(c1).Collect(cuetest.DebugEvent)
+
+	if len(c1.Captured()) != 1 {
+		t.Fatalf("Expected to see a single event but saw %d instead", len(c1.Captured()))
+	}
+	expectation := cue.Fields{
+		"k1": "[REDACTED]",
+		"k2": 2,
+		"k3": 3.5,
+		"k4": "[REDACTED]",
+	}
+	if !reflect.DeepEqual(c1.Captured()[0].Context.Fields(), expectation) {
+		t.Errorf("Expected to see context fields of %v but saw %v instead", expectation, c1.Captured()[0].Context.Fields())
+	}
+	if cuetest.DebugEvent.Context.Fields()["k1"] != "some value" {
+		t.Error("Expected the original event's context to be unaltered")
+	}
+}
+
 func TestPipelineEventTransformer(t *testing.T) {
 	c1 := cuetest.NewCapturingCollector()
 	p1 := NewPipeline().TransformEvent(func(event *cue.Event) *cue.Event {
@@ -121,6 +224,31 @@ func TestPipelineEventTransformer(t *testing.T) {
 	}
 }
 
+func TestPipelineEventMeta(t *testing.T) {
+	c := cuetest.NewCapturingCollector()
+	p := NewPipeline().TransformEvent(func(event *cue.Event) *cue.Event {
+		event.SetMeta("partition", "p7")
+		return event
+	})
+	p.Attach(c).Collect(cuetest.DebugEvent)
+
+	if len(c.Captured()) != 1 {
+		t.Fatalf("Expected to see a single event but saw %d instead", len(c.Captured()))
+	}
+	value, present := c.Captured()[0].GetMeta("partition")
+	if !present || value != "p7" {
+		t.Errorf("Expected downstream collector to see partition meta p7, but saw %v (present: %t) instead", value, present)
+	}
+	if _, present = cuetest.DebugEvent.GetMeta("partition"); present {
+		t.Error("Expected the original event to be unaltered, but it carries the meta value")
+	}
+
+	rendered := format.RenderString(format.JSONEvent, c.Captured()[0])
+	if strings.Contains(rendered, "partition") || strings.Contains(rendered, "p7") {
+		t.Errorf("Expected meta to be omitted from rendered output, but saw %s", rendered)
+	}
+}
+
 func TestMultiPipeline(t *testing.T) {
 	c1 := cuetest.NewCapturingCollector()
 	p1 := NewPipeline().FilterContext(func(key string, value interface{}) bool {
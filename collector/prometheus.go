@@ -0,0 +1,95 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build prometheus
+// +build prometheus
+
+package collector
+
+import (
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Prometheus represents configuration for collectors that count events in a
+// prometheus.CounterVec labeled by event level and context name.  This is
+// useful for dashboards and alerting on log volume without scraping log
+// files.
+//
+// The Prometheus collector is only built when the "prometheus" build tag is
+// set, so the Prometheus client library isn't a dependency of cue unless
+// the collector is used:
+//
+//	go build -tags prometheus
+type Prometheus struct {
+	Namespace string // Default: none
+	Subsystem string // Default: none
+	Name      string // Default: "log_events_total"
+}
+
+// New returns a new collector based on the Prometheus configuration.  Use
+// the Register method on the returned collector to register its metrics with
+// a prometheus.Registerer.
+func (p Prometheus) New() *PrometheusCollector {
+	if p.Name == "" {
+		p.Name = "log_events_total"
+	}
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: p.Namespace,
+		Subsystem: p.Subsystem,
+		Name:      p.Name,
+		Help:      "Number of log events collected, by level and context name.",
+	}, []string{"level", "context"})
+	return &PrometheusCollector{
+		config:  p,
+		counter: counter,
+	}
+}
+
+// PrometheusCollector is a cue.Collector that counts events by level and
+// context name.  It's created via Prometheus.New.
+type PrometheusCollector struct {
+	config  Prometheus
+	counter *prometheus.CounterVec
+}
+
+// String returns a string representation of the collector.
+func (p *PrometheusCollector) String() string {
+	return fmt.Sprintf("Prometheus(name=%s)", prometheus.BuildFQName(p.config.Namespace, p.config.Subsystem, p.config.Name))
+}
+
+// Collect increments the counter for the event's level and context name.
+// Collect never returns an error.
+func (p *PrometheusCollector) Collect(event *cue.Event) error {
+	p.counter.WithLabelValues(event.Level.String(), event.Context.Name()).Inc()
+	return nil
+}
+
+// Register registers the collector's metrics with registerer.  Pass
+// prometheus.DefaultRegisterer to expose them via the default registry.
+func (p *PrometheusCollector) Register(registerer prometheus.Registerer) error {
+	return registerer.Register(p.counter)
+}
+
+// Counter returns the underlying prometheus.CounterVec.
+func (p *PrometheusCollector) Counter() *prometheus.CounterVec {
+	return p.counter
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build prometheus
+// +build prometheus
+
+package collector
+
+import (
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/internal/cuetest"
+	"github.com/prometheus/client_golang/prometheus"
+	"testing"
+)
+
+func TestPrometheus(t *testing.T) {
+	c := Prometheus{Namespace: "test"}.New()
+	registry := prometheus.NewRegistry()
+	if err := c.Register(registry); err != nil {
+		t.Fatalf("Failed to register metrics: %s", err)
+	}
+
+	other := cuetest.GenerateEvent(cue.DEBUG, cue.NewContext("other"), "other event", nil, 0)
+	for _, event := range []*cue.Event{cuetest.DebugEvent, cuetest.DebugEvent, cuetest.ErrorEvent, other} {
+		if err := c.Collect(event); err != nil {
+			t.Errorf("Expected Collect to never fail, but saw %s", err)
+		}
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %s", err)
+	}
+	if len(families) != 1 || families[0].GetName() != "test_log_events_total" {
+		t.Fatalf("Expected a single test_log_events_total metric family, but saw %v instead", families)
+	}
+
+	counts := make(map[string]float64)
+	for _, metric := range families[0].GetMetric() {
+		labels := make(map[string]string)
+		for _, pair := range metric.GetLabel() {
+			labels[pair.GetName()] = pair.GetValue()
+		}
+		counts[labels["level"]+"/"+labels["context"]] = metric.GetCounter().GetValue()
+	}
+	expected := map[string]float64{
+		"DEBUG/test context": 2,
+		"ERROR/test context": 1,
+		"DEBUG/other":        1,
+	}
+	if fmt.Sprint(counts) != fmt.Sprint(expected) {
+		t.Errorf("Expected counts %v, but saw %v instead", expected, counts)
+	}
+}
+
+func TestPrometheusString(t *testing.T) {
+	c := Prometheus{}.New()
+
+	// Ensure nothing panics
+	_ = fmt.Sprint(c)
+}
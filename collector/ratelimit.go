@@ -0,0 +1,200 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package collector
+
+import (
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RateLimit represents configuration for rate-limited Collector instances.
+// Events are passed to Target subject to a token bucket that refills at Rate
+// events per second and holds at most Burst tokens.  Events that arrive while
+// the bucket is empty are dropped.
+//
+// A background goroutine sends a single WARN summary event reading
+// "RateLimit suppressed N events" to Target every ReportInterval, provided
+// events were dropped since the previous summary.  Hence suppression is
+// reported once per interval rather than once per dropped event.  Errors
+// returned by Target for summaries are logged, and the suppressed count is
+// retained for the next summary.  A final summary is sent on Close.  The
+// total number of dropped events is reported by the collector's String
+// method.
+//
+// The bucket is refilled according to event timestamps rather than the wall
+// clock.
+type RateLimit struct {
+	// Required
+	Target cue.Collector
+	Rate   float64 // Events per second
+
+	// Optional
+	Burst          int           // Default: 1
+	ReportInterval time.Duration // Interval between summary events.  Default: 1 minute
+}
+
+// New returns a new collector based on the RateLimit configuration.
+func (r RateLimit) New() cue.Collector {
+	if r.Target == nil {
+		log.Warn("RateLimit.New called to created a collector, but Target param is empty.  Returning nil collector.")
+		return nil
+	}
+	if r.Rate <= 0 {
+		log.Warn("RateLimit.New called to created a collector, but Rate param is not positive.  Returning nil collector.")
+		return nil
+	}
+	if r.Burst <= 0 {
+		r.Burst = 1
+	}
+	if r.ReportInterval <= 0 {
+		r.ReportInterval = time.Minute
+	}
+	c := &rateLimitCollector{
+		RateLimit: r,
+		tokens:    float64(r.Burst),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go c.reportPeriodically()
+	return c
+}
+
+type rateLimitCollector struct {
+	// Drops is accessed via atomic operations.  It's the first field to ensure
+	// 64-bit alignment.  See the sync/atomic docs for details.
+	drops uint64
+
+	RateLimit
+	mu         sync.Mutex
+	tokens     float64
+	last       time.Time
+	suppressed int // Drops that haven't been reported via a summary event
+	closed     bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func (r *rateLimitCollector) String() string {
+	return fmt.Sprintf("RateLimit(rate=%g, burst=%d, drops=%d, target=%s)", r.Rate, r.Burst, atomic.LoadUint64(&r.drops), r.Target)
+}
+
+func (r *rateLimitCollector) Collect(event *cue.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.refill(event.Time)
+	if r.tokens < 1 {
+		atomic.AddUint64(&r.drops, 1)
+		r.suppressed++
+		return nil
+	}
+	r.tokens--
+	return r.Target.Collect(event)
+}
+
+func (r *rateLimitCollector) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	r.mu.Unlock()
+
+	close(r.stop)
+	<-r.done
+
+	r.mu.Lock()
+	err := r.report()
+	r.mu.Unlock()
+
+	closer, ok := r.Target.(io.Closer)
+	if !ok {
+		return err
+	}
+	if closeErr := closer.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (r *rateLimitCollector) reportPeriodically() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.ReportInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			r.mu.Lock()
+			err := r.report()
+			r.mu.Unlock()
+			if err != nil {
+				log.Errorf(err, "Failed to send suppression summary for %s", r)
+			}
+		}
+	}
+}
+
+// report must be called with r.mu held.
+func (r *rateLimitCollector) report() error {
+	if r.suppressed == 0 {
+		return nil
+	}
+	err := r.Target.Collect(r.summaryEvent(time.Now()))
+	if err != nil {
+		return err
+	}
+	r.suppressed = 0
+	return nil
+}
+
+func (r *rateLimitCollector) refill(now time.Time) {
+	if r.last.IsZero() {
+		r.last = now
+		return
+	}
+	elapsed := now.Sub(r.last)
+	if elapsed <= 0 {
+		return
+	}
+	r.last = now
+	r.tokens += elapsed.Seconds() * r.Rate
+	if r.tokens > float64(r.Burst) {
+		r.tokens = float64(r.Burst)
+	}
+}
+
+func (r *rateLimitCollector) summaryEvent(now time.Time) *cue.Event {
+	return &cue.Event{
+		Time:    now,
+		Level:   cue.WARN,
+		Context: cue.NewContext("github.com/bobziuchkovski/cue/collector").WithValue("suppressed", r.suppressed),
+		Message: fmt.Sprintf("RateLimit suppressed %d events", r.suppressed),
+	}
+}
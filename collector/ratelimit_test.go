@@ -0,0 +1,171 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package collector
+
+import (
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/internal/cuetest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimitNilCollector(t *testing.T) {
+	c := RateLimit{Rate: 1}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when the target is missing, but got %s instead", c)
+	}
+
+	c = RateLimit{Target: cuetest.NewCapturingCollector()}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when the rate is missing, but got %s instead", c)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	capture := cuetest.NewCapturingCollector()
+	c := RateLimit{Target: capture, Rate: 1, Burst: 2, ReportInterval: time.Hour}.New()
+	defer cuetest.CloseCollector(c)
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		c.Collect(eventAt(start))
+	}
+	if len(capture.Captured()) != 2 {
+		t.Errorf("Expected the burst of 2 events to be collected but saw %d instead", len(capture.Captured()))
+	}
+	if !strings.Contains(fmt.Sprint(c), "drops=3") {
+		t.Errorf("Expected the collector to report 3 drops, but saw %s instead", c)
+	}
+
+	// A single token is available after 1 second
+	c.Collect(eventAt(start.Add(time.Second)))
+	c.Collect(eventAt(start.Add(time.Second)))
+	if len(capture.Captured()) != 3 {
+		t.Errorf("Expected 3 events to be collected but saw %d instead", len(capture.Captured()))
+	}
+	if !strings.Contains(fmt.Sprint(c), "drops=4") {
+		t.Errorf("Expected the collector to report 4 drops, but saw %s instead", c)
+	}
+}
+
+func TestRateLimitSummary(t *testing.T) {
+	capture := cuetest.NewCapturingCollector()
+	c := RateLimit{Target: capture, Rate: 1, ReportInterval: 10 * time.Millisecond}.New()
+	defer cuetest.CloseCollector(c)
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		c.Collect(eventAt(start))
+	}
+
+	// No further events are collected: the summary is sent when the interval ends
+	capture.WaitCaptured(2, time.Second)
+	captured := capture.Captured()
+	if len(captured) != 2 {
+		t.Fatalf("Expected an event and a summary to be collected, but saw %d events instead", len(captured))
+	}
+	summary := captured[1]
+	if summary.Level != cue.WARN || summary.Message != "RateLimit suppressed 3 events" {
+		t.Errorf("Expected a WARN summary event for 3 suppressed events, but saw %s %q instead", summary.Level, summary.Message)
+	}
+	if summary.Context.Fields()["suppressed"] != 3 {
+		t.Errorf("Expected the summary context to include suppressed=3, but saw %v instead", summary.Context.Fields())
+	}
+
+	// Intervals without drops don't send a summary
+	time.Sleep(50 * time.Millisecond)
+	if len(capture.Captured()) != 2 {
+		t.Errorf("Expected intervals without drops to be skipped, but saw %v instead", capture.Captured())
+	}
+}
+
+func TestRateLimitSummaryOnClose(t *testing.T) {
+	capture := cuetest.NewCapturingCollector()
+	c := RateLimit{Target: capture, Rate: 1, ReportInterval: time.Hour}.New()
+
+	start := time.Now()
+	c.Collect(eventAt(start))
+	c.Collect(eventAt(start))
+	cuetest.CloseCollector(c)
+
+	captured := capture.Captured()
+	if len(captured) != 2 || captured[1].Message != "RateLimit suppressed 1 events" {
+		t.Errorf("Expected a final summary on close, but saw %v instead", captured)
+	}
+}
+
+func TestRateLimitBurstCap(t *testing.T) {
+	capture := cuetest.NewCapturingCollector()
+	c := RateLimit{Target: capture, Rate: 10}.New()
+
+	// Tokens never accumulate beyond the default burst of 1
+	start := time.Now()
+	c.Collect(eventAt(start))
+	c.Collect(eventAt(start.Add(time.Minute)))
+	c.Collect(eventAt(start.Add(time.Minute)))
+	if len(capture.Captured()) != 2 {
+		t.Errorf("Expected 2 events to be collected but saw %d instead", len(capture.Captured()))
+	}
+}
+
+func TestRateLimitSummaryFailure(t *testing.T) {
+	failing := &failingCollector{}
+	c := RateLimit{Target: failing, Rate: 1, ReportInterval: time.Hour}.New()
+
+	start := time.Now()
+	c.Collect(eventAt(start))
+	c.Collect(eventAt(start))
+	failing.fail = true
+	err := c.(*rateLimitCollector).report()
+	if err == nil {
+		t.Error("Expected the summary failure to be returned, but it wasn't")
+	}
+
+	// The suppressed count is retained for the next summary
+	failing.fail = false
+	cuetest.CloseCollector(c)
+	if len(failing.collected) != 2 || failing.collected[1].Message != "RateLimit suppressed 1 events" {
+		t.Errorf("Expected the summary to be retried, but saw %d collected events instead", len(failing.collected))
+	}
+}
+
+func TestRateLimitString(t *testing.T) {
+	c := RateLimit{Target: cuetest.NewCapturingCollector(), Rate: 1}.New()
+	defer cuetest.CloseCollector(c)
+
+	// Ensure nothing panics
+	_ = fmt.Sprint(c)
+}
+
+type failingCollector struct {
+	fail      bool
+	collected []*cue.Event
+}
+
+func (f *failingCollector) Collect(event *cue.Event) error {
+	if f.fail {
+		return fmt.Errorf("collection failed")
+	}
+	f.collected = append(f.collected, event)
+	return nil
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package collector
+
+import (
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"io"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ReservoirSample represents configuration for Collector instances that keep
+// a uniform random sample of events over each interval.  Unlike Sample, which
+// passes the first of every N events and may therefore cluster kept events,
+// ReservoirSample uses reservoir sampling: every event collected during an
+// interval has an equal chance of being kept, so the kept events are spread
+// representatively across the interval.  At most Size events are kept per
+// interval.  ERROR and FATAL events are never sampled: they're always passed
+// to Target immediately.
+//
+// At the end of each Interval, the kept events are sent to Target by a
+// background goroutine, in the order they were collected.  If more than Size
+// events were collected, the kept events are passed as copies with their
+// SampleRate field set to the approximate ratio of collected to kept events.
+// Errors returned by Target at interval end are logged.  Kept events are also
+// sent on Close.  ReservoirSample collectors also implement a Flush() error
+// method, which sends the kept events immediately and starts a new interval.
+type ReservoirSample struct {
+	// Required
+	Target cue.Collector
+	Size   int // Maximum events kept per interval
+
+	// Optional
+	Interval time.Duration // Default: 1 minute
+}
+
+// New returns a new collector based on the ReservoirSample configuration.
+func (r ReservoirSample) New() cue.Collector {
+	if r.Target == nil {
+		log.Warn("ReservoirSample.New called to created a collector, but Target param is empty.  Returning nil collector.")
+		return nil
+	}
+	if r.Size <= 0 {
+		log.Warn("ReservoirSample.New called to created a collector, but Size param is not positive.  Returning nil collector.")
+		return nil
+	}
+	if r.Interval <= 0 {
+		r.Interval = time.Minute
+	}
+	c := &reservoirCollector{
+		ReservoirSample: r,
+		rand:            rand.New(rand.NewSource(time.Now().UnixNano())),
+		stop:            make(chan struct{}),
+		done:            make(chan struct{}),
+	}
+	go c.flushPeriodically()
+	return c
+}
+
+type reservoirSlot struct {
+	seen  int // Position of the event within the interval
+	event *cue.Event
+}
+
+type reservoirSlots []reservoirSlot
+
+func (s reservoirSlots) Len() int           { return len(s) }
+func (s reservoirSlots) Less(i, j int) bool { return s[i].seen < s[j].seen }
+func (s reservoirSlots) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+type reservoirCollector struct {
+	ReservoirSample
+	rand *rand.Rand
+
+	mu     sync.Mutex
+	seen   int
+	slots  reservoirSlots
+	closed bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func (r *reservoirCollector) String() string {
+	return fmt.Sprintf("ReservoirSample(size=%d, interval=%s, target=%s)", r.Size, r.Interval, r.Target)
+}
+
+func (r *reservoirCollector) Collect(event *cue.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if event.Level <= cue.ERROR {
+		return r.Target.Collect(event)
+	}
+
+	r.seen++
+	if len(r.slots) < r.Size {
+		r.slots = append(r.slots, reservoirSlot{seen: r.seen, event: event})
+		return nil
+	}
+	if i := r.rand.Intn(r.seen); i < r.Size {
+		r.slots[i] = reservoirSlot{seen: r.seen, event: event}
+	}
+	return nil
+}
+
+func (r *reservoirCollector) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	r.mu.Unlock()
+
+	close(r.stop)
+	<-r.done
+
+	r.mu.Lock()
+	err := r.flush()
+	r.mu.Unlock()
+
+	closer, ok := r.Target.(io.Closer)
+	if !ok {
+		return err
+	}
+	if closeErr := closer.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (r *reservoirCollector) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.flush()
+}
+
+func (r *reservoirCollector) flushPeriodically() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			r.mu.Lock()
+			err := r.flush()
+			r.mu.Unlock()
+			if err != nil {
+				log.Errorf(err, "Failed to send sampled events for %s", r)
+			}
+		}
+	}
+}
+
+// flush must be called with r.mu held.
+func (r *reservoirCollector) flush() error {
+	if len(r.slots) == 0 {
+		return nil
+	}
+
+	rate := 1
+	if r.seen > len(r.slots) {
+		rate = (r.seen + len(r.slots)/2) / len(r.slots)
+	}
+	slots := r.slots
+	r.slots = nil
+	r.seen = 0
+
+	sort.Sort(slots)
+	for _, slot := range slots {
+		err := r.Target.Collect(withSampleRate(slot.event, rate))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package collector
+
+import (
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/internal/cuetest"
+	"testing"
+	"time"
+)
+
+func TestReservoirSampleNilCollector(t *testing.T) {
+	c := ReservoirSample{Size: 10}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when the target is missing, but got %s instead", c)
+	}
+	c = ReservoirSample{Target: cuetest.NewCapturingCollector()}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when the size is missing, but got %s instead", c)
+	}
+}
+
+func TestReservoirSample(t *testing.T) {
+	capture := cuetest.NewCapturingCollector()
+	c := ReservoirSample{Target: capture, Size: 10, Interval: time.Hour}.New()
+
+	start := time.Now()
+	for i := 0; i < 1000; i++ {
+		c.Collect(levelEventAt(cue.INFO, fmt.Sprint(i), start.Add(time.Duration(i)*time.Millisecond)))
+	}
+	if len(capture.Captured()) != 0 {
+		t.Fatalf("Expected events to be held until the interval ends, but saw %d events instead", len(capture.Captured()))
+	}
+
+	err := c.(interface {
+		Flush() error
+	}).Flush()
+	if err != nil {
+		t.Fatalf("Encountered unexpected error flushing sampled events: %s", err)
+	}
+	c.Collect(levelEventAt(cue.INFO, "next", start.Add(time.Minute)))
+	captured := capture.Captured()
+	if len(captured) != 10 {
+		t.Fatalf("Expected exactly 10 sampled events, but saw %d instead", len(captured))
+	}
+
+	prev, early, late := -1, false, false
+	for _, event := range captured {
+		var index int
+		fmt.Sscan(event.Message, &index)
+		if index <= prev {
+			t.Errorf("Expected sampled events in collection order, but saw %d after %d", index, prev)
+		}
+		prev = index
+		early = early || index < 500
+		late = late || index >= 500
+		if event.SampleRate != 100 {
+			t.Errorf("Expected a sample rate of 100, but saw %d instead", event.SampleRate)
+		}
+	}
+	if !early || !late {
+		t.Errorf("Expected sampled events to be drawn from across the interval, but saw %v", captured)
+	}
+
+	cuetest.CloseCollector(c)
+	captured = capture.Captured()
+	if len(captured) != 11 || captured[10].Message != "next" || captured[10].SampleRate != 0 {
+		t.Errorf("Expected the remaining event to be flushed unsampled on close, but saw %v instead", captured)
+	}
+}
+
+func TestReservoirSampleInterval(t *testing.T) {
+	capture := cuetest.NewCapturingCollector()
+	c := ReservoirSample{Target: capture, Size: 10, Interval: 10 * time.Millisecond}.New()
+	defer cuetest.CloseCollector(c)
+
+	c.Collect(cuetest.DebugEvent)
+	c.Collect(cuetest.InfoEvent)
+
+	// No further events are collected: the sample is sent when the interval ends
+	capture.WaitCaptured(2, time.Second)
+	if len(capture.Captured()) != 2 {
+		t.Fatalf("Expected the sample to be sent at interval end, but saw %v instead", capture.Captured())
+	}
+	captured := capture.Captured()
+	if captured[0] != cuetest.DebugEvent || captured[1] != cuetest.InfoEvent {
+		t.Errorf("Expected the kept events to be sent unmodified, but saw %v instead", captured)
+	}
+
+	// Intervals without events don't send anything
+	time.Sleep(50 * time.Millisecond)
+	if len(capture.Captured()) != 2 {
+		t.Errorf("Expected empty intervals to be skipped, but saw %v instead", capture.Captured())
+	}
+}
+
+func TestReservoirSamplePassesErrors(t *testing.T) {
+	capture := cuetest.NewCapturingCollector()
+	c := ReservoirSample{Target: capture, Size: 1}.New()
+
+	c.Collect(cuetest.InfoEvent)
+	c.Collect(cuetest.InfoEvent)
+	c.Collect(cuetest.ErrorEvent)
+	c.Collect(cuetest.FatalEvent)
+
+	captured := capture.Captured()
+	if len(captured) != 2 || captured[0] != cuetest.ErrorEvent || captured[1] != cuetest.FatalEvent {
+		t.Errorf("Expected ERROR and FATAL events to be passed immediately, but saw %v instead", captured)
+	}
+}
+
+func TestReservoirSampleString(t *testing.T) {
+	c := ReservoirSample{Target: cuetest.NewCapturingCollector(), Size: 10}.New()
+
+	// Ensure nothing panics
+	_ = fmt.Sprint(c)
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package collector
+
+import (
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"sync"
+)
+
+// Ring represents configuration for Collector instances that retain the most
+// recent events in memory.  This is useful for in-process log inspection,
+// such as rendering recent events from a debug HTTP endpoint.
+type Ring struct {
+	Size int // Number of events to retain.  Default: 100
+}
+
+// New returns a new collector based on the Ring configuration.  Use the
+// Snapshot method on the returned collector to retrieve the retained events.
+func (r Ring) New() *RingCollector {
+	if r.Size <= 0 {
+		r.Size = 100
+	}
+	return &RingCollector{
+		events: make([]*cue.Event, r.Size),
+	}
+}
+
+// RingCollector is a cue.Collector that retains the most recent events in a
+// fixed-size circular buffer.  It's created via Ring.New.  RingCollector
+// methods are safe for concurrent use, and Collect never returns an error.
+type RingCollector struct {
+	mu     sync.Mutex
+	events []*cue.Event
+	next   int // Index of the slot for the next event
+	count  int // Number of retained events
+}
+
+// String returns a string representation of the collector.
+func (r *RingCollector) String() string {
+	return fmt.Sprintf("Ring(size=%d)", len(r.events))
+}
+
+// Collect retains the event, discarding the oldest retained event if the
+// buffer is full.
+func (r *RingCollector) Collect(event *cue.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events[r.next] = event
+	r.next = (r.next + 1) % len(r.events)
+	if r.count < len(r.events) {
+		r.count++
+	}
+	return nil
+}
+
+// Snapshot returns the retained events, oldest first.  The returned slice is
+// owned by the caller.  Events are shared with other collectors, so they must
+// not be modified.
+func (r *RingCollector) Snapshot() []*cue.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshot := make([]*cue.Event, 0, r.count)
+	start := (r.next - r.count + len(r.events)) % len(r.events)
+	for i := 0; i < r.count; i++ {
+		snapshot = append(snapshot, r.events[(start+i)%len(r.events)])
+	}
+	return snapshot
+}
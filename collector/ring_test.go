@@ -0,0 +1,91 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package collector
+
+import (
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/internal/cuetest"
+	"sync"
+	"testing"
+)
+
+func TestRing(t *testing.T) {
+	c := Ring{Size: 3}.New()
+	if len(c.Snapshot()) != 0 {
+		t.Errorf("Expected an empty snapshot, but saw %d events instead", len(c.Snapshot()))
+	}
+
+	var events []*cue.Event
+	for i := 0; i < 5; i++ {
+		event := cuetest.GenerateEvent(cue.INFO, cuetest.DebugEvent.Context, fmt.Sprint(i), nil, 0)
+		events = append(events, event)
+		if err := c.Collect(event); err != nil {
+			t.Errorf("Expected Collect to never fail, but saw %s", err)
+		}
+
+		snapshot := c.Snapshot()
+		expected := events
+		if len(expected) > 3 {
+			expected = expected[len(expected)-3:]
+		}
+		if fmt.Sprint(snapshot) != fmt.Sprint(expected) {
+			t.Errorf("Expected snapshot %v after %d events, but saw %v instead", expected, i+1, snapshot)
+		}
+	}
+}
+
+func TestRingDefaultSize(t *testing.T) {
+	c := Ring{}.New()
+	for i := 0; i < 150; i++ {
+		c.Collect(cuetest.DebugEvent)
+	}
+	if len(c.Snapshot()) != 100 {
+		t.Errorf("Expected the default size to retain 100 events, but saw %d instead", len(c.Snapshot()))
+	}
+}
+
+func TestRingConcurrentSnapshot(t *testing.T) {
+	c := Ring{Size: 10}.New()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			c.Collect(cuetest.DebugEvent)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		for _, event := range c.Snapshot() {
+			if event == nil {
+				t.Fatal("Expected snapshots to contain only collected events, but saw a nil event")
+			}
+		}
+	}
+	wg.Wait()
+}
+
+func TestRingString(t *testing.T) {
+	c := Ring{}.New()
+
+	// Ensure nothing panics
+	_ = fmt.Sprint(c)
+}
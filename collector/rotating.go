@@ -0,0 +1,176 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package collector
+
+import (
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/format"
+	"os"
+)
+
+// RotatingFile represents configuration for size-based rotating file
+// Collector instances.  Events are appended to the file at Path.  When a
+// write would grow the file beyond MaxBytes, the file is rotated: existing
+// backups are shifted (path.1 becomes path.2, and so on), the current file is
+// renamed to path.1, and a fresh file is opened at Path.  At most MaxBackups
+// backups are retained.
+//
+// RotatingFile is intended for environments that lack an external log
+// rotator.  If logrotate or similar is available, use the File collector
+// instead.
+type RotatingFile struct {
+	// Required
+	Path     string
+	MaxBytes int64
+
+	// Optional
+	MaxBackups int              // Default: 0 (the current file is discarded on rotation)
+	Perms      os.FileMode      // Default: 0600
+	Formatter  format.Formatter // Default: format.HumanReadable
+}
+
+// New returns a new collector based on the RotatingFile configuration.
+func (r RotatingFile) New() cue.Collector {
+	if r.Path == "" {
+		log.Warn("RotatingFile.New called to created a collector, but Path param is empty.  Returning nil collector.")
+		return nil
+	}
+	if r.MaxBytes <= 0 {
+		log.Warn("RotatingFile.New called to created a collector, but MaxBytes param is not positive.  Returning nil collector.")
+		return nil
+	}
+	if r.MaxBackups < 0 {
+		r.MaxBackups = 0
+	}
+	if r.Formatter == nil {
+		r.Formatter = format.HumanReadable
+	}
+	if r.Perms == 0 {
+		r.Perms = 0600
+	}
+
+	return &rotatingFileCollector{
+		RotatingFile: r,
+		fileCollector: &fileCollector{
+			File: File{
+				Path:      r.Path,
+				Flags:     os.O_CREATE | os.O_WRONLY | os.O_APPEND,
+				Perms:     r.Perms,
+				Formatter: r.Formatter,
+			},
+		},
+	}
+}
+
+type rotatingFileCollector struct {
+	RotatingFile
+	*fileCollector
+
+	size int64 // Current file size.  Only valid while the file is open.
+}
+
+func (r *rotatingFileCollector) String() string {
+	return fmt.Sprintf("RotatingFile(path=%s, maxBytes=%d, maxBackups=%d)", r.RotatingFile.Path, r.MaxBytes, r.MaxBackups)
+}
+
+func (r *rotatingFileCollector) Collect(event *cue.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	buf := format.GetBuffer()
+	defer format.ReleaseBuffer(buf)
+	r.render(buf, event)
+	bytes := buf.Bytes()
+
+	err := r.ensureSized()
+	if err != nil {
+		return err
+	}
+	if r.size > 0 && r.size+int64(len(bytes)) > r.MaxBytes {
+		err = r.rotate()
+		if err != nil {
+			return err
+		}
+	}
+
+	err = r.write(bytes)
+	if err != nil {
+		return err
+	}
+	r.size += int64(len(bytes))
+	return nil
+}
+
+// ensureSized opens the file if needed and records its current size.  The
+// caller must hold r.mu.
+func (r *rotatingFileCollector) ensureSized() error {
+	if r.file != nil {
+		return nil
+	}
+	err := r.ensureOpen()
+	if err != nil {
+		r.ensureClosed()
+		return err
+	}
+	stat, err := r.file.Stat()
+	if err != nil {
+		r.ensureClosed()
+		return err
+	}
+	r.size = stat.Size()
+	return nil
+}
+
+// rotate shifts existing backups, moves the current file to the first backup
+// slot, and opens a fresh file.  The caller must hold r.mu.
+func (r *rotatingFileCollector) rotate() error {
+	r.ensureClosed()
+
+	path := r.RotatingFile.Path
+	if r.MaxBackups == 0 {
+		err := os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return r.ensureSized()
+	}
+
+	err := os.Remove(backupPath(path, r.MaxBackups))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := r.MaxBackups - 1; i >= 1; i-- {
+		err = os.Rename(backupPath(path, i), backupPath(path, i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	err = os.Rename(path, backupPath(path, 1))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return r.ensureSized()
+}
+
+func backupPath(path string, index int) string {
+	return fmt.Sprintf("%s.%d", path, index)
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package collector
+
+import (
+	"github.com/bobziuchkovski/cue/internal/cuetest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestRotatingFileNilCollector(t *testing.T) {
+	c := RotatingFile{MaxBytes: 1024}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when the file path is missing, but got %s instead", c)
+	}
+
+	c = RotatingFile{Path: "/tmp/bogus"}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when max bytes is missing, but got %s instead", c)
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	tmp := tmpDir()
+	defer os.RemoveAll(tmp)
+
+	file := path.Join(tmp, "file")
+	c := RotatingFile{Path: file, MaxBytes: int64(2 * len(fileEventStr)), MaxBackups: 2}.New()
+	for i := 0; i < 7; i++ {
+		c.Collect(cuetest.DebugEvent)
+	}
+	cuetest.CloseCollector(c)
+
+	checkFileContents(t, file, fileEventStr)
+	checkFileContents(t, file+".1", strings.Repeat(fileEventStr, 2))
+	checkFileContents(t, file+".2", strings.Repeat(fileEventStr, 2))
+	checkMissing(t, file+".3")
+}
+
+func TestRotatingFileExistingContents(t *testing.T) {
+	tmp := tmpDir()
+	defer os.RemoveAll(tmp)
+
+	file := path.Join(tmp, "file")
+	opts := RotatingFile{Path: file, MaxBytes: int64(2 * len(fileEventStr)), MaxBackups: 1}
+
+	c1 := opts.New()
+	c1.Collect(cuetest.DebugEvent)
+	cuetest.CloseCollector(c1)
+
+	// The existing file size must be accounted for after reopening
+	c2 := opts.New()
+	c2.Collect(cuetest.DebugEvent)
+	c2.Collect(cuetest.DebugEvent)
+	cuetest.CloseCollector(c2)
+
+	checkFileContents(t, file, fileEventStr)
+	checkFileContents(t, file+".1", strings.Repeat(fileEventStr, 2))
+}
+
+func TestRotatingFileNoBackups(t *testing.T) {
+	tmp := tmpDir()
+	defer os.RemoveAll(tmp)
+
+	file := path.Join(tmp, "file")
+	c := RotatingFile{Path: file, MaxBytes: int64(len(fileEventStr))}.New()
+	for i := 0; i < 3; i++ {
+		c.Collect(cuetest.DebugEvent)
+	}
+	cuetest.CloseCollector(c)
+
+	checkFileContents(t, file, fileEventStr)
+	checkMissing(t, file+".1")
+}
+
+func TestRotatingFileOversizedEvent(t *testing.T) {
+	tmp := tmpDir()
+	defer os.RemoveAll(tmp)
+
+	// Events larger than MaxBytes are still written, one per file
+	file := path.Join(tmp, "file")
+	c := RotatingFile{Path: file, MaxBytes: 10, MaxBackups: 1}.New()
+	c.Collect(cuetest.DebugEvent)
+	c.Collect(cuetest.DebugEvent)
+	cuetest.CloseCollector(c)
+
+	checkFileContents(t, file, fileEventStr)
+	checkFileContents(t, file+".1", fileEventStr)
+}
+
+func TestRotatingFileString(t *testing.T) {
+	c := RotatingFile{Path: "/tmp/bogus", MaxBytes: 1024, MaxBackups: 3}.New()
+	expected := "RotatingFile(path=/tmp/bogus, maxBytes=1024, maxBackups=3)"
+	if c.(*rotatingFileCollector).String() != expected {
+		t.Errorf("Expected %q, but got %q instead", expected, c.(*rotatingFileCollector).String())
+	}
+}
+
+func checkMissing(t *testing.T, path string) {
+	_, err := os.Stat(path)
+	if !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be missing, but stat returned %v", path, err)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package collector
+
+import (
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"io"
+)
+
+// RouteByField represents configuration for routing Collector instances.  For
+// each event, the collector looks up the context value for Field and passes
+// the event to the matching target in Routes.  Values are matched using their
+// fmt.Sprint representation.  If the field is missing or no route matches, the
+// event is passed to Default.  If Default is nil, the event is dropped.
+//
+// Closing the collector closes all routes and the Default collector.
+type RouteByField struct {
+	// Required
+	Field  string
+	Routes map[string]cue.Collector
+
+	// Optional
+	Default cue.Collector
+}
+
+// New returns a new collector based on the RouteByField configuration.
+func (r RouteByField) New() cue.Collector {
+	if r.Field == "" {
+		log.Warn("RouteByField.New called to created a collector, but Field param is empty.  Returning nil collector.")
+		return nil
+	}
+
+	// Copy the routes so later changes to the input map don't race with
+	// event collection.
+	routes := make(map[string]cue.Collector)
+	for value, target := range r.Routes {
+		if target != nil {
+			routes[value] = target
+		}
+	}
+	r.Routes = routes
+	return &routeCollector{RouteByField: r}
+}
+
+type routeCollector struct {
+	RouteByField
+}
+
+func (r *routeCollector) String() string {
+	return fmt.Sprintf("RouteByField(field=%s, routes=%d, default=%v)", r.Field, len(r.Routes), r.Default)
+}
+
+func (r *routeCollector) Collect(event *cue.Event) error {
+	target := r.targetFor(event)
+	if target == nil {
+		return nil
+	}
+	return target.Collect(event)
+}
+
+func (r *routeCollector) Close() error {
+	var first error
+	closed := make(map[cue.Collector]bool)
+	for _, target := range r.targets() {
+		if closed[target] {
+			continue
+		}
+		closed[target] = true
+
+		closer, ok := target.(io.Closer)
+		if !ok {
+			continue
+		}
+		err := closer.Close()
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func (r *routeCollector) targetFor(event *cue.Event) cue.Collector {
+	var (
+		value interface{}
+		found bool
+	)
+
+	// Each visits the most recently added pairs first, so the first match
+	// is the current value for the field.
+	event.Context.Each(func(key string, v interface{}) {
+		if !found && key == r.Field {
+			value = v
+			found = true
+		}
+	})
+	if !found {
+		return r.Default
+	}
+
+	target, present := r.Routes[fmt.Sprint(value)]
+	if !present {
+		return r.Default
+	}
+	return target
+}
+
+func (r *routeCollector) targets() []cue.Collector {
+	var targets []cue.Collector
+	for _, target := range r.Routes {
+		targets = append(targets, target)
+	}
+	if r.Default != nil {
+		targets = append(targets, r.Default)
+	}
+	return targets
+}
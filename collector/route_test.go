@@ -0,0 +1,124 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package collector
+
+import (
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/internal/cuetest"
+	"testing"
+)
+
+func TestRouteByFieldNilCollector(t *testing.T) {
+	c := RouteByField{}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when the field is missing, but got %s instead", c)
+	}
+}
+
+func TestRouteByField(t *testing.T) {
+	tenant1 := cuetest.NewCapturingCollector()
+	tenant2 := cuetest.NewCapturingCollector()
+	fallback := cuetest.NewCapturingCollector()
+	c := RouteByField{
+		Field: "tenant",
+		Routes: map[string]cue.Collector{
+			"tenant1": tenant1,
+			"2":       tenant2,
+		},
+		Default: fallback,
+	}.New()
+
+	c.Collect(routedEvent("tenant", "tenant1"))
+	c.Collect(routedEvent("tenant", 2))
+	c.Collect(routedEvent("tenant", 2))
+	c.Collect(routedEvent("tenant", "bogus"))
+	c.Collect(routedEvent("other", "tenant1"))
+
+	checkRouted(t, "tenant1", tenant1, 1)
+	checkRouted(t, "tenant2", tenant2, 2)
+	checkRouted(t, "default", fallback, 2)
+}
+
+func TestRouteByFieldNoDefault(t *testing.T) {
+	tenant1 := cuetest.NewCapturingCollector()
+	c := RouteByField{
+		Field:  "tenant",
+		Routes: map[string]cue.Collector{"tenant1": tenant1},
+	}.New()
+
+	err := c.Collect(routedEvent("tenant", "bogus"))
+	if err != nil {
+		t.Errorf("Encountered unexpected error: %s", err)
+	}
+	checkRouted(t, "tenant1", tenant1, 0)
+}
+
+func TestRouteByFieldClose(t *testing.T) {
+	closer1 := &closingCollector{}
+	closer2 := &closingCollector{}
+	c := RouteByField{
+		Field: "tenant",
+		Routes: map[string]cue.Collector{
+			"tenant1": closer1,
+			"tenant2": closer1,
+		},
+		Default: closer2,
+	}.New()
+
+	cuetest.CloseCollector(c)
+	if closer1.closes != 1 {
+		t.Errorf("Expected shared route to be closed exactly once, but saw %d closes instead", closer1.closes)
+	}
+	if closer2.closes != 1 {
+		t.Errorf("Expected default route to be closed exactly once, but saw %d closes instead", closer2.closes)
+	}
+}
+
+func TestRouteByFieldString(t *testing.T) {
+	c := RouteByField{Field: "tenant"}.New()
+
+	// Ensure nothing panics
+	_ = fmt.Sprint(c)
+}
+
+func routedEvent(key string, value interface{}) *cue.Event {
+	return cuetest.GenerateEvent(cue.INFO, cue.NewContext("test").WithValue(key, value), "routed event", nil, 0)
+}
+
+func checkRouted(t *testing.T, name string, c *cuetest.CapturingCollector, count int) {
+	if len(c.Captured()) != count {
+		t.Errorf("Expected %d events routed to %s, but saw %d instead", count, name, len(c.Captured()))
+	}
+}
+
+type closingCollector struct {
+	closes int
+}
+
+func (c *closingCollector) Collect(event *cue.Event) error {
+	return nil
+}
+
+func (c *closingCollector) Close() error {
+	c.closes++
+	return nil
+}
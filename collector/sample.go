@@ -0,0 +1,342 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package collector
+
+import (
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Sample represents configuration for 1-in-N sampling Collector instances.
+// Events are counted per level, and the first of every N events for each
+// level is passed to Target.  The rest are dropped.  ERROR and FATAL events
+// are never sampled: they're always passed to Target.  Sampled events are
+// passed as copies with their SampleRate field set to N, so downstream systems
+// may correct for sampling when reconstructing volume.
+//
+// Retries of an event that Target failed to collect are passed again without
+// being counted, so worker retries don't shift the sampling sequence.
+//
+// If ReportInterval is set, a background goroutine sends a WARN event reading
+// "Sample dropped N events" to Target every ReportInterval, provided events
+// were dropped since the previous report.  Errors returned by Target for
+// reports are logged.  A final report is sent on Close.  The total number of
+// dropped events is reported by the collector's String method.
+type Sample struct {
+	// Required
+	Target cue.Collector
+	N      int // Pass 1 in N events per level
+
+	// Optional
+	ReportInterval time.Duration // Interval between drop reports.  If 0, drops aren't reported.
+}
+
+// New returns a new collector based on the Sample configuration.
+func (s Sample) New() cue.Collector {
+	if s.Target == nil {
+		log.Warn("Sample.New called to created a collector, but Target param is empty.  Returning nil collector.")
+		return nil
+	}
+	if s.N <= 0 {
+		log.Warn("Sample.New called to created a collector, but N param is not positive.  Returning nil collector.")
+		return nil
+	}
+	c := &sampleCollector{Sample: s}
+	if s.ReportInterval > 0 {
+		c.stop = make(chan struct{})
+		c.done = make(chan struct{})
+		go c.reportPeriodically()
+	}
+	return c
+}
+
+type sampleCollector struct {
+	// Counters are accessed via atomic operations.  They're the first fields
+	// to ensure 64-bit alignment.  See the sync/atomic docs for details.
+	counts [cue.TRACE + 1]uint64
+	drops  uint64
+
+	Sample
+	mu         sync.Mutex
+	failed     *cue.Event // Last event Target failed to collect
+	unreported uint64
+	closed     bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func (s *sampleCollector) String() string {
+	return fmt.Sprintf("Sample(n=%d, drops=%d, target=%s)", s.N, atomic.LoadUint64(&s.drops), s.Target)
+}
+
+func (s *sampleCollector) Collect(event *cue.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Workers retry failed events using the same pointer.  The retried event
+	// was already counted and passed, so it's passed again as-is.
+	if event != s.failed && !s.sample(event) {
+		atomic.AddUint64(&s.drops, 1)
+		s.unreported++
+		return nil
+	}
+	s.failed = nil
+
+	passed := event
+	if event.Level > cue.ERROR {
+		passed = withSampleRate(event, s.N)
+	}
+	err := s.Target.Collect(passed)
+	if err != nil {
+		s.failed = event
+	}
+	return err
+}
+
+func (s *sampleCollector) Close() error {
+	var err error
+	if s.stop != nil {
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			return nil
+		}
+		s.closed = true
+		s.mu.Unlock()
+
+		close(s.stop)
+		<-s.done
+
+		s.mu.Lock()
+		err = s.report()
+		s.mu.Unlock()
+	}
+
+	closer, ok := s.Target.(io.Closer)
+	if !ok {
+		return err
+	}
+	if closeErr := closer.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (s *sampleCollector) sample(event *cue.Event) bool {
+	if event.Level <= cue.ERROR || event.Level > cue.TRACE {
+		return true
+	}
+	count := atomic.AddUint64(&s.counts[event.Level], 1)
+	return (count-1)%uint64(s.N) == 0
+}
+
+func (s *sampleCollector) reportPeriodically() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.ReportInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			err := s.report()
+			s.mu.Unlock()
+			if err != nil {
+				log.Errorf(err, "Failed to send drop report for %s", s)
+			}
+		}
+	}
+}
+
+// report must be called with s.mu held.
+func (s *sampleCollector) report() error {
+	if s.unreported == 0 {
+		return nil
+	}
+	err := s.Target.Collect(s.reportEvent(time.Now()))
+	if err != nil {
+		return err
+	}
+	s.unreported = 0
+	return nil
+}
+
+func (s *sampleCollector) reportEvent(now time.Time) *cue.Event {
+	return &cue.Event{
+		Time:    now,
+		Level:   cue.WARN,
+		Context: cue.NewContext("github.com/bobziuchkovski/cue/collector").WithValue("dropped", s.unreported),
+		Message: fmt.Sprintf("Sample dropped %d events", s.unreported),
+	}
+}
+
+// SampleBurst represents configuration for burst-sampling Collector instances.
+// Events are grouped by level and message.  The first Initial events for each
+// group are passed to Target in full.  Thereafter, only 1 in every Rate events
+// for the group are passed to Target until Window elapses, at which point the
+// group's burst is reset.  This captures the variety of a newly-surfaced
+// error while still limiting the volume of repeated events.  Events passed
+// after the initial burst are copies with their SampleRate field set to Rate.
+// Retries of an event that Target failed to collect are passed again without
+// being counted.
+//
+// Window is measured using the events' Time fields.  Groups are discarded
+// once their window expires, which bounds memory use to the distinct groups
+// seen per window.
+type SampleBurst struct {
+	// Required
+	Target cue.Collector
+	Rate   int // Pass 1 in Rate events after the initial burst
+
+	// Optional
+	Initial int           // Events to pass in full per group and window.  Default: 0
+	Window  time.Duration // Burst reset interval.  Default: 1 minute
+}
+
+// New returns a new collector based on the SampleBurst configuration.
+func (s SampleBurst) New() cue.Collector {
+	if s.Target == nil {
+		log.Warn("SampleBurst.New called to created a collector, but Target param is empty.  Returning nil collector.")
+		return nil
+	}
+	if s.Rate <= 0 {
+		log.Warn("SampleBurst.New called to created a collector, but Rate param is not positive.  Returning nil collector.")
+		return nil
+	}
+	if s.Window <= 0 {
+		s.Window = time.Minute
+	}
+	return &sampleBurstCollector{
+		SampleBurst: s,
+		groups:      make(map[burstKey]*burstGroup),
+	}
+}
+
+type burstKey struct {
+	level   cue.Level
+	message string
+}
+
+type burstGroup struct {
+	start time.Time
+	count int
+}
+
+type sampleBurstCollector struct {
+	SampleBurst
+	groups    map[burstKey]*burstGroup
+	lastSweep time.Time
+	failed    *cue.Event // Last event Target failed to collect
+	resend    *cue.Event // Copy of failed that was passed to Target
+}
+
+func (s *sampleBurstCollector) String() string {
+	return fmt.Sprintf("SampleBurst(initial=%d, rate=%d, window=%s, target=%s)", s.Initial, s.Rate, s.Window, s.Target)
+}
+
+func (s *sampleBurstCollector) Collect(event *cue.Event) error {
+	// Workers retry failed events using the same pointer.  The retried event
+	// was already counted, so the copy passed previously is passed again.
+	passed := s.resend
+	if event != s.failed {
+		sampled, inBurst := s.sample(event)
+		if !sampled {
+			return nil
+		}
+		passed = event
+		if !inBurst {
+			passed = withSampleRate(event, s.Rate)
+		}
+	}
+
+	s.failed, s.resend = nil, nil
+	err := s.Target.Collect(passed)
+	if err != nil {
+		s.failed, s.resend = event, passed
+	}
+	return err
+}
+
+func (s *sampleBurstCollector) Close() error {
+	closer, ok := s.Target.(io.Closer)
+	if !ok {
+		return nil
+	}
+	return closer.Close()
+}
+
+// sample returns whether the event should be passed to Target, and whether
+// it's part of the group's initial burst.
+func (s *sampleBurstCollector) sample(event *cue.Event) (sampled bool, inBurst bool) {
+	key := burstKey{level: event.Level, message: event.Message}
+	group, present := s.groups[key]
+	if !present || s.expired(group, event.Time) {
+		s.sweep(event.Time)
+		group = &burstGroup{start: event.Time}
+		s.groups[key] = group
+	}
+
+	group.count++
+	if group.count <= s.Initial {
+		return true, true
+	}
+	return (group.count-s.Initial)%s.Rate == 0, false
+}
+
+func (s *sampleBurstCollector) expired(group *burstGroup, now time.Time) bool {
+	return now.Sub(group.start) >= s.Window
+}
+
+// sweep discards expired groups at most once per window to prevent unbounded
+// growth when many distinct messages are logged.
+func (s *sampleBurstCollector) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.Window {
+		return
+	}
+	for key, group := range s.groups {
+		if s.expired(group, now) {
+			delete(s.groups, key)
+		}
+	}
+	s.lastSweep = now
+}
+
+// withSampleRate returns a copy of event with its SampleRate field set to
+// reflect the given rate.  Rates from nested samplers are multiplied.
+func withSampleRate(event *cue.Event, rate int) *cue.Event {
+	if rate <= 1 {
+		return event
+	}
+	stamped := event.Clone()
+	if stamped.SampleRate > 0 {
+		stamped.SampleRate *= rate
+	} else {
+		stamped.SampleRate = rate
+	}
+	return stamped
+}
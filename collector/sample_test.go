@@ -0,0 +1,297 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package collector
+
+import (
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/internal/cuetest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSampleBurstNilCollector(t *testing.T) {
+	c := SampleBurst{Initial: 1, Rate: 1}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when the target is missing, but got %s instead", c)
+	}
+	c = SampleBurst{Initial: 1, Target: cuetest.NewCapturingCollector()}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when the rate is missing, but got %s instead", c)
+	}
+}
+
+func TestSampleBurst(t *testing.T) {
+	capture := cuetest.NewCapturingCollector()
+	c := SampleBurst{Initial: 3, Rate: 4, Target: capture}.New()
+
+	for i := 0; i < 15; i++ {
+		c.Collect(cuetest.DebugEvent)
+	}
+
+	// The first 3 events are kept, followed by events 7, 11, and 15.
+	if len(capture.Captured()) != 6 {
+		t.Errorf("Expected 6 events to be collected but saw %d instead", len(capture.Captured()))
+	}
+}
+
+func TestSampleBurstGroups(t *testing.T) {
+	capture := cuetest.NewCapturingCollector()
+	c := SampleBurst{Initial: 2, Rate: 100, Target: capture}.New()
+
+	for i := 0; i < 5; i++ {
+		c.Collect(cuetest.DebugEvent)
+		c.Collect(cuetest.ErrorEvent)
+	}
+
+	if len(capture.Captured()) != 4 {
+		t.Errorf("Expected 4 events to be collected but saw %d instead", len(capture.Captured()))
+	}
+}
+
+func TestSampleBurstWindow(t *testing.T) {
+	capture := cuetest.NewCapturingCollector()
+	c := SampleBurst{Initial: 2, Rate: 100, Window: time.Minute, Target: capture}.New()
+
+	start := cuetest.DebugEvent.Time
+	for i := 0; i < 4; i++ {
+		c.Collect(eventAt(start.Add(time.Duration(i) * time.Second)))
+	}
+	if len(capture.Captured()) != 2 {
+		t.Errorf("Expected 2 events to be collected but saw %d instead", len(capture.Captured()))
+	}
+
+	for i := 0; i < 4; i++ {
+		c.Collect(eventAt(start.Add(time.Minute + time.Duration(i)*time.Second)))
+	}
+	if len(capture.Captured()) != 4 {
+		t.Errorf("Expected 4 events to be collected after the window elapsed but saw %d instead", len(capture.Captured()))
+	}
+}
+
+func TestSampleBurstSweep(t *testing.T) {
+	c := SampleBurst{Rate: 10, Target: cuetest.NewCapturingCollector()}.New()
+
+	// Groups expire using the default window, so distinct messages don't
+	// accumulate indefinitely.
+	start := cuetest.DebugEvent.Time
+	for i := 0; i < 100; i++ {
+		event := eventAt(start)
+		event.Message = fmt.Sprint(i)
+		c.Collect(event)
+	}
+	c.Collect(eventAt(start.Add(2 * time.Minute)))
+	if groups := len(c.(*sampleBurstCollector).groups); groups != 1 {
+		t.Errorf("Expected expired groups to be discarded, but saw %d groups instead", groups)
+	}
+}
+
+func TestSampleBurstRetry(t *testing.T) {
+	flaky := &flakyCollector{failures: 1}
+	c := SampleBurst{Rate: 2, Target: flaky}.New()
+
+	start := time.Now()
+	first, second, third, fourth := eventAt(start), eventAt(start), eventAt(start), eventAt(start)
+	c.Collect(first)
+	if c.Collect(second) == nil {
+		t.Fatal("Expected the second collection attempt to fail")
+	}
+	c.Collect(second)
+	c.Collect(third)
+	c.Collect(fourth)
+
+	// Events 2 and 4 are passed, regardless of the retry
+	collected := flaky.Collected()
+	if len(collected) != 2 || collected[0].SampleRate != 2 || collected[1].SampleRate != 2 {
+		t.Errorf("Expected the retried event to be passed with its sample rate, but saw %v instead", collected)
+	}
+}
+
+func TestSampleBurstString(t *testing.T) {
+	c := SampleBurst{Initial: 1, Rate: 1, Target: cuetest.NewCapturingCollector()}.New()
+
+	// Ensure nothing panics
+	_ = fmt.Sprint(c)
+}
+
+func eventAt(t time.Time) *cue.Event {
+	event := cuetest.GenerateEvent(cue.DEBUG, cuetest.DebugEvent.Context, cuetest.DebugEvent.Message, nil, 0)
+	event.Time = t
+	return event
+}
+
+func TestSampleNilCollector(t *testing.T) {
+	c := Sample{N: 10}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when the target is missing, but got %s instead", c)
+	}
+
+	c = Sample{Target: cuetest.NewCapturingCollector()}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when N is missing, but got %s instead", c)
+	}
+}
+
+func TestSample(t *testing.T) {
+	capture := cuetest.NewCapturingCollector()
+	c := Sample{Target: capture, N: 10}.New()
+
+	for i := 0; i < 25; i++ {
+		c.Collect(cuetest.DebugEvent)
+		c.Collect(cuetest.InfoEvent)
+	}
+	// Levels are counted independently: events 1, 11, and 21 of each level pass
+	if len(capture.Captured()) != 6 {
+		t.Errorf("Expected 6 events to be collected but saw %d instead", len(capture.Captured()))
+	}
+	if !strings.Contains(fmt.Sprint(c), "drops=44") {
+		t.Errorf("Expected the collector to report 44 drops, but saw %s instead", c)
+	}
+}
+
+func TestSampleRateStamped(t *testing.T) {
+	capture := cuetest.NewCapturingCollector()
+	c := Sample{Target: capture, N: 10}.New()
+
+	c.Collect(cuetest.DebugEvent)
+	c.Collect(cuetest.ErrorEvent)
+	captured := capture.Captured()
+	if captured[0].SampleRate != 10 {
+		t.Errorf("Expected sampled events to carry sample_rate=10, but saw %d instead", captured[0].SampleRate)
+	}
+	if captured[0] == cuetest.DebugEvent || cuetest.DebugEvent.SampleRate != 0 {
+		t.Error("Expected the sampled event to be stamped on a copy, but the original was altered")
+	}
+	if captured[1].SampleRate != 0 {
+		t.Errorf("Expected unsampled ERROR events to carry no sample rate, but saw %d instead", captured[1].SampleRate)
+	}
+
+	// Nested samplers multiply their rates
+	capture = cuetest.NewCapturingCollector()
+	c = Sample{Target: Sample{Target: capture, N: 2}.New(), N: 10}.New()
+	c.Collect(cuetest.DebugEvent)
+	if capture.Captured()[0].SampleRate != 20 {
+		t.Errorf("Expected nested samplers to stamp sample_rate=20, but saw %d instead", capture.Captured()[0].SampleRate)
+	}
+}
+
+func TestSampleBurstRateStamped(t *testing.T) {
+	capture := cuetest.NewCapturingCollector()
+	c := SampleBurst{Initial: 1, Rate: 4, Target: capture}.New()
+
+	for i := 0; i < 5; i++ {
+		c.Collect(cuetest.DebugEvent)
+	}
+	captured := capture.Captured()
+	if len(captured) != 2 {
+		t.Fatalf("Expected 2 events to be collected but saw %d instead", len(captured))
+	}
+	if captured[0].SampleRate != 0 {
+		t.Errorf("Expected burst events to carry no sample rate, but saw %d instead", captured[0].SampleRate)
+	}
+	if captured[1].SampleRate != 4 {
+		t.Errorf("Expected post-burst events to carry sample_rate=4, but saw %d instead", captured[1].SampleRate)
+	}
+}
+
+func TestSampleErrorsPassThrough(t *testing.T) {
+	capture := cuetest.NewCapturingCollector()
+	c := Sample{Target: capture, N: 100}.New()
+
+	for i := 0; i < 5; i++ {
+		c.Collect(cuetest.ErrorEvent)
+		c.Collect(cuetest.FatalEvent)
+	}
+	if len(capture.Captured()) != 10 {
+		t.Errorf("Expected all 10 ERROR and FATAL events to be collected but saw %d instead", len(capture.Captured()))
+	}
+}
+
+func TestSampleReportInterval(t *testing.T) {
+	capture := cuetest.NewCapturingCollector()
+	c := Sample{Target: capture, N: 2, ReportInterval: 10 * time.Millisecond}.New()
+	defer cuetest.CloseCollector(c)
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		c.Collect(eventAt(start))
+	}
+
+	// No further events are collected: the report is sent when the interval ends
+	capture.WaitCaptured(3, time.Second)
+	captured := capture.Captured()
+	if len(captured) != 3 {
+		t.Fatalf("Expected 2 events and a report to be collected, but saw %d events instead", len(captured))
+	}
+	if captured[2].Level != cue.WARN || captured[2].Message != "Sample dropped 2 events" {
+		t.Errorf("Expected a WARN report for 2 dropped events, but saw %s %q instead", captured[2].Level, captured[2].Message)
+	}
+
+	// Intervals without drops don't send a report
+	time.Sleep(50 * time.Millisecond)
+	if len(capture.Captured()) != 3 {
+		t.Errorf("Expected intervals without drops to be skipped, but saw %v instead", capture.Captured())
+	}
+}
+
+func TestSampleReportOnClose(t *testing.T) {
+	capture := cuetest.NewCapturingCollector()
+	c := Sample{Target: capture, N: 2, ReportInterval: time.Hour}.New()
+
+	c.Collect(cuetest.DebugEvent)
+	c.Collect(cuetest.DebugEvent)
+	cuetest.CloseCollector(c)
+
+	captured := capture.Captured()
+	if len(captured) != 2 || captured[1].Message != "Sample dropped 1 events" {
+		t.Errorf("Expected a final drop report on close, but saw %v instead", captured)
+	}
+}
+
+func TestSampleRetry(t *testing.T) {
+	flaky := &flakyCollector{failures: 1}
+	c := Sample{Target: flaky, N: 2}.New()
+
+	start := time.Now()
+	first, second, third := eventAt(start), eventAt(start), eventAt(start)
+	if c.Collect(first) == nil {
+		t.Fatal("Expected the first collection attempt to fail")
+	}
+	c.Collect(first)
+	c.Collect(second)
+	c.Collect(third)
+
+	collected := flaky.Collected()
+	if len(collected) != 2 || collected[0].Message != first.Message || collected[0].SampleRate != 2 {
+		t.Fatalf("Expected the retried event to be passed once it succeeds, but saw %v instead", collected)
+	}
+	if !strings.Contains(fmt.Sprint(c), "drops=1") {
+		t.Errorf("Expected retries to leave the sampling sequence intact, but saw %s instead", c)
+	}
+}
+
+func TestSampleString(t *testing.T) {
+	c := Sample{Target: cuetest.NewCapturingCollector(), N: 10}.New()
+
+	// Ensure nothing panics
+	_ = fmt.Sprint(c)
+}
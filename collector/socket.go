@@ -21,11 +21,20 @@
 package collector
 
 import (
+	"compress/gzip"
 	"crypto/tls"
 	"fmt"
 	"github.com/bobziuchkovski/cue"
 	"github.com/bobziuchkovski/cue/format"
 	"net"
+	"time"
+)
+
+const (
+	// Reconnect delays double with each consecutive failed connection attempt,
+	// starting from minReconnectDelay, up to maxReconnectDelay.
+	minReconnectDelay = 100 * time.Millisecond
+	maxReconnectDelay = time.Minute
 )
 
 // Socket represents configuration for socket-based Collector instances. The
@@ -33,6 +42,20 @@ import (
 // and (optionally) TLS params.  The socket connection is opened via net.Dial,
 // or by tls.Dial if TLS config is specified.  See the net and crypto/tls
 // packages for details on supported Network and Address specifications.
+//
+// If Compress is set, the outbound stream is gzip-compressed.  The stream is
+// flushed after each event so the receiver sees timely data.  A new gzip
+// stream is started each time the connection is re-opened.  The receiving
+// end must gunzip the stream.
+//
+// If a write fails, the connection is closed and re-opened on the next
+// Collect call.  If re-opening fails repeatedly, further attempts are delayed
+// with exponential backoff, up to a minute between attempts, so that the
+// collector doesn't tight-loop reconnecting while the server is down.
+// Collect returns an error without dialing while waiting to reconnect.  For
+// TCP connections, KeepAlive specifies the keep-alive period, which allows
+// connections that silently die, such as behind a load balancer, to be
+// detected proactively.  See net.Dialer for details.
 type Socket struct {
 	// Required
 	Network string
@@ -41,6 +64,8 @@ type Socket struct {
 	// Optional
 	TLS       *tls.Config
 	Formatter format.Formatter // Default: format.HumanReadable
+	Compress  bool             // Default: false
+	KeepAlive time.Duration    // Default: 15 seconds.  Negative disables keep-alives.
 }
 
 // New returns a new collector based on the Socket configuration.
@@ -62,11 +87,15 @@ func (s Socket) New() cue.Collector {
 type socketCollector struct {
 	Socket
 	conn      net.Conn
+	gzip      *gzip.Writer // Only set if Compress is set
 	connected bool
+
+	failures    int       // Consecutive failed connection attempts
+	nextAttempt time.Time // Earliest time to retry connecting after failures
 }
 
 func (s *socketCollector) String() string {
-	return fmt.Sprintf("Socket(network=%s, address=%s, tls=%t)", s.Network, s.Address, s.TLS != nil)
+	return fmt.Sprintf("Socket(network=%s, address=%s, tls=%t, compress=%t)", s.Network, s.Address, s.TLS != nil, s.Compress)
 }
 
 func (s *socketCollector) Collect(event *cue.Event) error {
@@ -81,34 +110,77 @@ func (s *socketCollector) Collect(event *cue.Event) error {
 	defer format.ReleaseBuffer(buf)
 	s.Formatter(buf, event)
 
-	_, err := s.conn.Write(buf.Bytes())
+	err := s.write(buf.Bytes())
 	if err != nil {
 		s.conn.Close()
 		s.conn = nil
+		s.gzip = nil
 		s.connected = false
 	}
 	return err
 }
 
 func (s *socketCollector) Close() error {
-	if s.conn != nil {
-		return s.conn.Close()
+	if s.conn == nil {
+		return nil
 	}
-	return nil
+	if s.gzip != nil {
+		s.gzip.Close()
+	}
+	return s.conn.Close()
+}
+
+func (s *socketCollector) write(bytes []byte) error {
+	if s.gzip == nil {
+		_, err := s.conn.Write(bytes)
+		return err
+	}
+	_, err := s.gzip.Write(bytes)
+	if err != nil {
+		return err
+	}
+	return s.gzip.Flush()
 }
 
 func (s *socketCollector) reopen() error {
+	if time.Now().Before(s.nextAttempt) {
+		return fmt.Errorf("cue/collector: waiting until %s to reconnect after %d failed attempts", s.nextAttempt.Format(time.StampMilli), s.failures)
+	}
+
 	var err error
+	dialer := &net.Dialer{KeepAlive: s.KeepAlive}
 	if s.TLS != nil {
-		s.conn, err = tls.Dial(s.Network, s.Address, s.TLS)
-		if err == nil {
-			s.connected = true
-		}
+		s.conn, err = tls.DialWithDialer(dialer, s.Network, s.Address, s.TLS)
+	} else {
+		s.conn, err = dialer.Dial(s.Network, s.Address)
+	}
+	if err != nil {
+		s.failures++
+		s.nextAttempt = time.Now().Add(reconnectDelay(s.failures))
 		return err
 	}
-	s.conn, err = net.Dial(s.Network, s.Address)
-	if err == nil {
-		s.connected = true
+	s.failures = 0
+	s.nextAttempt = time.Time{}
+	if s.Compress {
+		s.gzip = gzip.NewWriter(s.conn)
 	}
-	return err
+	s.connected = true
+	return nil
+}
+
+// reconnectDelay returns the delay before the next connection attempt after
+// the given number of consecutive failures.  The first failure is retried
+// immediately, since the worker retries failed events right away.
+func reconnectDelay(failures int) time.Duration {
+	if failures <= 1 {
+		return 0
+	}
+	delay := minReconnectDelay
+	for i := 2; i < failures && delay < maxReconnectDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxReconnectDelay {
+		delay = maxReconnectDelay
+	}
+	return delay
 }
@@ -21,10 +21,15 @@
 package collector
 
 import (
+	"bytes"
+	"compress/gzip"
 	"crypto/tls"
 	"fmt"
 	"github.com/bobziuchkovski/cue/internal/cuetest"
+	"io/ioutil"
+	"strings"
 	"testing"
+	"time"
 )
 
 const socketEventStr = "Jan  2 15:04:00 DEBUG file3.go:3 debug event k1=\"some value\" k2=2 k3=3.5 k4=true"
@@ -96,6 +101,101 @@ func TestSocketReopenOnError(t *testing.T) {
 	recorder.CheckStringContents(t, socketEventStr)
 }
 
+func TestSocketReconnectBackoff(t *testing.T) {
+	recorder := cuetest.NewTCPRecorder()
+	defer recorder.Close()
+
+	c := Socket{
+		Network: "tcp",
+		Address: recorder.Address(),
+	}.New()
+
+	for i := 0; i < 2; i++ {
+		err := c.Collect(cuetest.DebugEvent)
+		if err == nil || strings.Contains(err.Error(), "waiting") {
+			t.Fatalf("Expected to see a connection error on attempt %d but saw %v instead", i+1, err)
+		}
+	}
+	err := c.Collect(cuetest.DebugEvent)
+	if err == nil || !strings.Contains(err.Error(), "waiting") {
+		t.Errorf("Expected to see a reconnect backoff error after repeated failures, but saw %v instead", err)
+	}
+
+	recorder.Start()
+	socket := c.(*socketCollector)
+	socket.nextAttempt = time.Now()
+	err = c.Collect(cuetest.DebugEvent)
+	if err != nil {
+		t.Errorf("Encountered unexpected collector error: %s", err)
+	}
+	if socket.failures != 0 {
+		t.Errorf("Expected the failure count to reset after reconnecting, but saw %d instead", socket.failures)
+	}
+
+	cuetest.CloseCollector(c)
+	recorder.CheckStringContents(t, socketEventStr)
+}
+
+func TestSocketReconnectDelay(t *testing.T) {
+	expectations := map[int]time.Duration{
+		0:    0,
+		1:    0,
+		2:    100 * time.Millisecond,
+		3:    200 * time.Millisecond,
+		4:    400 * time.Millisecond,
+		12:   time.Minute,
+		1000: time.Minute,
+	}
+	for failures, expected := range expectations {
+		if delay := reconnectDelay(failures); delay != expected {
+			t.Errorf("Expected a reconnect delay of %s after %d failures, but saw %s instead", expected, failures, delay)
+		}
+	}
+}
+
+func TestSocketCompress(t *testing.T) {
+	recorder := cuetest.NewTCPRecorder()
+	recorder.Start()
+	defer recorder.Close()
+
+	c := Socket{
+		Network:  "tcp",
+		Address:  recorder.Address(),
+		Compress: true,
+	}.New()
+
+	c.Collect(cuetest.DebugEvent)
+	c.Collect(cuetest.DebugEvent)
+	cuetest.CloseCollector(c)
+	checkGzipContents(t, recorder.Contents(), socketEventStr+socketEventStr)
+}
+
+func TestSocketCompressReopenOnError(t *testing.T) {
+	recorder := cuetest.NewTCPRecorder()
+	defer recorder.Close()
+
+	c := Socket{
+		Network:  "tcp",
+		Address:  recorder.Address(),
+		Compress: true,
+	}.New()
+
+	err := c.Collect(cuetest.DebugEvent)
+	if err == nil {
+		t.Error("Expected to see a collector error but didn't")
+	}
+
+	// The reopened connection must carry a complete gzip stream of its own
+	recorder.Start()
+	err = c.Collect(cuetest.DebugEvent)
+	if err != nil {
+		t.Errorf("Encountered unexpected collector error: %s", err)
+	}
+
+	cuetest.CloseCollector(c)
+	checkGzipContents(t, recorder.Contents(), socketEventStr)
+}
+
 func TestSocketString(t *testing.T) {
 	recorder := cuetest.NewTCPRecorder()
 	defer recorder.Close()
@@ -108,3 +208,17 @@ func TestSocketString(t *testing.T) {
 	// Ensure nothing panics
 	_ = fmt.Sprint(c)
 }
+
+func checkGzipContents(t *testing.T, compressed []byte, expected string) {
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatalf("Encountered unexpected error opening gzip stream: %s", err)
+	}
+	decompressed, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("Encountered unexpected error reading gzip stream: %s", err)
+	}
+	if string(decompressed) != expected {
+		t.Errorf("Expected decompressed content of %q but got %q instead", expected, decompressed)
+	}
+}
@@ -136,6 +136,11 @@ const (
 // The MessageFormatter must ensure new line characters in event messages are
 // properly escaped.  The default formatter, format.HumanMessage, does this
 // automatically.
+//
+// Messages are newline-terminated and limited to 1024 bytes, including the
+// newline, per RFC 3164.  When Network is "udp", "udp4", or "udp6", each event
+// is written as a single datagram, as expected by traditional syslog servers
+// listening on UDP port 514.  Octet counting isn't used for any network type.
 type Syslog struct {
 	App      string
 	Facility Facility
@@ -203,12 +208,14 @@ func syslogFormatter(facility Facility, app string, local bool, msgFormatter for
 		msgFormatter = format.HumanMessage
 	}
 
-	formatter := format.Formatf("%v%v %v %v: %v\n", priFormatter(facility), format.Time(time.RFC3339), format.Hostname, procIDFormatter(app), msgFormatter)
+	formatter := format.Formatf("%v%v %v %v: %v", priFormatter(facility), format.Time(time.RFC3339), format.Hostname, procIDFormatter(app), msgFormatter)
 	if local {
-		formatter = format.Formatf("%v%v %v: %v\n", priFormatter(facility), format.Time(time.Stamp), procIDFormatter(app), msgFormatter)
+		formatter = format.Formatf("%v%v %v: %v", priFormatter(facility), format.Time(time.Stamp), procIDFormatter(app), msgFormatter)
 	}
-	// RFC 3164 explicitly limits the message length to 1024 bytes
-	return format.Truncate(formatter, 1024)
+	// RFC 3164 explicitly limits the message length to 1024 bytes.  We
+	// truncate prior to appending the trailing newline so that stream-based
+	// receivers can still locate message boundaries for truncated messages.
+	return format.Formatf("%v\n", format.Truncate(formatter, 1023))
 }
 
 // StructuredSyslog represents configuration for RFC 5424 (structured) syslog
@@ -342,7 +349,7 @@ func priorityFor(facility Facility, level cue.Level) priority {
 
 func severityFor(level cue.Level) severity {
 	switch level {
-	case cue.DEBUG:
+	case cue.TRACE, cue.DEBUG:
 		return sDEBUG
 	case cue.INFO:
 		return sINFO
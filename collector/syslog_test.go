@@ -28,6 +28,7 @@ import (
 	"github.com/bobziuchkovski/cue/internal/cuetest"
 	"os"
 	"regexp"
+	"strings"
 	"testing"
 )
 
@@ -111,6 +112,61 @@ func TestSyslogTLS(t *testing.T) {
 	checkSyslogContents(t, "testapp", LOCAL4, string(recorder.Contents()), cuetest.DebugEvent)
 }
 
+func TestSyslogUDP(t *testing.T) {
+	recorder := cuetest.NewUDPRecorder()
+	recorder.Start()
+	defer recorder.Close()
+
+	c := Syslog{
+		App:      "testapp",
+		Facility: LOCAL4,
+		Network:  "udp",
+		Address:  recorder.Address(),
+	}.New()
+
+	c.Collect(cuetest.DebugEvent)
+	c.Collect(cuetest.InfoEvent)
+	cuetest.CloseCollector(c)
+	recorder.Close()
+
+	datagrams := recorder.Datagrams()
+	if len(datagrams) != 2 {
+		t.Fatalf("Expected 2 datagrams but received %d instead", len(datagrams))
+	}
+	checkSyslogContents(t, "testapp", LOCAL4, string(datagrams[0]), cuetest.DebugEvent)
+	checkSyslogContents(t, "testapp", LOCAL4, string(datagrams[1]), cuetest.InfoEvent)
+}
+
+func TestSyslogTruncation(t *testing.T) {
+	recorder := cuetest.NewUDPRecorder()
+	recorder.Start()
+	defer recorder.Close()
+
+	c := Syslog{
+		App:      "testapp",
+		Facility: LOCAL4,
+		Network:  "udp",
+		Address:  recorder.Address(),
+	}.New()
+
+	event := *cuetest.InfoEvent
+	event.Message = strings.Repeat("x", 2048)
+	c.Collect(&event)
+	cuetest.CloseCollector(c)
+	recorder.Close()
+
+	datagrams := recorder.Datagrams()
+	if len(datagrams) != 1 {
+		t.Fatalf("Expected 1 datagram but received %d instead", len(datagrams))
+	}
+	if len(datagrams[0]) != 1024 {
+		t.Errorf("Expected a truncated datagram length of 1024 bytes but got %d instead", len(datagrams[0]))
+	}
+	if !bytes.HasSuffix(datagrams[0], []byte("x\n")) {
+		t.Errorf("Expected the truncated datagram to retain its trailing newline but it didn't: %q", datagrams[0])
+	}
+}
+
 func TestSyslogString(t *testing.T) {
 	recorder := cuetest.NewTCPRecorder()
 	recorder.Start()
@@ -0,0 +1,83 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package collector
+
+import (
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+)
+
+// TeeSampled represents configuration for collectors that pass every event to
+// a Primary collector and a sampled subset to a Secondary collector.  This
+// suits a "full local, sampled remote" topology, where a local file receives
+// everything while an expensive hosted service receives a fraction.
+//
+// Secondary sampling follows the same rules as Sample: the first of every
+// SecondaryRate events per level is passed, ERROR and FATAL events are always
+// passed, and sampled events are passed as copies with their SampleRate field
+// set to SecondaryRate.  Primary always receives the original events.
+//
+// Collect returns the first error encountered, and Close closes both
+// collectors if they implement io.Closer.  See Multi for details.
+type TeeSampled struct {
+	// Required
+	Primary       cue.Collector
+	Secondary     cue.Collector
+	SecondaryRate int // Pass 1 in SecondaryRate events per level to Secondary
+}
+
+// New returns a new collector based on the TeeSampled configuration.
+func (t TeeSampled) New() cue.Collector {
+	if t.Primary == nil {
+		log.Warn("TeeSampled.New called to created a collector, but Primary param is empty.  Returning nil collector.")
+		return nil
+	}
+	if t.Secondary == nil {
+		log.Warn("TeeSampled.New called to created a collector, but Secondary param is empty.  Returning nil collector.")
+		return nil
+	}
+	if t.SecondaryRate <= 0 {
+		log.Warn("TeeSampled.New called to created a collector, but SecondaryRate param is not positive.  Returning nil collector.")
+		return nil
+	}
+	sampled := Sample{Target: t.Secondary, N: t.SecondaryRate}.New()
+	return &teeSampledCollector{
+		TeeSampled: t,
+		multi:      &multiCollector{children: []cue.Collector{t.Primary, sampled}},
+	}
+}
+
+type teeSampledCollector struct {
+	TeeSampled
+	multi *multiCollector
+}
+
+func (t *teeSampledCollector) String() string {
+	return fmt.Sprintf("TeeSampled(rate=%d, primary=%s, secondary=%s)", t.SecondaryRate, t.Primary, t.Secondary)
+}
+
+func (t *teeSampledCollector) Collect(event *cue.Event) error {
+	return t.multi.Collect(event)
+}
+
+func (t *teeSampledCollector) Close() error {
+	return t.multi.Close()
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package collector
+
+import (
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/internal/cuetest"
+	"testing"
+)
+
+func TestTeeSampledNilCollector(t *testing.T) {
+	c := TeeSampled{Secondary: cuetest.NewCapturingCollector(), SecondaryRate: 10}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when the primary is missing, but got %s instead", c)
+	}
+
+	c = TeeSampled{Primary: cuetest.NewCapturingCollector(), SecondaryRate: 10}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when the secondary is missing, but got %s instead", c)
+	}
+
+	c = TeeSampled{Primary: cuetest.NewCapturingCollector(), Secondary: cuetest.NewCapturingCollector()}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when the rate is missing, but got %s instead", c)
+	}
+}
+
+func TestTeeSampled(t *testing.T) {
+	primary := cuetest.NewCapturingCollector()
+	secondary := cuetest.NewCapturingCollector()
+	c := TeeSampled{Primary: primary, Secondary: secondary, SecondaryRate: 10}.New()
+
+	for i := 0; i < 100; i++ {
+		c.Collect(cuetest.InfoEvent)
+	}
+	for i := 0; i < 5; i++ {
+		c.Collect(cuetest.ErrorEvent)
+	}
+
+	checkRouted(t, "primary", primary, 105)
+	for _, event := range primary.Captured() {
+		if event.SampleRate != 0 {
+			t.Errorf("Expected primary events to be unsampled, but saw sample rate %d", event.SampleRate)
+		}
+	}
+
+	checkRouted(t, "secondary", secondary, 15)
+	for _, event := range secondary.Captured() {
+		switch event.Level {
+		case cue.INFO:
+			if event.SampleRate != 10 {
+				t.Errorf("Expected sampled events to have a sample rate of 10, but saw %d", event.SampleRate)
+			}
+		case cue.ERROR:
+			if event != cuetest.ErrorEvent {
+				t.Error("Expected errors to be passed to the secondary unmodified, but they weren't")
+			}
+		}
+	}
+}
+
+func TestTeeSampledClose(t *testing.T) {
+	primary := &closingCollector{}
+	secondary := &closingCollector{}
+	c := TeeSampled{Primary: primary, Secondary: secondary, SecondaryRate: 10}.New()
+
+	cuetest.CloseCollector(c)
+	if primary.closes != 1 || secondary.closes != 1 {
+		t.Errorf("Expected each collector to be closed once, but saw %d and %d closes instead", primary.closes, secondary.closes)
+	}
+}
+
+func TestTeeSampledString(t *testing.T) {
+	c := TeeSampled{Primary: cuetest.NewCapturingCollector(), Secondary: cuetest.NewCapturingCollector(), SecondaryRate: 10}.New()
+
+	// Ensure nothing panics
+	_ = fmt.Sprint(c)
+}
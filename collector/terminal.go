@@ -24,13 +24,37 @@ import (
 	"github.com/bobziuchkovski/cue"
 	"github.com/bobziuchkovski/cue/format"
 	"os"
+	"strings"
+	"sync"
 )
 
 // Terminal represents configuration for stdout/stderr collection.  By
-// default, all events are logged to stdout.
+// default, all events are logged to stdout.  If StderrThreshold is set,
+// events at or above that severity are written to stderr instead, which
+// follows the twelve-factor convention of logging to stdout while letting
+// operators separate out errors.
+//
+// If AutoColor is set, output is wrapped with format.Colorize for each
+// stream that's a terminal (see format.IsTerminal).  Output redirected to
+// files or pipes is left uncolored.
+//
+// If logged messages or context values may contain untrusted input, consider
+// wrapping the Formatter with format.StripANSI to prevent escape sequences
+// from manipulating the terminal.
 type Terminal struct {
 	Formatter      format.Formatter // Default: format.HumanReadable
 	ErrorsToStderr bool             // If set, ERROR and FATAL events are written to stderr
+	AutoColor      bool             // If set, output is colorized for streams that are terminals
+
+	// StderrThreshold, if set, sends events at or above the given severity
+	// to stderr.  For example, cue.WARN sends WARN, ERROR, and FATAL events
+	// to stderr.  ErrorsToStderr is equivalent to cue.ERROR.  Default: cue.OFF
+	// (disabled)
+	StderrThreshold cue.Level
+
+	// MaxLines limits the lines retained by collectors created via
+	// NewBuffered.  The oldest lines are discarded first.  Default: unlimited
+	MaxLines int
 }
 
 // New returns a new collector based on the Terminal configuration.
@@ -38,11 +62,21 @@ func (t Terminal) New() cue.Collector {
 	if t.Formatter == nil {
 		t.Formatter = format.HumanReadable
 	}
-	return &terminalCollector{Terminal: t}
+	if t.ErrorsToStderr && t.StderrThreshold < cue.ERROR {
+		t.StderrThreshold = cue.ERROR
+	}
+	return &terminalCollector{
+		Terminal:   t,
+		formatters: make(map[*os.File]format.Formatter),
+	}
 }
 
 type terminalCollector struct {
 	Terminal
+
+	// Formatters caches the formatter for each output, since AutoColor
+	// depends on whether the output is a terminal.
+	formatters map[*os.File]format.Formatter
 }
 
 func (t *terminalCollector) String() string {
@@ -51,13 +85,13 @@ func (t *terminalCollector) String() string {
 
 func (t *terminalCollector) Collect(event *cue.Event) error {
 	output := os.Stdout
-	if t.ErrorsToStderr && (event.Level == cue.ERROR || event.Level == cue.FATAL) {
+	if t.StderrThreshold != cue.OFF && event.Level <= t.StderrThreshold {
 		output = os.Stderr
 	}
 
 	buf := format.GetBuffer()
 	defer format.ReleaseBuffer(buf)
-	t.Formatter(buf, event)
+	t.formatterFor(output)(buf, event)
 
 	bytes := buf.Bytes()
 	if bytes[len(bytes)-1] != byte('\n') {
@@ -67,3 +101,70 @@ func (t *terminalCollector) Collect(event *cue.Event) error {
 	_, err := output.Write(bytes)
 	return err
 }
+
+func (t *terminalCollector) formatterFor(output *os.File) format.Formatter {
+	formatter, present := t.formatters[output]
+	if present {
+		return formatter
+	}
+	formatter = t.Formatter
+	if t.AutoColor && format.IsTerminal(output) {
+		formatter = format.Colorize(formatter)
+	}
+	t.formatters[output] = formatter
+	return formatter
+}
+
+// NewBuffered returns a collector that buffers rendered lines in memory
+// instead of writing them to stdout/stderr.  This is useful for terminal UI
+// applications that own the screen and would otherwise have their display
+// corrupted by interleaved log output.  Such applications should call Drain
+// periodically and render the returned lines themselves.  The
+// ErrorsToStderr param is ignored for buffered collectors.
+func (t Terminal) NewBuffered() *BufferedTerminal {
+	if t.Formatter == nil {
+		t.Formatter = format.HumanReadable
+	}
+	return &BufferedTerminal{terminal: t}
+}
+
+// BufferedTerminal is a cue.Collector that buffers rendered lines for
+// terminal UI applications.  It's created via Terminal.NewBuffered.
+// BufferedTerminal methods are safe for concurrent use.
+type BufferedTerminal struct {
+	terminal Terminal
+
+	mu    sync.Mutex
+	lines []string
+}
+
+// String returns a string representation of the collector.
+func (b *BufferedTerminal) String() string {
+	return "BufferedTerminal()"
+}
+
+// Collect renders the event and buffers the resulting lines.  Events that
+// render multiple lines, such as those with stack traces, are split into
+// separate lines.
+func (b *BufferedTerminal) Collect(event *cue.Event) error {
+	rendered := strings.TrimSuffix(format.RenderString(b.terminal.Formatter, event), "\n")
+	lines := strings.Split(rendered, "\n")
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lines = append(b.lines, lines...)
+	if b.terminal.MaxLines > 0 && len(b.lines) > b.terminal.MaxLines {
+		b.lines = append([]string(nil), b.lines[len(b.lines)-b.terminal.MaxLines:]...)
+	}
+	return nil
+}
+
+// Drain returns the buffered lines, oldest first, and clears the buffer.
+func (b *BufferedTerminal) Drain() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	lines := b.lines
+	b.lines = nil
+	return lines
+}
@@ -22,9 +22,13 @@ package collector
 
 import (
 	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/format"
 	"github.com/bobziuchkovski/cue/internal/cuetest"
 	"io/ioutil"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -72,6 +76,90 @@ func TestTerminalStderr(t *testing.T) {
 	checkFileContents(t, stderr.Name(), terminalErrorStr)
 }
 
+func TestTerminalStderrThreshold(t *testing.T) {
+	realStdout, realStderr := os.Stdout, os.Stderr
+	defer restoreStdoutStderr(realStdout, realStderr)
+
+	stdout, stderr := replaceStdoutStderr()
+	c := Terminal{StderrThreshold: cue.WARN, Formatter: format.Message}.New()
+
+	c.Collect(cuetest.DebugEvent)
+	c.Collect(cuetest.InfoEvent)
+	c.Collect(cuetest.WarnEvent)
+	c.Collect(cuetest.ErrorEvent)
+	restoreStdoutStderr(realStdout, realStderr)
+
+	stdout.Close()
+	stderr.Close()
+	checkFileContents(t, stdout.Name(), "debug event\ninfo event\n")
+	checkFileContents(t, stderr.Name(), "warn event\nerror event\n")
+}
+
+func TestTerminalAutoColorNotTerminal(t *testing.T) {
+	realStdout, realStderr := os.Stdout, os.Stderr
+	defer restoreStdoutStderr(realStdout, realStderr)
+
+	// The replacements are regular files, so no color codes are written.
+	stdout, stderr := replaceStdoutStderr()
+	c := Terminal{AutoColor: true, ErrorsToStderr: true}.New()
+
+	c.Collect(cuetest.DebugEvent)
+	c.Collect(cuetest.ErrorEvent)
+	restoreStdoutStderr(realStdout, realStderr)
+
+	stdout.Close()
+	stderr.Close()
+	checkFileContents(t, stdout.Name(), terminalDebugStr)
+	checkFileContents(t, stderr.Name(), terminalErrorStr)
+}
+
+func TestBufferedTerminal(t *testing.T) {
+	realStdout, realStderr := os.Stdout, os.Stderr
+	defer restoreStdoutStderr(realStdout, realStderr)
+
+	stdout, _ := replaceStdoutStderr()
+	c := Terminal{}.NewBuffered()
+
+	c.Collect(cuetest.DebugEvent)
+	c.Collect(cuetest.ErrorEvent)
+	restoreStdoutStderr(realStdout, realStderr)
+
+	expected := []string{strings.TrimSuffix(terminalDebugStr, "\n"), strings.TrimSuffix(terminalErrorStr, "\n")}
+	lines := c.Drain()
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("Expected drained lines %q but got %q instead", expected, lines)
+	}
+	if len(c.Drain()) != 0 {
+		t.Error("Expected Drain to clear the buffered lines but it didn't")
+	}
+
+	err := stdout.Close()
+	if err != nil {
+		t.Errorf("Encountered unexpected error: %s", err)
+	}
+	checkFileContents(t, stdout.Name(), "")
+}
+
+func TestBufferedTerminalMultiLine(t *testing.T) {
+	c := Terminal{Formatter: format.Literal("first\nsecond\n"), MaxLines: 3}.NewBuffered()
+
+	c.Collect(cuetest.DebugEvent)
+	c.Collect(cuetest.InfoEvent)
+
+	expected := []string{"second", "first", "second"}
+	lines := c.Drain()
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("Expected drained lines %q but got %q instead", expected, lines)
+	}
+}
+
+func TestBufferedTerminalString(t *testing.T) {
+	c := Terminal{}.NewBuffered()
+
+	// Ensure nothing panics
+	_ = fmt.Sprint(c)
+}
+
 func TestTerminalString(t *testing.T) {
 	c := Terminal{ErrorsToStderr: true}.New()
 
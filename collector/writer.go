@@ -0,0 +1,85 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package collector
+
+import (
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/format"
+	"io"
+	"os"
+)
+
+// Writer represents configuration for collection to arbitrary io.Writer
+// instances.  Events are written to Writer unless their level has an entry
+// in Route, in which case they're written to the routed writer instead.
+// This is useful for splitting levels across destinations, or for capturing
+// specific levels into separate in-memory buffers during tests.
+type Writer struct {
+	Writer    io.Writer               // Default: os.Stdout
+	Formatter format.Formatter        // Default: format.HumanReadable
+	Route     map[cue.Level]io.Writer // Per-level writers.  Unmapped levels use Writer
+}
+
+// New returns a new collector based on the Writer configuration.
+func (w Writer) New() cue.Collector {
+	if w.Writer == nil {
+		w.Writer = os.Stdout
+	}
+	if w.Formatter == nil {
+		w.Formatter = format.HumanReadable
+	}
+
+	route := make(map[cue.Level]io.Writer)
+	for level, writer := range w.Route {
+		if writer != nil {
+			route[level] = writer
+		}
+	}
+	w.Route = route
+	return &writerCollector{Writer: w}
+}
+
+type writerCollector struct {
+	Writer
+}
+
+func (w *writerCollector) String() string {
+	return fmt.Sprintf("Writer(routes=%d)", len(w.Route))
+}
+
+func (w *writerCollector) Collect(event *cue.Event) error {
+	output, ok := w.Route[event.Level]
+	if !ok {
+		output = w.Writer.Writer
+	}
+
+	buf := format.GetBuffer()
+	defer format.ReleaseBuffer(buf)
+	w.Formatter(buf, event)
+
+	bytes := buf.Bytes()
+	if len(bytes) == 0 || bytes[len(bytes)-1] != byte('\n') {
+		bytes = append(bytes, byte('\n'))
+	}
+
+	_, err := output.Write(bytes)
+	return err
+}
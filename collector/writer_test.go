@@ -0,0 +1,76 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package collector
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/format"
+	"github.com/bobziuchkovski/cue/internal/cuetest"
+	"io"
+	"testing"
+)
+
+func TestWriterDefault(t *testing.T) {
+	buf := &bytes.Buffer{}
+	c := Writer{Writer: buf}.New()
+	c.Collect(cuetest.DebugEvent)
+	c.Collect(cuetest.ErrorEvent)
+
+	expected := terminalDebugStr + terminalErrorStr
+	if buf.String() != expected {
+		t.Errorf("Expected %q, received %q", expected, buf.String())
+	}
+}
+
+func TestWriterRoute(t *testing.T) {
+	def, debug, errs := &bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{}
+	c := Writer{
+		Writer:    def,
+		Formatter: format.Message,
+		Route: map[cue.Level]io.Writer{
+			cue.DEBUG: debug,
+			cue.ERROR: errs,
+		},
+	}.New()
+
+	c.Collect(cuetest.DebugEvent)
+	c.Collect(cuetest.InfoEvent)
+	c.Collect(cuetest.ErrorEvent)
+	c.Collect(cuetest.DebugEvent)
+
+	if debug.String() != "debug event\ndebug event\n" {
+		t.Errorf("Unexpected DEBUG output: %q", debug.String())
+	}
+	if errs.String() != "error event\n" {
+		t.Errorf("Unexpected ERROR output: %q", errs.String())
+	}
+	if def.String() != "info event\n" {
+		t.Errorf("Unexpected default output: %q", def.String())
+	}
+}
+
+func TestWriterString(t *testing.T) {
+	c := Writer{Route: map[cue.Level]io.Writer{cue.DEBUG: &bytes.Buffer{}}}.New()
+
+	// Ensure nothing panics
+	_ = fmt.Sprint(c)
+}
@@ -25,12 +25,10 @@ import (
 	"sync/atomic"
 )
 
-// cfg holds our global logging config.
-var cfg = &atomicConfig{}
-
-func init() {
-	cfg.set(newConfig())
-}
+// cfg holds our global logging config.  It's initialized during variable
+// declaration rather than via init() since package-level contexts may call
+// Context.WithValue, which consults the config, prior to init() running.
+var cfg = newAtomicConfig()
 
 type atomicConfig struct {
 	// The mutex is only used during config updates.  Reads are handled via
@@ -39,6 +37,12 @@ type atomicConfig struct {
 	cfg atomic.Value
 }
 
+func newAtomicConfig() *atomicConfig {
+	ac := &atomicConfig{}
+	ac.set(newConfig())
+	return ac
+}
+
 func (ac *atomicConfig) get() *config {
 	return ac.cfg.Load().(*config)
 }
@@ -56,15 +60,34 @@ func (ac *atomicConfig) unlock() {
 }
 
 type config struct {
-	threshold   Level
-	frames      int
-	errorFrames int
-	registry    registry
+	threshold        Level
+	frames           int
+	errorFrames      int
+	maxContextValues int
+	errorLevelFunc   ErrorLevelFunc
+	checkpointHooks  []func(name string)
+	closeMarker      bool
+	registry         registry
 }
 
 type registry map[Collector]*entry
 
+// named returns the collector and entry registered with the given name.
+// Unnamed collectors are never matched.
+func (r registry) named(name string) (Collector, *entry, bool) {
+	if name == "" {
+		return nil, nil, false
+	}
+	for c, entry := range r {
+		if entry.name == name {
+			return c, entry, true
+		}
+	}
+	return nil, nil, false
+}
+
 type entry struct {
+	name      string
 	threshold Level
 	degraded  bool
 	worker    worker
@@ -72,6 +95,7 @@ type entry struct {
 
 func (e *entry) clone() *entry {
 	return &entry{
+		name:      e.name,
 		threshold: e.threshold,
 		degraded:  e.degraded,
 		worker:    e.worker,
@@ -90,17 +114,34 @@ func newConfig() *config {
 // clone duplicates configuration for atomic updates.
 func (c *config) clone() *config {
 	new := &config{
-		threshold:   c.threshold,
-		frames:      c.frames,
-		errorFrames: c.errorFrames,
-		registry:    make(registry),
+		threshold:        c.threshold,
+		frames:           c.frames,
+		errorFrames:      c.errorFrames,
+		maxContextValues: c.maxContextValues,
+		errorLevelFunc:   c.errorLevelFunc,
+		closeMarker:      c.closeMarker,
+		registry:         make(registry),
 	}
+	new.checkpointHooks = append(new.checkpointHooks, c.checkpointHooks...)
 	for collector, entry := range c.registry {
 		new.registry[collector] = entry.clone()
 	}
 	return new
 }
 
+// errorLevel returns the level for events logged via Logger.Error and
+// Logger.Errorf, consulting the configured ErrorLevelFunc, if any.
+func (c *config) errorLevel(err error) Level {
+	if c.errorLevelFunc == nil {
+		return ERROR
+	}
+	level := c.errorLevelFunc(err, ERROR)
+	if level > TRACE {
+		return ERROR
+	}
+	return level
+}
+
 // updateThreshold should only be called on a new, cloned config
 func (c *config) updateThreshold() {
 	max := OFF
@@ -21,8 +21,12 @@
 package cue
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
+	"sync"
+	"time"
 )
 
 var (
@@ -41,31 +45,42 @@ type Fields map[string]interface{}
 // key/value pair may be added to a context with one exception: an empty string
 // is not a valid key.  Pointer values are dereferenced and their target is
 // added.  Values of basic types -- string, bool, integer, float, and complex
-// -- are stored directly.  Other types, including all slices and arrays, are
-// coerced to a string representation via fmt.Sprint.  This ensures stored
-// context values are immutable.  This is important for safe asynchronous
-// operation.
+// -- are stored directly.  Named types with basic underlying types, such as
+// time.Duration and ByteSize, retain their type so formatters may choose their
+// representation.  time.Time values are likewise stored directly.  Values
+// created via Deferred are stored as-is and rendered lazily.  Other types,
+// including all slices and arrays, are coerced to a string representation via
+// fmt.Sprint.  This ensures stored context values are immutable.  This is
+// important for safe asynchronous operation.
 //
-// Storing duplicate keys is allowed, but the resulting behavior is currently
-// undefined.
+// Storing duplicate keys is allowed.  The most recently added value for a key
+// replaces earlier values, as seen by NumValues, Each, and Fields.
+//
+// The number of key/value pairs stored in a Context is unlimited by default.
+// See SetMaxContextValues for details on capping context size.
 type Context interface {
 	// Name returns the name of the context.
 	Name() string
 
-	// NumValues returns the number of key/value pairs in the Context.
-	// The counting behavior for duplicate keys is currently undefined.
+	// NumValues returns the number of distinct keys in the Context.
 	NumValues() int
 
 	// Each executes function fn on each of the Context's key/value pairs.
+	// For duplicate keys, only the most recently added value is visited.
 	// Iteration order is currently undefined.
 	Each(fn func(key string, value interface{}))
 
 	// Fields returns a map representation of the Context's key/value pairs.
-	// Duplicate key handling is currently undefined.
+	// A new map is returned on each call, so it's safe to modify.
+	// For duplicate keys, the most recently added value is used.
 	Fields() Fields
 
 	// WithFields returns a new Context that adds the key/value pairs from
-	// fields to the existing key/value pairs.
+	// fields to the existing key/value pairs.  Values in fields replace
+	// existing values for the same keys, so chained calls are last-writer-wins:
+	// WithFields(Fields{"a": 1}).WithFields(Fields{"a": 2}) yields a=2.  The
+	// pairs are added in sorted key order, so the outcome is deterministic
+	// even when SetMaxContextValues causes keys to be dropped.
 	WithFields(fields Fields) Context
 
 	// WithValue returns a new Context that adds key and value to the existing
@@ -95,6 +110,30 @@ func JoinContext(name string, contexts ...Context) Context {
 	return joined
 }
 
+// FilterContext returns a new Context with the same name as ctx, containing
+// the key/value pairs from ctx for which drop returns false.  Unlike
+// WithValue, FilterContext copies values as-is: they're already immutable, so
+// they aren't coerced again.  This preserves their types for collectors and
+// formatters.  The relative order of the retained pairs is preserved as well.
+func FilterContext(ctx Context, drop func(key string, value interface{}) bool) Context {
+	var kept []*pairs
+	ctx.Each(func(key string, value interface{}) {
+		if !drop(key, value) {
+			kept = append(kept, &pairs{key: key, value: value})
+		}
+	})
+
+	// Each visits the newest pairs first, so we rebuild from the end.
+	filtered := emptyPairs
+	for i := len(kept) - 1; i >= 0; i-- {
+		filtered = filtered.append(kept[i].key, kept[i].value)
+	}
+	return &context{
+		name:  ctx.Name(),
+		pairs: filtered,
+	}
+}
+
 // NewContext returns a new Context with the given name.
 func NewContext(name string) Context {
 	return &context{
@@ -124,9 +163,17 @@ func (c *context) Fields() Fields {
 }
 
 func (c *context) WithFields(fields Fields) Context {
+	// Map iteration order is random, so we sort the keys to ensure the pairs
+	// are appended, and possibly capped, in a predictable order.
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
 	var new Context = c
-	for k, v := range fields {
-		new = new.WithValue(k, v)
+	for _, k := range keys {
+		new = new.WithValue(k, fields[k])
 	}
 	return new
 }
@@ -135,55 +182,182 @@ func (c *context) WithValue(key string, value interface{}) Context {
 	if key == "" {
 		return c
 	}
+	max := cfg.get().maxContextValues
+	if max > 0 && c.pairs.count() >= max && !c.pairs.has(key) {
+		internalLogger.Warnf("Context %q has reached the maximum of %d values.  Dropping value for key %q.  See cue.SetMaxContextValues for details.", c.name, max, key)
+		return c
+	}
 	return &context{
 		name:  c.name,
 		pairs: c.pairs.append(key, basicValue(value)),
 	}
 }
 
+// pairs is an immutable linked list of key/value pairs, newest first.  A
+// key may appear more than once, in which case the newest entry shadows
+// the older ones.
+//
+// Appending must stay O(1), so we don't search the list for duplicates.
+// Instead, each entry carries a bitmask of its list's key hashes.  If the
+// appended key's bit isn't already set, the key can't collide, and the list
+// is known to be free of shadowed entries.  Otherwise, shadowed entries are
+// resolved lazily by each and count.
 type pairs struct {
-	prev  *pairs
-	key   string
-	value interface{}
+	prev   *pairs
+	key    string
+	value  interface{}
+	depth  int    // Number of entries, including shadowed ones
+	keys   uint64 // Bitmask of key hashes for the list
+	shadow bool   // Whether the list may contain shadowed entries
 }
 
 func (p *pairs) append(key string, value interface{}) *pairs {
-	return &pairs{
+	bit := keyBit(key)
+	appended := &pairs{
 		prev:  p,
 		key:   key,
 		value: value,
+		depth: p.len() + 1,
+		keys:  bit,
 	}
+	if p != nil {
+		appended.keys |= p.keys
+		appended.shadow = p.shadow || p.keys&bit != 0
+	}
+	return appended
+}
+
+// keyBit returns a single bit selected by the FNV-1a hash of key.
+func keyBit(key string) uint64 {
+	hash := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		hash ^= uint32(key[i])
+		hash *= 16777619
+	}
+	return 1 << (hash % 64)
+}
+
+func (p *pairs) has(key string) bool {
+	for current := p; current != nil; current = current.prev {
+		if current.key == key {
+			return true
+		}
+	}
+	return false
 }
 
 func (p *pairs) each(fn func(key string, value interface{})) {
+	if p == nil || !p.shadow {
+		// No shadowed entries, so we can skip tracking visited keys.
+		for current := p; current != nil; current = current.prev {
+			fn(current.key, current.value)
+		}
+		return
+	}
+
+	seen := make(map[string]bool, p.len())
 	for current := p; current != nil; current = current.prev {
+		if seen[current.key] {
+			continue
+		}
+		seen[current.key] = true
 		fn(current.key, current.value)
 	}
 }
 
+func (p *pairs) len() int {
+	if p == nil {
+		return 0
+	}
+	return p.depth
+}
+
+// count returns the number of distinct keys in the list.
 func (p *pairs) count() int {
+	if p == nil || !p.shadow {
+		return p.len()
+	}
 	count := 0
-	for current := p; current != nil; current = current.prev {
+	p.each(func(key string, value interface{}) {
 		count++
-	}
+	})
 	return count
 }
 
 func (p *pairs) toFields() Fields {
-	if p == nil {
-		return make(Fields)
-	}
-	fields := p.prev.toFields()
-	fields[p.key] = p.value
+	fields := make(Fields, p.len())
+	p.each(func(key string, value interface{}) {
+		fields[key] = value
+	})
 	return fields
 }
 
+// DeferredValue is a context value that is rendered lazily.  See Deferred for
+// details.
+type DeferredValue struct {
+	once  sync.Once
+	fn    func() interface{}
+	value interface{}
+}
+
+// Deferred returns a context value that defers calling fn until the value is
+// rendered by a formatter.  This is useful for attaching large or expensive
+// objects to a context, since the cost of rendering them is only paid if a
+// collector actually renders the context.  Fn is called at most once and its
+// result is cached.
+//
+// Deferred values may be rendered asynchronously, long after the logging call
+// that generated the event returns.  Hence fn must be pure: it must not depend
+// on mutable state, and its result must be immutable.
+func Deferred(fn func() interface{}) *DeferredValue {
+	return &DeferredValue{fn: fn}
+}
+
+// Value calls the deferred function, if it hasn't been called already, and
+// returns the result.  Pointer and non-basic results are coerced according to
+// the same rules used for other context values.
+func (d *DeferredValue) Value() interface{} {
+	d.once.Do(func() {
+		if d.fn != nil {
+			d.value = basicValue(d.fn())
+		} else {
+			d.value = basicValue(nil)
+		}
+	})
+	return d.value
+}
+
+// String returns the string representation of the deferred value.
+func (d *DeferredValue) String() string {
+	return fmt.Sprint(d.Value())
+}
+
+// MarshalJSON returns the JSON encoding of the deferred value.
+func (d *DeferredValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Value())
+}
+
 // basicValue serves to dereference pointers and coerce non-basic types to strings,
 // ensuring all values are effectively immutable.  The latter is critical for
 // asynchronous operation.  We can't have context values changing while an event is
 // queued, or else the logged value won't represent the value as it was at the
 // time the event was generated.
 func basicValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *DeferredValue:
+		if v != nil {
+			return v
+		}
+	case time.Time:
+		// Strip the monotonic clock reading, which is meaningless outside
+		// the current process and clutters fmt output.
+		return v.Round(0)
+	case *time.Time:
+		if v != nil {
+			return v.Round(0)
+		}
+	}
+
 	rval := reflect.ValueOf(value)
 	if !rval.IsValid() {
 		return fmt.Sprint(value)
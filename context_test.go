@@ -22,12 +22,14 @@ package cue
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"math"
 	"reflect"
 	"testing"
+	"time"
 )
 
 var contextFieldTests = []struct {
@@ -122,6 +124,196 @@ func TestContextEmptyKey(t *testing.T) {
 	}
 }
 
+func TestContextMaxValues(t *testing.T) {
+	defer resetCue()
+	c := newCapturingCollector()
+	Collect(WARN, c)
+	SetMaxContextValues(2)
+
+	ctx := NewContext("test").WithValue("k1", 1).WithValue("k2", 2).WithValue("k3", 3)
+	if ctx.NumValues() != 2 {
+		t.Errorf("Expected the context to be capped at 2 values, but saw %d instead", ctx.NumValues())
+	}
+	expected := Fields{"k1": 1, "k2": 2}
+	if !reflect.DeepEqual(ctx.Fields(), expected) {
+		t.Errorf("Expected context fields of %v but saw %v instead", expected, ctx.Fields())
+	}
+
+	if len(c.Captured()) != 1 {
+		t.Fatalf("Expected exactly 1 WARN event but saw %d instead", len(c.Captured()))
+	}
+	if c.Captured()[0].Level != WARN {
+		t.Errorf("Expected a WARN event but saw %s instead", c.Captured()[0].Level)
+	}
+
+	SetMaxContextValues(0)
+	ctx = ctx.WithValue("k3", 3)
+	if ctx.NumValues() != 3 {
+		t.Errorf("Expected the context cap to be removed, but saw %d values instead", ctx.NumValues())
+	}
+}
+
+func TestContextDuplicateKeys(t *testing.T) {
+	ctx := NewContext("test").WithValue("user", "a").WithValue("k1", 1).WithValue("user", "b")
+	if ctx.NumValues() != 2 {
+		t.Errorf("Expected NumValues to count 2 distinct keys, but saw %d instead", ctx.NumValues())
+	}
+
+	expected := Fields{"user": "b", "k1": 1}
+	for i := 0; i < 10; i++ {
+		if !reflect.DeepEqual(ctx.Fields(), expected) {
+			t.Fatalf("Expected context fields of %v but saw %v instead", expected, ctx.Fields())
+		}
+
+		visited := make(map[string][]interface{})
+		ctx.Each(func(key string, value interface{}) {
+			visited[key] = append(visited[key], value)
+		})
+		if len(visited) != 2 || len(visited["user"]) != 1 || visited["user"][0] != "b" {
+			t.Fatalf("Expected Each to visit only the most recent value for each key, but saw %v instead", visited)
+		}
+	}
+
+	// The original context is unaffected by later overrides.
+	orig := NewContext("test").WithValue("user", "a")
+	_ = orig.WithValue("user", "b")
+	if !reflect.DeepEqual(orig.Fields(), Fields{"user": "a"}) {
+		t.Errorf("Expected the original context to retain its value, but saw %v instead", orig.Fields())
+	}
+}
+
+func TestContextManyKeys(t *testing.T) {
+	// More keys than the duplicate bitmask has bits, so collisions are certain.
+	ctx := NewContext("test")
+	for i := 0; i < 200; i++ {
+		ctx = ctx.WithValue(fmt.Sprint("k", i), i)
+	}
+	ctx = ctx.WithValue("k7", "replaced")
+	if ctx.NumValues() != 200 {
+		t.Errorf("Expected NumValues to count 200 distinct keys, but saw %d instead", ctx.NumValues())
+	}
+	fields := ctx.Fields()
+	if len(fields) != 200 || fields["k7"] != "replaced" || fields["k199"] != 199 {
+		t.Errorf("Expected 200 fields with k7 replaced, but saw %v instead", fields)
+	}
+}
+
+func TestContextWithFieldsLastWriterWins(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		ctx := NewContext("test").WithFields(Fields{"a": 1, "b": 1}).WithFields(Fields{"a": 2, "c": 2})
+		expected := Fields{"a": 2, "b": 1, "c": 2}
+		if !reflect.DeepEqual(ctx.Fields(), expected) {
+			t.Fatalf("Expected context fields of %v but saw %v instead", expected, ctx.Fields())
+		}
+	}
+}
+
+func TestContextWithFieldsMaxValues(t *testing.T) {
+	defer resetCue()
+	SetMaxContextValues(2)
+
+	for i := 0; i < 100; i++ {
+		ctx := NewContext("test").WithFields(Fields{"k3": 3, "k1": 1, "k2": 2, "k4": 4})
+		expected := Fields{"k1": 1, "k2": 2}
+		if !reflect.DeepEqual(ctx.Fields(), expected) {
+			t.Fatalf("Expected the capped context to retain the first keys in sorted order, %v, but saw %v instead", expected, ctx.Fields())
+		}
+	}
+}
+
+func TestContextMaxValuesDuplicateKey(t *testing.T) {
+	defer resetCue()
+	SetMaxContextValues(2)
+
+	ctx := NewContext("test").WithValue("k1", 1).WithValue("k2", 2).WithValue("k1", 3)
+	expected := Fields{"k1": 3, "k2": 2}
+	if !reflect.DeepEqual(ctx.Fields(), expected) {
+		t.Errorf("Expected an existing key to be replaceable at the cap, but saw %v instead", ctx.Fields())
+	}
+}
+
+func TestContextTimeValues(t *testing.T) {
+	now := time.Now()
+	elapsed := 1500 * time.Millisecond
+	ctx := NewContext("test").WithValue("time", now).WithValue("ptr", &now).WithValue("elapsed", elapsed)
+
+	fields := ctx.Fields()
+	for _, key := range []string{"time", "ptr"} {
+		stored, ok := fields[key].(time.Time)
+		if !ok {
+			t.Errorf("Expected %q to be stored as a time.Time, but got %#v instead", key, fields[key])
+			continue
+		}
+		if !stored.Equal(now) {
+			t.Errorf("Expected %q to equal %s, but got %s instead", key, now, stored)
+		}
+		if stored != now.Round(0) {
+			t.Errorf("Expected %q to have its monotonic clock reading stripped, but it didn't", key)
+		}
+	}
+	if fields["elapsed"] != elapsed {
+		t.Errorf("Expected elapsed to be stored as a time.Duration, but got %#v instead", fields["elapsed"])
+	}
+}
+
+func TestFilterContext(t *testing.T) {
+	ctx := NewContext("test").WithValue("k1", 1).WithValue("secret", "s").WithValue("k2", 2*time.Second)
+	filtered := FilterContext(ctx, func(key string, value interface{}) bool {
+		return key == "secret"
+	})
+
+	if filtered.Name() != "test" {
+		t.Errorf("Expected the filtered context to retain the name %q, but saw %q instead", "test", filtered.Name())
+	}
+	expected := Fields{"k1": 1, "k2": 2 * time.Second}
+	if !reflect.DeepEqual(filtered.Fields(), expected) {
+		t.Errorf("Expected filtered fields %v but saw %v instead", expected, filtered.Fields())
+	}
+
+	var keys []string
+	filtered.Each(func(key string, value interface{}) {
+		keys = append(keys, key)
+	})
+	if !reflect.DeepEqual(keys, []string{"k2", "k1"}) {
+		t.Errorf("Expected the filtered context to retain pair order, but saw %v instead", keys)
+	}
+}
+
+func TestContextDeferredValue(t *testing.T) {
+	calls := 0
+	deferred := Deferred(func() interface{} {
+		calls++
+		return []int{1, 2, 3}
+	})
+
+	ctx := NewContext("test").WithValue("deferred", deferred)
+	if calls != 0 {
+		t.Errorf("Expected deferred function to remain uncalled when added to a context, but it was called %d times", calls)
+	}
+
+	value := ctx.Fields()["deferred"]
+	if value != deferred {
+		t.Errorf("Expected deferred value to be stored as-is, but saw %#v instead", value)
+	}
+	if calls != 0 {
+		t.Errorf("Expected deferred function to remain uncalled when retrieving fields, but it was called %d times", calls)
+	}
+
+	if fmt.Sprint(value) != "[1 2 3]" {
+		t.Errorf("Expected deferred value to render as %q, but saw %q instead", "[1 2 3]", fmt.Sprint(value))
+	}
+	marshaled, err := json.Marshal(value)
+	if err != nil {
+		t.Errorf("Encountered unexpected error marshaling deferred value: %s", err)
+	}
+	if string(marshaled) != `"[1 2 3]"` {
+		t.Errorf("Expected deferred value to marshal as %q, but saw %q instead", `"[1 2 3]"`, marshaled)
+	}
+	if calls != 1 {
+		t.Errorf("Expected deferred function to be called exactly once, but it was called %d times", calls)
+	}
+}
+
 func TestJoinContext(t *testing.T) {
 	c1 := NewContext("first").WithValue("k1", "v1").WithFields(Fields{"k2": 2, "k3": 3.0})
 	c2 := NewContext("second").WithFields(Fields{"k4": "v4", "k5": true}).WithValue("k6", uintptr(0x12345678))
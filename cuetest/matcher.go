@@ -0,0 +1,153 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cuetest
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// Matcher specifies constraints for matching events via ExpectEvent.  Zero
+// values are unconstrained.  Hence an empty Matcher matches any event.
+type Matcher struct {
+	Level         cue.Level      // Event level must equal Level
+	Message       string         // Event message must contain Message
+	MessageRegexp *regexp.Regexp // Event message must match MessageRegexp
+	Error         error          // Event error must have the same Error() value
+	Fields        cue.Fields     // Event context must contain each key with an equal value
+}
+
+// Matches returns true if event satisfies all of the matcher's constraints.
+func (m Matcher) Matches(event *cue.Event) bool {
+	if m.Level != cue.OFF && event.Level != m.Level {
+		return false
+	}
+	if m.Message != "" && !strings.Contains(event.Message, m.Message) {
+		return false
+	}
+	if m.MessageRegexp != nil && !m.MessageRegexp.MatchString(event.Message) {
+		return false
+	}
+	if m.Error != nil && !errorsMatch(m.Error, event.Error) {
+		return false
+	}
+
+	fields := event.Context.Fields()
+	for k, expected := range m.Fields {
+		actual, present := fields[k]
+		if !present || !valuesMatch(expected, actual) {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns a description of the matcher's constraints.
+func (m Matcher) String() string {
+	var constraints []string
+	if m.Level != cue.OFF {
+		constraints = append(constraints, fmt.Sprintf("level=%s", m.Level))
+	}
+	if m.Message != "" {
+		constraints = append(constraints, fmt.Sprintf("message contains %q", m.Message))
+	}
+	if m.MessageRegexp != nil {
+		constraints = append(constraints, fmt.Sprintf("message matches /%s/", m.MessageRegexp))
+	}
+	if m.Error != nil {
+		constraints = append(constraints, fmt.Sprintf("error=%q", m.Error))
+	}
+	if len(m.Fields) > 0 {
+		constraints = append(constraints, fmt.Sprintf("fields include %s", describeFields(m.Fields)))
+	}
+	if len(constraints) == 0 {
+		return "Matcher(any event)"
+	}
+	return fmt.Sprintf("Matcher(%s)", strings.Join(constraints, ", "))
+}
+
+// ExpectEvent checks whether any of the given events satisfy matcher.  If
+// none do, it calls t.Errorf with a description of the matcher and a listing
+// of the events that were actually captured.  It returns true if a matching
+// event was found.
+//
+// ExpectEvent pairs well with the events returned by
+// CapturingCollector.Captured.
+func ExpectEvent(t testing.TB, events []*cue.Event, matcher Matcher) bool {
+	t.Helper()
+	for _, event := range events {
+		if matcher.Matches(event) {
+			return true
+		}
+	}
+	t.Errorf("%s", describeMismatch(events, matcher))
+	return false
+}
+
+func describeMismatch(events []*cue.Event, matcher Matcher) string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "No captured event satisfies %s\n\n", matcher)
+	fmt.Fprintf(&buf, "Captured %d events\n", len(events))
+	buf.WriteString("========\n")
+	for i, event := range events {
+		fmt.Fprintf(&buf, "%d: level=%s message=%q", i, event.Level, event.Message)
+		if event.Error != nil {
+			fmt.Fprintf(&buf, " error=%q", event.Error)
+		}
+		fmt.Fprintf(&buf, " fields=%s\n", describeFields(event.Context.Fields()))
+	}
+	return buf.String()
+}
+
+func describeFields(fields cue.Fields) string {
+	var keys []string
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = fmt.Sprintf("%s=%v", k, fields[k])
+	}
+	return "{" + strings.Join(pairs, " ") + "}"
+}
+
+func errorsMatch(expected, actual error) bool {
+	if actual == nil {
+		return false
+	}
+	// Errors are compared by message rather than with ==, which panics for
+	// non-comparable error types.
+	return expected.Error() == actual.Error()
+}
+
+// Context values are coerced to basic types, so we fall back to comparing
+// string representations when the values aren't deeply equal.
+func valuesMatch(expected, actual interface{}) bool {
+	return reflect.DeepEqual(expected, actual) || fmt.Sprint(expected) == fmt.Sprint(actual)
+}
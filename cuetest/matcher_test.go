@@ -0,0 +1,139 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cuetest
+
+import (
+	"errors"
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+type recordingT struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+var matcherEvents = []*cue.Event{
+	{
+		Level:   cue.DEBUG,
+		Message: "debug event",
+		Context: cue.NewContext("test").WithFields(cue.Fields{"k1": "some value", "k2": 2, "k3": 3.5, "k4": true}),
+	},
+	{
+		Level:   cue.ERROR,
+		Message: "login failed for bob",
+		Error:   errors.New("bad password"),
+		Context: cue.NewContext("test").WithValue("user", "bob").WithValue("attempts", 3),
+	},
+}
+
+// multiError is a non-comparable error type.  Comparing two instances with
+// == panics.
+type multiError []string
+
+func (m multiError) Error() string {
+	return strings.Join(m, "; ")
+}
+
+var matcherTests = []struct {
+	Name    string
+	Matcher Matcher
+	Matches bool
+}{
+	{Name: "Empty", Matcher: Matcher{}, Matches: true},
+	{Name: "Level", Matcher: Matcher{Level: cue.ERROR}, Matches: true},
+	{Name: "LevelMismatch", Matcher: Matcher{Level: cue.WARN}, Matches: false},
+	{Name: "Message", Matcher: Matcher{Message: "login failed"}, Matches: true},
+	{Name: "MessageMismatch", Matcher: Matcher{Message: "bogus"}, Matches: false},
+	{Name: "MessageRegexp", Matcher: Matcher{MessageRegexp: regexp.MustCompile(`^login .* bob$`)}, Matches: true},
+	{Name: "MessageRegexpMismatch", Matcher: Matcher{MessageRegexp: regexp.MustCompile(`^bob`)}, Matches: false},
+	{Name: "Error", Matcher: Matcher{Error: errors.New("bad password")}, Matches: true},
+	{Name: "ErrorMismatch", Matcher: Matcher{Error: errors.New("bogus")}, Matches: false},
+	{Name: "Fields", Matcher: Matcher{Fields: cue.Fields{"user": "bob", "attempts": 3}}, Matches: true},
+	{Name: "FieldsMismatch", Matcher: Matcher{Fields: cue.Fields{"user": "alice"}}, Matches: false},
+	{Name: "FieldsMissing", Matcher: Matcher{Fields: cue.Fields{"bogus": "bob"}}, Matches: false},
+	{Name: "Combined", Matcher: Matcher{Level: cue.ERROR, Message: "login", Fields: cue.Fields{"user": "bob"}}, Matches: true},
+	{Name: "CombinedMismatch", Matcher: Matcher{Level: cue.DEBUG, Fields: cue.Fields{"user": "bob"}}, Matches: false},
+}
+
+func TestExpectEvent(t *testing.T) {
+	for _, test := range matcherTests {
+		rt := &recordingT{}
+		matched := ExpectEvent(rt, matcherEvents, test.Matcher)
+		if matched != test.Matches {
+			t.Errorf("Match result is incorrect.  Test: %s, Expected: %t, Received: %t", test.Name, test.Matches, matched)
+		}
+		if test.Matches && len(rt.failures) != 0 {
+			t.Errorf("Expected no failures to be reported.  Test: %s, Received: %v", test.Name, rt.failures)
+		}
+		if !test.Matches && len(rt.failures) != 1 {
+			t.Errorf("Expected exactly 1 failure to be reported.  Test: %s, Received: %d", test.Name, len(rt.failures))
+		}
+	}
+}
+
+func TestExpectEventNonComparableError(t *testing.T) {
+	events := []*cue.Event{{
+		Level:   cue.ERROR,
+		Message: "multiple failures",
+		Error:   multiError{"first", "second"},
+		Context: cue.NewContext("test"),
+	}}
+
+	rt := &recordingT{}
+	if !ExpectEvent(rt, events, Matcher{Error: multiError{"first", "second"}}) {
+		t.Errorf("Expected a non-comparable error with the same message to match, but saw failures: %v", rt.failures)
+	}
+	if ExpectEvent(rt, events, Matcher{Error: multiError{"first"}}) {
+		t.Error("Expected a non-comparable error with a different message not to match, but it did")
+	}
+}
+
+func TestExpectEventFailureMessage(t *testing.T) {
+	rt := &recordingT{}
+	ExpectEvent(rt, matcherEvents, Matcher{Level: cue.WARN, Message: "timeout", Fields: cue.Fields{"user": "alice"}})
+	if len(rt.failures) != 1 {
+		t.Fatalf("Expected exactly 1 failure to be reported, but saw %d instead", len(rt.failures))
+	}
+
+	expectations := []string{
+		`level=WARN`,
+		`message contains "timeout"`,
+		`fields include {user=alice}`,
+		`Captured 2 events`,
+		`0: level=DEBUG message="debug event" fields={k1=some value k2=2 k3=3.5 k4=true}`,
+		`1: level=ERROR message="login failed for bob" error="bad password" fields={attempts=3 user=bob}`,
+	}
+	for _, expected := range expectations {
+		if !strings.Contains(rt.failures[0], expected) {
+			t.Errorf("Expected failure message to contain %q, but it didn't.  Message:\n%s", expected, rt.failures[0])
+		}
+	}
+}
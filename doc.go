@@ -34,7 +34,7 @@ Furthermore, collector thresholds may be altered dynamically at run-time, on a
 per-collector basis.  If debugging logs are needed to troubleshoot a live issue,
 collector thresholds may be set to the DEBUG level for a short period of time
 and then restored to their original levels shortly thereafter.  See the SetLevel
-function for details.
+and SetThreshold functions for details.
 
 Basics
 
@@ -93,6 +93,11 @@ If a panic is triggered via a cue logger instance's Panic or Panicf methods,
 Recover recovers from the panic but only emits the single event from the
 Panic/Panicf method.
 
+For unrecoverable errors, such as failures during program startup, calling
+Fatal or Fatalf logs the provided message at the FATAL level, flushes
+asynchronous collectors, and then calls os.Exit(FatalExitCode).  As with the
+standard library's log.Fatal, deferred functions are not run.
+
 Event Collection
 
 Cue decouples event generation from event collection.  Library and framework
@@ -105,7 +110,8 @@ control over the behavior of event collection.
 
 Collectors are registered via the Collect and CollectAsync functions.  Each
 collector is registered for a given level threshold.  The threshold for a
-collector may be updated at any time using the SetLevel function.
+collector may be updated at any time using the SetLevel function, or for all
+registered collectors at once using the SetThreshold function.
 
 Collect registers fully synchronous event collectors.  Logging calls that match
 a synchronous collector's threshold block until the collector's Collect method
@@ -0,0 +1,71 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cue
+
+import (
+	"bytes"
+)
+
+// MultiError is an error that aggregates several underlying errors.  It's
+// the combined error returned by Logger.Errors and assigned to the
+// Event.Error field of events it emits.  Collectors that support multiple
+// causes, such as the hosted Sentry collector, may type assert on *MultiError
+// to report each error individually.
+type MultiError struct {
+	Errors []error
+}
+
+// Error returns the underlying error messages joined by "; ".
+func (me *MultiError) Error() string {
+	var buf bytes.Buffer
+	for i, err := range me.Errors {
+		if i > 0 {
+			buf.WriteString("; ")
+		}
+		buf.WriteString(err.Error())
+	}
+	return buf.String()
+}
+
+// Unwrap returns the underlying errors.  This allows errors.Is and errors.As
+// to match against any of them.
+func (me *MultiError) Unwrap() []error {
+	return me.Errors
+}
+
+// combineErrors returns nil if errs contains no non-nil errors, the sole
+// non-nil error if there's exactly one, and a *MultiError otherwise.
+func combineErrors(errs []error) error {
+	var nonNil []error
+	for _, err := range errs {
+		if err != nil {
+			nonNil = append(nonNil, err)
+		}
+	}
+	switch len(nonNil) {
+	case 0:
+		return nil
+	case 1:
+		return nonNil[0]
+	default:
+		return &MultiError{Errors: nonNil}
+	}
+}
@@ -30,34 +30,99 @@ import (
 // matching collectors across multiple goroutines.  For this reason, Event
 // fields -must not- be altered in place.
 type Event struct {
-	Time    time.Time // Local time when the event was generated
-	Level   Level     // Event severity level
-	Context Context   // Context of the logger that generated the event
-	Frames  []*Frame  // Stack frames for the call site, or nil if disabled
-	Error   error     // The error associated with the message (ERROR and FATAL levels only)
-	Message string    // The log message
+	Time       time.Time // Local time when the event was generated
+	Level      Level     // Event severity level
+	Context    Context   // Context of the logger that generated the event.  Immutable; see Fields
+	Frames     []*Frame  // Stack frames for the call site, or nil if disabled
+	Error      error     // The error associated with the message (ERROR and FATAL levels only)
+	Message    string    // The log message
+	Goroutines int       // Number of goroutines when the event was generated (FATAL level only)
+	SampleRate int       // Sampling rate applied by sampling collectors, e.g. 10 for 1-in-10, or 0 if unsampled
+
+	// Meta holds side-band metadata that collectors and pipeline stages may
+	// use to communicate, such as a computed partition key.  It's never
+	// rendered by formatters.  Use SetMeta and GetMeta to access it.
+	Meta map[string]interface{}
+}
+
+// Clone returns a shallow copy of the event.  The Meta map is copied, so
+// calling SetMeta on the clone doesn't affect the original event.  Other
+// reference fields, such as Frames, are shared with the original.
+func (e *Event) Clone() *Event {
+	clone := *e
+	if e.Meta != nil {
+		clone.Meta = make(map[string]interface{}, len(e.Meta))
+		for k, v := range e.Meta {
+			clone.Meta[k] = v
+		}
+	}
+	return &clone
+}
+
+// Fields returns a map representation of the event's context key/value pairs.
+// The returned map is freshly allocated, so callers may freely modify it.
+//
+// The underlying Context is immutable: logger methods such as WithValue
+// return new contexts rather than modifying existing ones, and context values
+// are stored as immutable copies.  Thus collectors may safely read an
+// event's context on background goroutines long after the logging call has
+// returned.
+func (e *Event) Fields() Fields {
+	if e.Context == nil {
+		return make(Fields)
+	}
+	return e.Context.Fields()
+}
+
+// SetMeta stores value in the event's Meta map under key.  Events are shared
+// across collectors, so SetMeta must only be called on events the caller
+// owns, such as those returned by Clone or passed to pipeline transformers.
+func (e *Event) SetMeta(key string, value interface{}) {
+	if e.Meta == nil {
+		e.Meta = make(map[string]interface{})
+	}
+	e.Meta[key] = value
+}
+
+// GetMeta returns the value stored in the event's Meta map under key, and
+// whether the key was present.
+func (e *Event) GetMeta(key string) (interface{}, bool) {
+	value, present := e.Meta[key]
+	return value, present
 }
 
 func newEvent(context Context, level Level, cause error, message string) *Event {
 	now := time.Now()
-	return &Event{
+	event := &Event{
 		Time:    now,
 		Level:   level,
 		Context: context,
 		Error:   cause,
 		Message: message,
 	}
+	event.captureDiagnostics()
+	return event
 }
 
 func newEventf(context Context, level Level, cause error, format string, values ...interface{}) *Event {
 	now := time.Now()
-	return &Event{
+	event := &Event{
 		Time:    now,
 		Level:   level,
 		Context: context,
 		Error:   cause,
 		Message: fmt.Sprintf(format, values...),
 	}
+	event.captureDiagnostics()
+	return event
+}
+
+// Diagnostics are only captured for FATAL events to avoid per-event overhead.
+func (e *Event) captureDiagnostics() {
+	if e.Level != FATAL {
+		return
+	}
+	e.Goroutines = runtime.NumGoroutine()
 }
 
 func (e *Event) captureFrames(skip int, depth int, errorDepth int, recovering bool) {
@@ -21,6 +21,7 @@
 package cue
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -32,6 +33,18 @@ func TestEventSource(t *testing.T) {
 	}
 }
 
+func TestEventDiagnostics(t *testing.T) {
+	e := newEvent(NewContext("test"), FATAL, nil, "test")
+	if e.Goroutines <= 0 {
+		t.Errorf("Expected FATAL event to carry a positive goroutine count, but saw %d instead", e.Goroutines)
+	}
+
+	e = newEventf(NewContext("test"), ERROR, nil, "%s", "test")
+	if e.Goroutines != 0 {
+		t.Errorf("Expected ERROR event to omit the goroutine count, but saw %d instead", e.Goroutines)
+	}
+}
+
 func TestEventStack(t *testing.T) {
 	e := &Event{}
 	e.captureFrames(1, 2, 2, false)
@@ -47,3 +60,66 @@ func TestEventStack(t *testing.T) {
 		t.Error("Expected Event.Frames to return nil when no frames are captured")
 	}
 }
+
+func TestEventClone(t *testing.T) {
+	e := newEvent(NewContext("test"), FATAL, nil, "test")
+	e.SetMeta("k1", "v1")
+
+	clone := e.Clone()
+	if clone == e {
+		t.Fatal("Expected Clone to return a new event, but it returned the original")
+	}
+	if clone.Message != e.Message || clone.Level != e.Level || clone.Goroutines != e.Goroutines || !clone.Time.Equal(e.Time) {
+		t.Errorf("Expected clone to match the original event, but it didn't.  Original: %#v, Clone: %#v", e, clone)
+	}
+
+	clone.SetMeta("k1", "changed")
+	clone.SetMeta("k2", "v2")
+	if value, _ := e.GetMeta("k1"); value != "v1" {
+		t.Errorf("Expected original meta value to be unaffected by the clone, but saw %v instead", value)
+	}
+	if _, present := e.GetMeta("k2"); present {
+		t.Error("Expected meta added to the clone to be absent from the original, but it's present")
+	}
+}
+
+func TestEventFields(t *testing.T) {
+	defer resetCue()
+	c := newCapturingCollector()
+	Collect(DEBUG, c)
+
+	log := NewLogger("test").WithValue("k1", "v1")
+	log.Info("test")
+	log = log.WithValue("k1", "changed").WithValue("k2", "v2")
+	log.Info("test")
+
+	event := c.Captured()[0]
+	expected := Fields{"k1": "v1"}
+	fields := event.Fields()
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("Expected event fields %v, but saw %v instead", expected, fields)
+	}
+
+	fields["k3"] = "v3"
+	delete(fields, "k1")
+	if !reflect.DeepEqual(event.Fields(), expected) {
+		t.Errorf("Expected event fields to be unaffected by modifying the returned map, but saw %v instead", event.Fields())
+	}
+
+	if len((&Event{}).Fields()) != 0 {
+		t.Error("Expected an event without a context to return empty fields")
+	}
+}
+
+func TestEventMeta(t *testing.T) {
+	e := &Event{}
+	if _, present := e.GetMeta("missing"); present {
+		t.Error("Expected missing meta key to be absent, but it's present")
+	}
+
+	e.SetMeta("partition", 3)
+	value, present := e.GetMeta("partition")
+	if !present || value != 3 {
+		t.Errorf("Expected meta value 3 to be present, but saw %v (present: %t) instead", value, present)
+	}
+}
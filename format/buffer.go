@@ -23,12 +23,22 @@ package format
 import (
 	"errors"
 	"sync"
+	"sync/atomic"
 	"unicode/utf8"
 )
 
 var (
-	errBarrier = errors.New("cue/format: write attempted on a buffer that was previously read")
-	pool       = newPool()
+	errBarrier         = errors.New("cue/format: write attempted on a buffer that was previously read")
+	errDoubleRelease   = errors.New("cue/format: buffer released more than once")
+	errUseAfterRelease = errors.New("cue/format: buffer used after it was released")
+	pool               = newPool()
+)
+
+// Pool debugging state.  See SetPoolDebug.
+var (
+	poolDebug   int32 // Accessed atomically
+	debugMu     sync.Mutex
+	outstanding = make(map[*debugBuffer]struct{})
 )
 
 // Using a buffer pool brought basic benchmark runs down from 400016 ns/op to
@@ -90,7 +100,15 @@ type buffer struct {
 // GetBuffer returns an empty buffer from a pool of Buffers.  A corresponding
 // "defer ReleaseBuffer()" should be used to free the buffer when finished.
 func GetBuffer() Buffer {
-	return pool.get()
+	if atomic.LoadInt32(&poolDebug) == 0 {
+		return pool.get()
+	}
+
+	debugMu.Lock()
+	defer debugMu.Unlock()
+	buffer := &debugBuffer{Buffer: pool.get()}
+	outstanding[buffer] = struct{}{}
+	return buffer
 }
 
 // ReleaseBuffer returns a buffer to the buffer pool.  Failing to release the
@@ -98,7 +116,43 @@ func GetBuffer() Buffer {
 // However, as of Go 1.6, there's a significant performance gain in pooling and
 // reusing Buffer instances.
 func ReleaseBuffer(buffer Buffer) {
-	pool.put(buffer)
+	debug, ok := buffer.(*debugBuffer)
+	if !ok {
+		pool.put(buffer)
+		return
+	}
+
+	debugMu.Lock()
+	defer debugMu.Unlock()
+	if debug.released {
+		panic(errDoubleRelease)
+	}
+	debug.released = true
+	delete(outstanding, debug)
+	pool.put(debug.Buffer)
+}
+
+// SetPoolDebug enables or disables buffer pool debugging.  This is a
+// diagnostics aid for authors of custom formatters.  While enabled, GetBuffer
+// tracks the buffers it returns, ReleaseBuffer panics if a buffer is released
+// more than once, and any use of a buffer after it's released panics.
+// OutstandingBuffers reports the number of tracked buffers that haven't been
+// released, which helps detect missing ReleaseBuffer calls.  Pool debugging
+// adds locking overhead and should not be enabled in production.
+func SetPoolDebug(enabled bool) {
+	if enabled {
+		atomic.StoreInt32(&poolDebug, 1)
+	} else {
+		atomic.StoreInt32(&poolDebug, 0)
+	}
+}
+
+// OutstandingBuffers returns the number of buffers obtained via GetBuffer
+// while pool debugging was enabled that have not yet been released.
+func OutstandingBuffers() int {
+	debugMu.Lock()
+	defer debugMu.Unlock()
+	return len(outstanding)
 }
 
 // newBuffer creates a new buffer instance.  Currently, the initialized
@@ -159,3 +213,54 @@ func (b *buffer) ensureCapacity(size int) {
 		b.bytes = new
 	}
 }
+
+// debugBuffer wraps pooled buffers while pool debugging is enabled.  The
+// wrapper itself is never pooled, so it's safe to check for use after release.
+type debugBuffer struct {
+	Buffer
+	released bool // Guarded by debugMu
+}
+
+func (b *debugBuffer) check() {
+	debugMu.Lock()
+	released := b.released
+	debugMu.Unlock()
+	if released {
+		panic(errUseAfterRelease)
+	}
+}
+
+func (b *debugBuffer) Bytes() []byte {
+	b.check()
+	return b.Buffer.Bytes()
+}
+
+func (b *debugBuffer) Len() int {
+	b.check()
+	return b.Buffer.Len()
+}
+
+func (b *debugBuffer) Reset() {
+	b.check()
+	b.Buffer.Reset()
+}
+
+func (b *debugBuffer) Append(value []byte) {
+	b.check()
+	b.Buffer.Append(value)
+}
+
+func (b *debugBuffer) AppendByte(value byte) {
+	b.check()
+	b.Buffer.AppendByte(value)
+}
+
+func (b *debugBuffer) AppendRune(value rune) {
+	b.check()
+	b.Buffer.AppendRune(value)
+}
+
+func (b *debugBuffer) AppendString(value string) {
+	b.check()
+	b.Buffer.AppendString(value)
+}
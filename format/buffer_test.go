@@ -148,3 +148,66 @@ func TestReleaseBuffer(t *testing.T) {
 	buf := GetBuffer()
 	ReleaseBuffer(buf)
 }
+
+func TestPoolDebug(t *testing.T) {
+	SetPoolDebug(true)
+	defer SetPoolDebug(false)
+
+	before := OutstandingBuffers()
+	buf := GetBuffer()
+	buf.AppendString("test")
+	if OutstandingBuffers() != before+1 {
+		t.Errorf("Expected %d outstanding buffers, but saw %d instead", before+1, OutstandingBuffers())
+	}
+	if string(buf.Bytes()) != "test" {
+		t.Errorf("Expected debug buffer to contain %q, but saw %q instead", "test", buf.Bytes())
+	}
+
+	ReleaseBuffer(buf)
+	if OutstandingBuffers() != before {
+		t.Errorf("Expected %d outstanding buffers, but saw %d instead", before, OutstandingBuffers())
+	}
+}
+
+func TestPoolDebugDoubleRelease(t *testing.T) {
+	SetPoolDebug(true)
+	defer SetPoolDebug(false)
+
+	buf := GetBuffer()
+	ReleaseBuffer(buf)
+	checkPanics(t, errDoubleRelease, func() {
+		ReleaseBuffer(buf)
+	})
+}
+
+func TestPoolDebugUseAfterRelease(t *testing.T) {
+	SetPoolDebug(true)
+	defer SetPoolDebug(false)
+
+	buf := GetBuffer()
+	ReleaseBuffer(buf)
+	checkPanics(t, errUseAfterRelease, func() {
+		buf.AppendString("test")
+	})
+	checkPanics(t, errUseAfterRelease, func() {
+		buf.Bytes()
+	})
+}
+
+func TestPoolDebugDisabled(t *testing.T) {
+	buf := GetBuffer()
+	if _, ok := buf.(*debugBuffer); ok {
+		t.Error("Expected a plain buffer while pool debugging is disabled, but received a debug buffer")
+	}
+	ReleaseBuffer(buf)
+}
+
+func checkPanics(t *testing.T, expected error, fn func()) {
+	defer func() {
+		cause := recover()
+		if cause != expected {
+			t.Errorf("Expected panic with %v, but recovered %v instead", expected, cause)
+		}
+	}()
+	fn()
+}
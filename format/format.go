@@ -21,20 +21,34 @@
 package format
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"github.com/bobziuchkovski/cue"
+	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 	"unicode"
+	"unicode/utf8"
+)
+
+// The command name and command line are determined once and cached.
+var (
+	commandOnce sync.Once
+	commandName string
+	commandLine string
 )
 
 // Color codes for use with Colorize.
 const (
+	reset  = 0
 	red    = 31
 	green  = 32
 	yellow = 33
@@ -51,10 +65,37 @@ var (
 	HumanReadable       = Join(" ", Time(time.Stamp), Level, SourceWithLine, HumanMessage)
 	HumanReadableColors = Colorize(HumanReadable)
 
+	// Same as HumanReadable, but only the level is colored.
+	HumanReadableLevelColors = Join(" ", Time(time.Stamp), Colorize(Level), SourceWithLine, HumanMessage)
+
 	// Message[: Error] {"key1":"val1","key2":"val2"}
 	JSONMessage = Join(" ", Escape(Trim(MessageWithError)), JSONContext)
+
+	// {"time":"...","level":"INFO","message":"...","fields":{"key1":"val1"}}
+	// See JSON for details.
+	JSONEvent = JSON(JSONOptions{})
+
+	// {"version":"1.1","host":"...","short_message":"...","level":6,...}
+	// See GELFHost for details.
+	GELF = GELFHost("")
+
+	// ISO8601/RFC3339 timestamps in the event's location, with second and
+	// millisecond precision, respectively.
+	// 2006-01-02T15:04:05-07:00
+	// 2006-01-02T15:04:05.000-07:00
+	ISO8601       = Time(time.RFC3339)
+	ISO8601Millis = Time(iso8601Millis)
+
+	// Same as above, but converted to UTC.
+	// 2006-01-02T22:04:05Z
+	// 2006-01-02T22:04:05.000Z
+	ISO8601UTC       = TimeUTC(time.RFC3339)
+	ISO8601MillisUTC = TimeUTC(iso8601Millis)
 )
 
+// RFC3339 layout with fixed millisecond precision.
+const iso8601Millis = "2006-01-02T15:04:05.000Z07:00"
+
 // Formatter is the interface used to format Collector output.
 type Formatter func(buffer Buffer, event *cue.Event)
 
@@ -103,24 +144,90 @@ func Join(sep string, formatters ...Formatter) Formatter {
 	}
 }
 
+// CSV returns a new Formatter that renders the contents of the underlying
+// formatters as a single CSV row terminated by CRLF.  Each formatter's output
+// is one field, which is quoted if it contains a comma, double quote, or line
+// break, per RFC 4180.  Unlike Join, empty output is written as an empty
+// field so that columns remain aligned.  See CSVHeader for emitting column
+// names.
+func CSV(formatters ...Formatter) Formatter {
+	return func(buffer Buffer, event *cue.Event) {
+		tmp := GetBuffer()
+		defer ReleaseBuffer(tmp)
+
+		for i, formatter := range formatters {
+			formatter(tmp, event)
+			if i > 0 {
+				buffer.AppendRune(',')
+			}
+			writeCSVField(buffer, tmp.Bytes())
+			tmp.Reset()
+		}
+		buffer.AppendString("\r\n")
+	}
+}
+
+// CSVHeader returns a new Formatter that writes a CSV row of the given column
+// names ahead of the first event rendered by formatter, which is typically
+// created via CSV.  Subsequent events are rendered by formatter alone.  The
+// header is written once per CSVHeader formatter, so a new one should be
+// created for each output destination.
+func CSVHeader(names []string, formatter Formatter) Formatter {
+	var written uint32
+	return func(buffer Buffer, event *cue.Event) {
+		if atomic.CompareAndSwapUint32(&written, 0, 1) {
+			for i, name := range names {
+				if i > 0 {
+					buffer.AppendRune(',')
+				}
+				writeCSVField(buffer, []byte(name))
+			}
+			buffer.AppendString("\r\n")
+		}
+		formatter(buffer, event)
+	}
+}
+
+func writeCSVField(buffer Buffer, field []byte) {
+	if !bytes.ContainsAny(field, ",\"\r\n") {
+		buffer.Append(field)
+		return
+	}
+	buffer.AppendRune('"')
+	for _, b := range field {
+		if b == '"' {
+			buffer.AppendByte('"')
+		}
+		buffer.AppendByte(b)
+	}
+	buffer.AppendRune('"')
+}
+
 // Formatf provides printf-like formatting of source formatters. The "%v"
 // placeholder is used to specify formatter placeholders.  In the rare event
-// a literal "%v" is required, "%%v" renders the literal.  No alignment,
-// padding, or other printf constructs are currently supported, though code
-// contributions are certainly welcome.
+// a literal "%v" is required, "%%v" renders the literal.
+//
+// Placeholders may specify a minimum width, as in "%10v", in which case the
+// formatter output is padded with spaces on the left.  The "-" flag, as in
+// "%-10v", pads on the right instead.  Thus Formatf("%-5v %v", Level, Message)
+// aligns messages for all levels.  Output is measured in runes, including any
+// escape sequences, so padded output should be colorized from the outside:
+// Colorize(Formatf("%-5v", Level)).  Output that meets or exceeds the width is
+// written unaltered.  Other printf constructs aren't supported.  Malformed or
+// unsupported placeholders, such as "%d", are rendered literally.
 func Formatf(format string, formatters ...Formatter) Formatter {
 	formatterIdx := 0
 	segments := splitFormat(format)
 	chain := make([]Formatter, len(segments))
 	for i, seg := range segments {
 		switch {
-		case seg == "%v" && formatterIdx < len(formatters):
-			chain[i] = formatters[formatterIdx]
+		case seg.placeholder && formatterIdx < len(formatters):
+			chain[i] = pad(formatters[formatterIdx], seg.width, seg.left)
 			formatterIdx++
-		case seg == "%v":
+		case seg.placeholder:
 			chain[i] = Literal("%!v(MISSING)")
 		default:
-			chain[i] = Literal(seg)
+			chain[i] = Literal(seg.literal)
 		}
 	}
 
@@ -131,58 +238,163 @@ func Formatf(format string, formatters ...Formatter) Formatter {
 	}
 }
 
-func splitFormat(format string) []string {
+// Widths beyond this are treated as malformed placeholders.
+const maxFormatWidth = 1024
+
+// formatSegment is either a literal string or a placeholder with an optional
+// minimum width.
+type formatSegment struct {
+	literal     string
+	placeholder bool
+	width       int
+	left        bool
+}
+
+func splitFormat(format string) []formatSegment {
 	var (
-		segments []string
-		segstart int
-		lastrune rune
+		segments []formatSegment
+		literal  []rune
 	)
+	flush := func() {
+		if len(literal) > 0 {
+			segments = append(segments, formatSegment{literal: string(literal)})
+			literal = nil
+		}
+	}
 
 	runes := []rune(format)
-	for i, r := range runes {
-		switch {
-		case lastrune == '%' && r == '%':
-			segend := i - 1
-			if segstart != segend {
-				segments = append(segments, string(runes[segstart:segend]))
-			}
-			segments = append(segments, "%")
-			segstart = i + 1
-			lastrune = 0
-		case lastrune == '%' && r == 'v':
-			segend := i - 1
-			if segstart != segend {
-				segments = append(segments, string(runes[segstart:segend]))
+	for i := 0; i < len(runes); {
+		if runes[i] != '%' {
+			literal = append(literal, runes[i])
+			i++
+			continue
+		}
+		if i+1 < len(runes) && runes[i+1] == '%' {
+			literal = append(literal, '%')
+			i += 2
+			continue
+		}
+
+		// Parse an optional "-" flag and width, followed by the 'v' verb
+		j := i + 1
+		left := false
+		if j < len(runes) && runes[j] == '-' {
+			left = true
+			j++
+		}
+		digits := j
+		for j < len(runes) && runes[j] >= '0' && runes[j] <= '9' {
+			j++
+		}
+		if j >= len(runes) || runes[j] != 'v' {
+			literal = append(literal, '%')
+			i++
+			continue
+		}
+
+		width := 0
+		if j > digits {
+			var err error
+			width, err = strconv.Atoi(string(runes[digits:j]))
+			if err != nil || width > maxFormatWidth {
+				literal = append(literal, runes[i:j+1]...)
+				i = j + 1
+				continue
 			}
-			segments = append(segments, "%v")
-			segstart = i + 1
-			lastrune = r
-		default:
-			lastrune = r
 		}
+		flush()
+		segments = append(segments, formatSegment{placeholder: true, width: width, left: left})
+		i = j + 1
+	}
+	flush()
+	return segments
+}
+
+// pad returns a formatter that pads the output of formatter with spaces to
+// the given minimum width.
+func pad(formatter Formatter, width int, left bool) Formatter {
+	if width <= 0 {
+		return formatter
 	}
+	return func(buffer Buffer, event *cue.Event) {
+		tmp := GetBuffer()
+		defer ReleaseBuffer(tmp)
 
-	if segstart < len(runes) {
-		segments = append(segments, string(runes[segstart:]))
+		formatter(tmp, event)
+		padding := width - utf8.RuneCount(tmp.Bytes())
+		if padding <= 0 {
+			buffer.Append(tmp.Bytes())
+			return
+		}
+		if left {
+			buffer.Append(tmp.Bytes())
+			buffer.AppendString(strings.Repeat(" ", padding))
+			return
+		}
+		buffer.AppendString(strings.Repeat(" ", padding))
+		buffer.Append(tmp.Bytes())
 	}
-	return segments
 }
 
 // Colorize returns a new formatter that wraps the underlying formatter output
-// in color escape codes by level: DEBUG output is blue, INFO output is green,
-// WARN output is yellow, and ERROR/FATAL output is red.  No additional color
+// in color escape codes by level: TRACE/DEBUG output is blue, INFO output is
+// green, WARN output is yellow, and ERROR/FATAL output is red.  No additional color
 // support is provided, nor will any be added.
+//
+// To color only part of the output, such as the level, wrap just that part
+// and compose the rest as usual:
+//
+//	Join(" ", Time(time.Stamp), Colorize(Level), HumanMessage)
+//
+// HumanReadableLevelColors is pre-defined in this manner.  Colorize may be
+// nested: the color is re-applied after any reset written by the underlying
+// formatter, so output following a nested Colorize remains colored.  Colorize
+// always writes escape codes; use ColorizeIfTerminal to only color terminal
+// output.
 func Colorize(formatter Formatter) Formatter {
 	return func(buffer Buffer, event *cue.Event) {
-		buffer.AppendString(fmt.Sprintf("\x1b[%dm", colorFor(event.Level)))
-		formatter(buffer, event)
-		buffer.AppendString("\x1b[0m")
+		tmp := GetBuffer()
+		defer ReleaseBuffer(tmp)
+
+		color := fmt.Sprintf("\x1b[%dm", colorFor(event.Level))
+		resetCode := fmt.Sprintf("\x1b[%dm", reset)
+		formatter(tmp, event)
+		buffer.AppendString(color)
+		buffer.AppendString(strings.Replace(string(tmp.Bytes()), resetCode, resetCode+color, -1))
+		buffer.AppendString(resetCode)
+	}
+}
+
+// ColorizeIfTerminal returns Colorize(formatter) if f is a terminal, and
+// formatter unaltered otherwise.  This avoids polluting redirected logs and
+// CI output with color escape codes.  The check is performed once, when
+// ColorizeIfTerminal is called.  For example:
+//
+//	ColorizeIfTerminal(HumanReadable, os.Stdout)
+func ColorizeIfTerminal(formatter Formatter, f *os.File) Formatter {
+	if IsTerminal(f) {
+		return Colorize(formatter)
+	}
+	return formatter
+}
+
+// IsTerminal reports whether f refers to a terminal (character device).  It's
+// used to avoid writing color escape codes to files and pipes.  Note that
+// other character devices, such as /dev/null, are reported as terminals too.
+func IsTerminal(f *os.File) bool {
+	if f == nil {
+		return false
 	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
 }
 
 func colorFor(lvl cue.Level) int {
 	switch lvl {
-	case cue.DEBUG:
+	case cue.TRACE, cue.DEBUG:
 		return blue
 	case cue.INFO:
 		return green
@@ -207,6 +419,30 @@ func Trim(formatter Formatter) Formatter {
 	}
 }
 
+// Upper returns a formatter that converts the input formatter's output to
+// upper case.
+func Upper(formatter Formatter) Formatter {
+	return func(buffer Buffer, event *cue.Event) {
+		tmp := GetBuffer()
+		defer ReleaseBuffer(tmp)
+
+		formatter(tmp, event)
+		buffer.AppendString(strings.ToUpper(string(tmp.Bytes())))
+	}
+}
+
+// Lower returns a formatter that converts the input formatter's output to
+// lower case.  Hence Lower(Level) writes "info" for INFO level messages.
+func Lower(formatter Formatter) Formatter {
+	return func(buffer Buffer, event *cue.Event) {
+		tmp := GetBuffer()
+		defer ReleaseBuffer(tmp)
+
+		formatter(tmp, event)
+		buffer.AppendString(strings.ToLower(string(tmp.Bytes())))
+	}
+}
+
 // Escape returns a formatter that escapes all control characters and all
 // whitespace characters other than ' ' (ASCII space) from the input formatter.
 func Escape(formatter Formatter) Formatter {
@@ -230,6 +466,79 @@ func Escape(formatter Formatter) Formatter {
 	}
 }
 
+// StripANSI returns a formatter that removes ANSI escape sequences from the
+// input formatter's output.  This prevents user-provided messages and context
+// values from manipulating terminal output via color codes, cursor movement,
+// and similar escape sequences.  Terminal formats that render untrusted input
+// should be wrapped with StripANSI.  When combined with Colorize, StripANSI
+// must wrap the inner formatter, not the colorized output:
+//
+//	Colorize(StripANSI(HumanReadable))
+func StripANSI(formatter Formatter) Formatter {
+	return func(buffer Buffer, event *cue.Event) {
+		tmp := GetBuffer()
+		defer ReleaseBuffer(tmp)
+
+		formatter(tmp, event)
+		runes := []rune(string(tmp.Bytes()))
+		for i := 0; i < len(runes); i++ {
+			switch {
+			case runes[i] == '\x9b':
+				i = skipCSI(runes, i+1)
+			case runes[i] != '\x1b':
+				buffer.AppendRune(runes[i])
+			case i+1 >= len(runes):
+				// Drop the trailing escape character
+			case runes[i+1] == '[':
+				i = skipCSI(runes, i+2)
+			case runes[i+1] == ']':
+				i = skipOSC(runes, i+2)
+			case runes[i+1] >= 0x20 && runes[i+1] <= 0x7e:
+				i = skipEscape(runes, i+1)
+			}
+		}
+	}
+}
+
+// skipCSI returns the index of the final rune for the control sequence that
+// starts at runes[start].  Control sequences are terminated by a rune in the
+// range 0x40 to 0x7e.
+func skipCSI(runes []rune, start int) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] >= 0x40 && runes[i] <= 0x7e {
+			return i
+		}
+	}
+	return len(runes)
+}
+
+// skipEscape returns the index of the final rune for the escape sequence that
+// starts at runes[start].  Escape sequences consist of zero or more runes in
+// the range 0x20 to 0x2f followed by a final rune in the range 0x30 to 0x7e.
+func skipEscape(runes []rune, start int) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] >= 0x30 && runes[i] <= 0x7e {
+			return i
+		}
+	}
+	return len(runes)
+}
+
+// skipOSC returns the index of the final rune for the operating system
+// command that starts at runes[start].  Operating system commands are
+// terminated by BEL or by ESC followed by a backslash.
+func skipOSC(runes []rune, start int) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] == '\a' {
+			return i
+		}
+		if runes[i] == '\x1b' && i+1 < len(runes) && runes[i+1] == '\\' {
+			return i + 1
+		}
+	}
+	return len(runes)
+}
+
 // Truncate returns a new formatter that truncates the input formatter after
 // length bytes are written.
 func Truncate(formatter Formatter, length int) Formatter {
@@ -246,6 +555,190 @@ func Truncate(formatter Formatter, length int) Formatter {
 	}
 }
 
+// TruncateValues returns a new formatter that runs the input formatter on a
+// copy of the event whose context values are limited to max runes.  Longer
+// values are truncated, with their final rune replaced by an ellipsis ("…"),
+// and are passed to the formatter as strings.  Unlike Truncate, this bounds
+// the size of oversized fields, such as stack dumps stored in the context,
+// while keeping structured output like JSON valid.  If max is less than 1,
+// the input formatter is returned as-is.
+func TruncateValues(formatter Formatter, max int) Formatter {
+	if max < 1 {
+		return formatter
+	}
+	return func(buffer Buffer, event *cue.Event) {
+		truncated := make(cue.Fields)
+		event.Context.Each(func(key string, value interface{}) {
+			s, ok := value.(string)
+			if !ok {
+				s = fmt.Sprint(value)
+			}
+			runes := []rune(s)
+			if len(runes) > max {
+				truncated[key] = string(runes[:max-1]) + "…"
+			}
+		})
+		if len(truncated) == 0 {
+			formatter(buffer, event)
+			return
+		}
+
+		clone := event.Clone()
+		clone.Context = cue.FilterContext(event.Context, func(key string, value interface{}) bool {
+			_, present := truncated[key]
+			return present
+		}).WithFields(truncated)
+		formatter(buffer, clone)
+	}
+}
+
+// Align specifies the alignment of content within a Column.
+type Align int
+
+// Column alignments.
+const (
+	AlignLeft Align = iota
+	AlignRight
+	AlignCenter
+)
+
+// Column returns a new formatter that writes the output of the input
+// formatter as exactly width runes.  Longer output is truncated, with its
+// final rune replaced by an ellipsis ("…").  Shorter output is padded with
+// spaces according to align.  With AlignCenter, any odd padding rune is
+// placed on the right.  Column is useful for rendering aligned terminal
+// output.  If width is less than 1, nothing is written.
+func Column(formatter Formatter, width int, align Align) Formatter {
+	return func(buffer Buffer, event *cue.Event) {
+		if width < 1 {
+			return
+		}
+		tmp := GetBuffer()
+		defer ReleaseBuffer(tmp)
+
+		formatter(tmp, event)
+		runes := []rune(string(tmp.Bytes()))
+		if len(runes) > width {
+			buffer.AppendString(string(runes[:width-1]))
+			buffer.AppendRune('…')
+			return
+		}
+
+		padding := width - len(runes)
+		var left, right int
+		switch align {
+		case AlignRight:
+			left = padding
+		case AlignCenter:
+			left = padding / 2
+			right = padding - left
+		default:
+			right = padding
+		}
+		buffer.AppendString(strings.Repeat(" ", left))
+		buffer.Append(tmp.Bytes())
+		buffer.AppendString(strings.Repeat(" ", right))
+	}
+}
+
+// Default returns a new formatter that writes the output of the input
+// formatter, or fallback if the input formatter doesn't write any bytes.
+// This is useful for rendering placeholders, such as for SourceWithLine
+// when frame collection is disabled.
+func Default(formatter Formatter, fallback string) Formatter {
+	return func(buffer Buffer, event *cue.Event) {
+		tmp := GetBuffer()
+		defer ReleaseBuffer(tmp)
+
+		formatter(tmp, event)
+		if tmp.Len() == 0 {
+			buffer.AppendString(fallback)
+			return
+		}
+		buffer.Append(tmp.Bytes())
+	}
+}
+
+// Prefix returns a new formatter that writes prefix followed by the output of
+// the input formatter.  If the input formatter doesn't write any bytes,
+// nothing is written.  This is useful for rendering separators and labels
+// around optional values, such as Prefix(Error, "error=").
+func Prefix(formatter Formatter, prefix string) Formatter {
+	return func(buffer Buffer, event *cue.Event) {
+		tmp := GetBuffer()
+		defer ReleaseBuffer(tmp)
+
+		formatter(tmp, event)
+		if tmp.Len() == 0 {
+			return
+		}
+		buffer.AppendString(prefix)
+		buffer.Append(tmp.Bytes())
+	}
+}
+
+// Suffix returns a new formatter that writes the output of the input
+// formatter followed by suffix.  If the input formatter doesn't write any
+// bytes, nothing is written.
+func Suffix(formatter Formatter, suffix string) Formatter {
+	return func(buffer Buffer, event *cue.Event) {
+		tmp := GetBuffer()
+		defer ReleaseBuffer(tmp)
+
+		formatter(tmp, event)
+		if tmp.Len() == 0 {
+			return
+		}
+		buffer.Append(tmp.Bytes())
+		buffer.AppendString(suffix)
+	}
+}
+
+// WhenField returns a new formatter that writes the output of the then
+// formatter only if the event context contains key and its value, as
+// stringified by fmt.Sprint, equals want.  Otherwise nothing is written.
+// This is useful for rendering markers driven by context values, such as
+// WhenField("slow", "true", Literal("SLOW")).
+func WhenField(key string, want string, then Formatter) Formatter {
+	return func(buffer Buffer, event *cue.Event) {
+		var value interface{}
+		present := false
+		event.Context.Each(func(k string, v interface{}) {
+			if k == key {
+				value, present = v, true
+			}
+		})
+		if present && fmt.Sprint(value) == want {
+			then(buffer, event)
+		}
+	}
+}
+
+// ByLevel returns a new formatter that dispatches to the formatter mapped to
+// the event's level in formatters.  Events with unmapped levels, or levels
+// mapped to nil, are written by defaultFormatter.  If defaultFormatter is nil,
+// nothing is written for them.  This allows a single collector to produce
+// level-appropriate output, such as colorized human-readable output for DEBUG
+// and INFO events and terse single-line output for WARN and above.  The map is
+// copied, so later changes to it don't affect the returned formatter.
+func ByLevel(formatters map[cue.Level]Formatter, defaultFormatter Formatter) Formatter {
+	byLevel := make(map[cue.Level]Formatter, len(formatters))
+	for level, formatter := range formatters {
+		if formatter != nil {
+			byLevel[level] = formatter
+		}
+	}
+	return func(buffer Buffer, event *cue.Event) {
+		formatter, ok := byLevel[event.Level]
+		if !ok {
+			formatter = defaultFormatter
+		}
+		if formatter != nil {
+			formatter(buffer, event)
+		}
+	}
+}
+
 // Literal returns a formatter that always writes s to its buffer.
 func Literal(s string) Formatter {
 	return func(buffer Buffer, event *cue.Event) {
@@ -261,6 +754,24 @@ func Time(timeFormat string) Formatter {
 	}
 }
 
+// TimeUTC is the same as Time, but converts the event's timestamp to UTC
+// prior to formatting.  This is useful when aggregating logs from hosts in
+// multiple time zones.
+func TimeUTC(timeFormat string) Formatter {
+	return TimeIn(time.UTC, timeFormat)
+}
+
+// TimeIn is the same as Time, but converts the event's timestamp to the
+// given location prior to formatting.  If loc is nil, UTC is used.
+func TimeIn(loc *time.Location, timeFormat string) Formatter {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return func(buffer Buffer, event *cue.Event) {
+		buffer.AppendString(event.Time.In(loc).Format(timeFormat))
+	}
+}
+
 // Hostname writes the host's short name to the buffer, domain excluded.
 // If the hostname cannot be determined, "unknown" is written instead.
 func Hostname(buffer Buffer, event *cue.Event) {
@@ -285,6 +796,43 @@ func FQDN(buffer Buffer, event *cue.Event) {
 	buffer.AppendString(name)
 }
 
+// Command writes the base name of the running program, as determined by
+// os.Args[0], to the buffer.  This is useful for distinguishing the output of
+// multiple programs that log to a shared destination.  If the program name
+// cannot be determined, "unknown" is written instead.
+func Command(buffer Buffer, event *cue.Event) {
+	commandOnce.Do(loadCommand)
+	buffer.AppendString(commandName)
+}
+
+// CommandLine writes the full command line of the running program, as
+// determined by os.Args, to the buffer.  Arguments are separated by spaces and
+// arguments containing spaces, quotes, or control characters are quoted using
+// strconv.Quote.
+func CommandLine(buffer Buffer, event *cue.Event) {
+	commandOnce.Do(loadCommand)
+	buffer.AppendString(commandLine)
+}
+
+func loadCommand() {
+	commandName = "unknown"
+	if len(os.Args) == 0 || os.Args[0] == "" {
+		commandLine = commandName
+		return
+	}
+	commandName = filepath.Base(os.Args[0])
+
+	tmp := GetBuffer()
+	defer ReleaseBuffer(tmp)
+	for i, arg := range os.Args {
+		if i > 0 {
+			tmp.AppendRune(' ')
+		}
+		writeHumanValue(tmp, arg)
+	}
+	commandLine = string(tmp.Bytes())
+}
+
 // Level writes event.Level.String() to the buffer.  Hence, it writes "INFO"
 // for INFO level messages, "DEBUG" for DEBUG level messages, and so on.
 func Level(buffer Buffer, event *cue.Event) {
@@ -351,6 +899,34 @@ func Line(buffer Buffer, event *cue.Event) {
 	buffer.AppendString(fmt.Sprintf("%d", event.Frames[0].Line))
 }
 
+// StackString writes all of the event's frames as a newline-joined string,
+// innermost frame first.  Each line has the form "function (file:line)".
+// The result contains no trailing newline, which makes it suitable for
+// embedding as a single string value, such as a flat "stack" field in JSON
+// output.  If frame collection is disabled, nothing is written.
+func StackString(buffer Buffer, event *cue.Event) {
+	for i, frame := range event.Frames {
+		if i > 0 {
+			buffer.AppendByte('\n')
+		}
+		buffer.AppendString(frame.Function)
+		buffer.AppendString(" (")
+		buffer.AppendString(frame.File)
+		buffer.AppendRune(':')
+		buffer.AppendString(strconv.Itoa(frame.Line))
+		buffer.AppendRune(')')
+	}
+}
+
+// SampleRate writes the event's sampling rate, e.g. "10" for events that were
+// sampled 1-in-10.  Nothing is written for unsampled events.
+func SampleRate(buffer Buffer, event *cue.Event) {
+	if event.SampleRate <= 0 {
+		return
+	}
+	buffer.AppendString(strconv.Itoa(event.SampleRate))
+}
+
 // Message writes event.Message to the buffer.
 func Message(buffer Buffer, event *cue.Event) {
 	buffer.AppendString(event.Message)
@@ -388,6 +964,17 @@ func MessageWithError(buffer Buffer, event *cue.Event) {
 	}
 }
 
+// Diagnostics writes diagnostic values captured for FATAL events in key=value
+// format.  Currently, this is limited to the number of goroutines that were
+// running when the event was generated ("goroutines=N").  Nothing is written
+// for events that lack diagnostic values.
+func Diagnostics(buffer Buffer, event *cue.Event) {
+	if event.Goroutines <= 0 {
+		return
+	}
+	buffer.AppendString(fmt.Sprintf("goroutines=%d", event.Goroutines))
+}
+
 // SourceWithLine writes ShortFile, followed by ":" and Line.  If these cannot
 // be determined or frame collection is disabled, nothing is written.
 func SourceWithLine(buffer Buffer, event *cue.Event) {
@@ -411,8 +998,43 @@ func ContextName(buffer Buffer, event *cue.Event) {
 // but suppresses quotes on values that don't contain spaces, quotes, or
 // control characters.  Other values are quoted using strconv.Quote.
 func HumanContext(buffer Buffer, event *cue.Event) {
-	fields := event.Context.Fields()
+	writeHumanFields(buffer, event.Context.Fields())
+}
+
+// SelectFields returns a new formatter that writes only the named
+// event.Context key/value pairs, using the same key=value format as
+// HumanContext.  Keys missing from the context are skipped.
+func SelectFields(keys ...string) Formatter {
+	selected := make(map[string]bool)
+	for _, k := range keys {
+		selected[k] = true
+	}
+	return func(buffer Buffer, event *cue.Event) {
+		fields := event.Context.Fields()
+		for k := range fields {
+			if !selected[k] {
+				delete(fields, k)
+			}
+		}
+		writeHumanFields(buffer, fields)
+	}
+}
 
+// ExcludeFields returns a new formatter that writes the event.Context
+// key/value pairs, omitting the named keys, using the same key=value format
+// as HumanContext.  This is useful for keeping sensitive values, such as
+// auth tokens, out of specific outputs.
+func ExcludeFields(keys ...string) Formatter {
+	return func(buffer Buffer, event *cue.Event) {
+		fields := event.Context.Fields()
+		for _, k := range keys {
+			delete(fields, k)
+		}
+		writeHumanFields(buffer, fields)
+	}
+}
+
+func writeHumanFields(buffer Buffer, fields cue.Fields) {
 	// Sort field keys for predictable output ordering
 	var sortedKeys []string
 	for k := range fields {
@@ -454,14 +1076,465 @@ func writeHumanValue(buffer Buffer, v interface{}) {
 	buffer.AppendString(s)
 }
 
+// Logfmt writes the entire event in logfmt format: time, level, msg, error,
+// and source fields, followed by the event.Context key/value pairs sorted by
+// key.  The error and source fields are omitted if the event has no error or
+// if frame collection is disabled, respectively.  Values that contain spaces,
+// equals signs, quotes, or control characters are quoted using strconv.Quote.
+// Characters that are invalid for logfmt keys are replaced with underscores.
+//
+//	time=2006-01-02T15:04:05Z level=info msg="Message" source=file.go:42 key1=val1
+func Logfmt(buffer Buffer, event *cue.Event) {
+	writeLogfmtPair(buffer, "time", event.Time.Format(time.RFC3339))
+	buffer.AppendRune(' ')
+	writeLogfmtPair(buffer, "level", strings.ToLower(event.Level.String()))
+	buffer.AppendRune(' ')
+	writeLogfmtPair(buffer, "msg", event.Message)
+	if event.Error != nil {
+		buffer.AppendRune(' ')
+		writeLogfmtPair(buffer, "error", event.Error.Error())
+	}
+	if source := RenderString(SourceWithLine, event); source != "" {
+		buffer.AppendRune(' ')
+		writeLogfmtPair(buffer, "source", source)
+	}
+
+	fields := event.Context.Fields()
+	var sortedKeys []string
+	for k := range fields {
+		sortedKeys = append(sortedKeys, k)
+	}
+	sort.Strings(sortedKeys)
+
+	for _, k := range sortedKeys {
+		buffer.AppendRune(' ')
+		writeLogfmtPair(buffer, k, fields[k])
+	}
+}
+
+func writeLogfmtPair(buffer Buffer, key string, value interface{}) {
+	for _, r := range key {
+		if r <= ' ' || r == '=' || r == '"' || r == 0x7f || !unicode.IsPrint(r) {
+			r = '_'
+		}
+		buffer.AppendRune(r)
+	}
+	buffer.AppendRune('=')
+
+	s := fmt.Sprint(value)
+	special := func(r rune) bool {
+		return r <= ' ' || r == '=' || r == '"' || r == '\\' || !unicode.IsPrint(r)
+	}
+	if s == "" || strings.IndexFunc(s, special) >= 0 {
+		buffer.AppendString(strconv.Quote(s))
+		return
+	}
+	buffer.AppendString(s)
+}
+
 // JSONContext marshals the event.Context fields into JSON and writes the
-// result.
+// result.  time.Duration values are written as integer nanoseconds.  See
+// JSONContextWith for rendering durations in other units.
 func JSONContext(buffer Buffer, event *cue.Event) {
 	fields := event.Context.Fields()
 	marshaled, _ := json.Marshal(fields)
 	buffer.Append(marshaled)
 }
 
+// JSONContextWith returns a formatter that marshals the event.Context fields
+// into JSON, as with JSONContext, customized by opts.  Only the DurationUnit
+// option applies; Stack and Keys are ignored.
+func JSONContextWith(opts JSONOptions) Formatter {
+	return func(buffer Buffer, event *cue.Event) {
+		fields := durationFields(event.Context.Fields(), opts.DurationUnit)
+		marshaled, _ := json.Marshal(fields)
+		buffer.Append(marshaled)
+	}
+}
+
+// JSONOptions customizes the output of the JSON formatter.
+type JSONOptions struct {
+	// If Stack is set, the event's frames are rendered via StackString and
+	// included as a flat "stack" string.  This suits systems that index a
+	// single stack field rather than an array of frames.
+	Stack bool
+
+	// If DurationUnit is set, time.Duration context values are written as
+	// numeric counts of the unit, e.g. time.Millisecond renders 1.5s as 1500.
+	// Otherwise they're written as integer nanoseconds.
+	DurationUnit time.Duration
+
+	// Keys customizes the names of the keys written by the JSON formatter.
+	// This is useful for matching existing ingestion schemas, such as ECS or
+	// Google Cloud Logging.
+	Keys JSONKeys
+}
+
+// JSONKeys specifies the key names written by the JSON formatter.  Empty
+// names use the defaults shown below.  For example, the following renders
+// "@timestamp", "severity", and "msg" keys:
+//
+//	JSON(JSONOptions{Keys: JSONKeys{Time: "@timestamp", Level: "severity", Message: "msg"}})
+type JSONKeys struct {
+	Time       string // Default: "time"
+	Level      string // Default: "level"
+	Message    string // Default: "message"
+	Error      string // Default: "error"
+	File       string // Default: "file"
+	Line       string // Default: "line"
+	Function   string // Default: "function"
+	Stack      string // Default: "stack"
+	SampleRate string // Default: "sample_rate"
+	Fields     string // Default: "fields"
+}
+
+// withDefaults returns a copy of k with empty names replaced by defaults.
+func (k JSONKeys) withDefaults() JSONKeys {
+	set := func(name *string, def string) {
+		if *name == "" {
+			*name = def
+		}
+	}
+	set(&k.Time, "time")
+	set(&k.Level, "level")
+	set(&k.Message, "message")
+	set(&k.Error, "error")
+	set(&k.File, "file")
+	set(&k.Line, "line")
+	set(&k.Function, "function")
+	set(&k.Stack, "stack")
+	set(&k.SampleRate, "sample_rate")
+	set(&k.Fields, "fields")
+	return k
+}
+
+// DurationField returns a formatter that writes the event.Context value for
+// key as a numeric count of unit, e.g. DurationField("elapsed",
+// time.Millisecond) renders 1.5s as 1500.  Non-duration values are written
+// via fmt.Sprint, and nothing is written if the key is missing.
+func DurationField(key string, unit time.Duration) Formatter {
+	return func(buffer Buffer, event *cue.Event) {
+		value, present := event.Context.Fields()[key]
+		if !present {
+			return
+		}
+		d, ok := value.(time.Duration)
+		if !ok {
+			buffer.AppendString(fmt.Sprint(value))
+			return
+		}
+		buffer.AppendString(strconv.FormatFloat(durationIn(d, unit), 'f', -1, 64))
+	}
+}
+
+// durationFields returns fields with time.Duration values converted to
+// numeric counts of unit.  If unit is 0, fields is returned as-is.
+func durationFields(fields cue.Fields, unit time.Duration) cue.Fields {
+	if unit <= 0 {
+		return fields
+	}
+	for k, v := range fields {
+		if d, ok := v.(time.Duration); ok {
+			fields[k] = durationIn(d, unit)
+		}
+	}
+	return fields
+}
+
+func durationIn(d time.Duration, unit time.Duration) float64 {
+	if unit <= 0 {
+		unit = time.Nanosecond
+	}
+	return float64(d) / float64(unit)
+}
+
+// JSON returns a formatter that marshals the entire event into a single JSON
+// object.  Keys are written in the following order:
+//
+//	time         The event time in RFC3339 format
+//	level        The event level, e.g. "INFO"
+//	message      The event message
+//	error        The event error.  Omitted if the event's Error field is nil.
+//	file         The file of the logging call site.  Omitted if unknown.
+//	line         The line of the logging call site.  Omitted if unknown.
+//	function     The function of the logging call site.  Omitted if unknown.
+//	stack        See JSONOptions.Stack.  Omitted if disabled or if unknown.
+//	sample_rate  The event's sampling rate.  Omitted if unsampled.
+//	fields       The event context key/value pairs
+//
+// Context fields are nested under the "fields" key to avoid collisions with
+// the reserved keys above.  Context values that can't be marshaled to JSON
+// are written as strings.  No trailing newline is written.  The key names
+// may be customized via JSONOptions.Keys.
+func JSON(opts JSONOptions) Formatter {
+	keys := opts.Keys.withDefaults()
+	return func(buffer Buffer, event *cue.Event) {
+		buffer.AppendRune('{')
+		writeJSONPair(buffer, keys.Time, event.Time.Format(time.RFC3339), false)
+		writeJSONPair(buffer, keys.Level, event.Level.String(), true)
+		writeJSONPair(buffer, keys.Message, event.Message, true)
+		if event.Error != nil {
+			writeJSONPair(buffer, keys.Error, event.Error.Error(), true)
+		}
+		if len(event.Frames) > 0 {
+			frame := event.Frames[0]
+			writeJSONPair(buffer, keys.File, frame.File, true)
+			writeJSONPair(buffer, keys.Line, frame.Line, true)
+			writeJSONPair(buffer, keys.Function, frame.Function, true)
+			if opts.Stack {
+				writeJSONPair(buffer, keys.Stack, RenderString(StackString, event), true)
+			}
+		}
+		if event.SampleRate > 0 {
+			writeJSONPair(buffer, keys.SampleRate, event.SampleRate, true)
+		}
+		writeJSONPair(buffer, keys.Fields, jsonFields(durationFields(event.Context.Fields(), opts.DurationUnit)), true)
+		buffer.AppendRune('}')
+	}
+}
+
+func writeJSONPair(buffer Buffer, key string, value interface{}, needSep bool) {
+	if needSep {
+		buffer.AppendRune(',')
+	}
+	marshaledKey, _ := json.Marshal(key)
+	buffer.Append(marshaledKey)
+	buffer.AppendRune(':')
+
+	marshaled, err := json.Marshal(value)
+	if err != nil {
+		marshaled, _ = json.Marshal(fmt.Sprint(value))
+	}
+	buffer.Append(marshaled)
+}
+
+// jsonFields returns fields with unmarshalable values, such as complex
+// numbers, converted to strings.
+func jsonFields(fields cue.Fields) cue.Fields {
+	for k, v := range fields {
+		_, err := json.Marshal(v)
+		if err != nil {
+			fields[k] = fmt.Sprint(v)
+		}
+	}
+	return fields
+}
+
+// GELFHost returns a formatter that renders events as Graylog Extended Log
+// Format (GELF) 1.1 JSON objects.  The host field is set to the given host,
+// or to the local hostname (see Hostname) if host is empty.  The remaining
+// fields are rendered as follows:
+//
+//	short_message  The event message, or the error if the message is empty
+//	full_message   The message, error, and stack (see StackString).  Omitted
+//	               if the event has neither an error nor frames.
+//	timestamp      Seconds since the Unix epoch, with millisecond precision
+//	level          The syslog severity corresponding to the event level
+//	_file, _line   The call site.  Omitted if unknown.
+//	_<key>         Each context field, prefixed with an underscore
+//
+// Context keys are sanitized to the characters permitted by GELF, and the
+// reserved "id" key is written as "__id".  GELF only permits string and
+// numeric values, so other context values are written as strings.
+func GELFHost(host string) Formatter {
+	return func(buffer Buffer, event *cue.Event) {
+		eventHost := host
+		if eventHost == "" {
+			eventHost = RenderString(Hostname, event)
+		}
+		short := event.Message
+		if short == "" && event.Error != nil {
+			short = event.Error.Error()
+		}
+
+		buffer.AppendRune('{')
+		writeJSONPair(buffer, "version", "1.1", false)
+		writeJSONPair(buffer, "host", eventHost, true)
+		writeJSONPair(buffer, "short_message", short, true)
+		if event.Error != nil || len(event.Frames) > 0 {
+			full := RenderString(MessageWithError, event)
+			if len(event.Frames) > 0 {
+				full += "\n" + RenderString(StackString, event)
+			}
+			writeJSONPair(buffer, "full_message", full, true)
+		}
+		millis := event.Time.UnixNano() / int64(time.Millisecond)
+		writeJSONPair(buffer, "timestamp", json.Number(fmt.Sprintf("%d.%03d", millis/1000, millis%1000)), true)
+		writeJSONPair(buffer, "level", gelfLevel(event.Level), true)
+		if len(event.Frames) > 0 {
+			writeJSONPair(buffer, "_file", event.Frames[0].File, true)
+			writeJSONPair(buffer, "_line", event.Frames[0].Line, true)
+		}
+
+		fields := event.Context.Fields()
+		var sortedKeys []string
+		for k := range fields {
+			sortedKeys = append(sortedKeys, k)
+		}
+		sort.Strings(sortedKeys)
+		for _, k := range sortedKeys {
+			writeJSONPair(buffer, gelfKey(k), gelfValue(fields[k]), true)
+		}
+		buffer.AppendRune('}')
+	}
+}
+
+// gelfLevel returns the syslog severity for the level.  This matches the
+// severities used by the cue/collector syslog collectors.
+func gelfLevel(level cue.Level) int {
+	switch level {
+	case cue.TRACE, cue.DEBUG:
+		return 7
+	case cue.INFO:
+		return 6
+	case cue.WARN:
+		return 4
+	case cue.ERROR:
+		return 3
+	default:
+		return 2
+	}
+}
+
+func gelfKey(key string) string {
+	if key == "id" {
+		return "__id"
+	}
+	sanitized := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r == '_', r == '.', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+	return "_" + sanitized
+}
+
+func gelfValue(value interface{}) interface{} {
+	switch value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr, float32, float64, string:
+		return value
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// Bunyan marshals the event into a single JSON object using the field layout
+// of the Node.js Bunyan library, for consumption by tools such as the bunyan
+// and pino pretty-printers.  Fields are rendered as follows:
+//
+//	v         The Bunyan format version, always 0
+//	name      The event context name
+//	hostname  The local hostname (see FQDN)
+//	pid       The process ID
+//	level     The Bunyan level: 10 (trace), 20 (debug), 30 (info), 40 (warn),
+//	          50 (error), or 60 (fatal)
+//	msg       The event message
+//	time      The event time in ISO 8601 format, in UTC with milliseconds
+//	src       The call site file, line, and function.  Omitted if unknown.
+//	err       The error message, type name, and stack (see StackString).
+//	          Omitted if the event's Error field is nil.
+//	<key>     Each context field
+//
+// Context keys that collide with the keys above are written with a leading
+// underscore, e.g. "_msg".  Context values that can't be marshaled to JSON
+// are written as strings.  No trailing newline is written.
+func Bunyan(buffer Buffer, event *cue.Event) {
+	buffer.AppendRune('{')
+	writeJSONPair(buffer, "v", 0, false)
+	writeJSONPair(buffer, "name", event.Context.Name(), true)
+	writeJSONPair(buffer, "hostname", RenderString(FQDN, event), true)
+	writeJSONPair(buffer, "pid", os.Getpid(), true)
+	writeJSONPair(buffer, "level", bunyanLevel(event.Level), true)
+	writeJSONPair(buffer, "msg", event.Message, true)
+	writeJSONPair(buffer, "time", event.Time.UTC().Format("2006-01-02T15:04:05.000Z"), true)
+	if len(event.Frames) > 0 {
+		writeJSONPair(buffer, "src", bunyanSource{
+			File:     event.Frames[0].File,
+			Line:     event.Frames[0].Line,
+			Function: event.Frames[0].Function,
+		}, true)
+	}
+	if event.Error != nil {
+		writeJSONPair(buffer, "err", bunyanError{
+			Message: event.Error.Error(),
+			Name:    RenderString(ErrorType, event),
+			Stack:   RenderString(StackString, event),
+		}, true)
+	}
+
+	fields := jsonFields(event.Context.Fields())
+	var sortedKeys []string
+	for k := range fields {
+		sortedKeys = append(sortedKeys, k)
+	}
+	sort.Strings(sortedKeys)
+	for _, k := range sortedKeys {
+		key := k
+		if bunyanReserved[k] {
+			key = "_" + k
+		}
+		writeJSONPair(buffer, key, fields[k], true)
+	}
+	buffer.AppendRune('}')
+}
+
+var bunyanReserved = map[string]bool{
+	"v":        true,
+	"name":     true,
+	"hostname": true,
+	"pid":      true,
+	"level":    true,
+	"msg":      true,
+	"time":     true,
+	"src":      true,
+	"err":      true,
+}
+
+type bunyanSource struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Function string `json:"func"`
+}
+
+type bunyanError struct {
+	Message string `json:"message"`
+	Name    string `json:"name"`
+	Stack   string `json:"stack,omitempty"`
+}
+
+func bunyanLevel(level cue.Level) int {
+	switch level {
+	case cue.TRACE:
+		return 10
+	case cue.DEBUG:
+		return 20
+	case cue.INFO:
+		return 30
+	case cue.WARN:
+		return 40
+	case cue.ERROR:
+		return 50
+	default:
+		return 60
+	}
+}
+
+// FormEncodedContext writes the event.Context key/value pairs using
+// URL-encoded form syntax ("key1=val1&key2=val2").  Keys are sorted and both
+// keys and values are escaped using url.QueryEscape.  This is useful for
+// submitting context to form-based intake APIs.
+func FormEncodedContext(buffer Buffer, event *cue.Event) {
+	values := make(url.Values)
+	for k, v := range event.Context.Fields() {
+		values.Set(k, fmt.Sprint(v))
+	}
+	buffer.AppendString(values.Encode())
+}
+
 // StructuredContext marshals the event.Context fields into structured
 // key=value pairs as prescribed by RFC 5424, "The Syslog Protocol".
 func StructuredContext(buffer Buffer, event *cue.Event) {
@@ -533,3 +1606,95 @@ func writeStructuredValue(buffer Buffer, v interface{}) {
 		}
 	}
 }
+
+// MarkdownContext writes the event.Context fields as a Markdown bullet list,
+// one "- **key**: `value`" item per line, sorted by key.  Markdown special
+// characters in keys are escaped, line breaks in values are replaced with
+// spaces, values containing backticks are wrapped in double backticks, and
+// empty values are written as `""`.
+// This suits chat services and issue trackers that render Markdown.  No
+// trailing newline is written.
+func MarkdownContext(buffer Buffer, event *cue.Event) {
+	fields := event.Context.Fields()
+	var sortedKeys []string
+	for k := range fields {
+		sortedKeys = append(sortedKeys, k)
+	}
+	sort.Strings(sortedKeys)
+
+	for i, k := range sortedKeys {
+		if i > 0 {
+			buffer.AppendByte('\n')
+		}
+		buffer.AppendString("- **")
+		writeMarkdownEscaped(buffer, k)
+		buffer.AppendString("**: ")
+		writeMarkdownCode(buffer, fmt.Sprint(fields[k]))
+	}
+}
+
+// Markdown writes the event as a Markdown document suitable for chat messages
+// and issue bodies.  The document consists of the following blocks, separated
+// by blank lines:
+//
+//	**LEVEL** message    The event level in bold, followed by the message
+//	```error```          The event error in a fenced code block.  Omitted if
+//	                     the event's Error field is nil.
+//	- **key**: `value`   The context fields (see MarkdownContext).  Omitted
+//	                     if the context is empty.
+//
+// The message is written as-is, so it may contain Markdown of its own.  No
+// trailing newline is written.
+func Markdown(buffer Buffer, event *cue.Event) {
+	buffer.AppendString("**")
+	buffer.AppendString(event.Level.String())
+	buffer.AppendString("** ")
+	buffer.AppendString(event.Message)
+
+	if event.Error != nil {
+		message := event.Error.Error()
+		fence := "```"
+		for strings.Contains(message, fence) {
+			fence += "`"
+		}
+		buffer.AppendString("\n\n")
+		buffer.AppendString(fence)
+		buffer.AppendByte('\n')
+		buffer.AppendString(message)
+		buffer.AppendByte('\n')
+		buffer.AppendString(fence)
+	}
+
+	if event.Context.NumValues() > 0 {
+		buffer.AppendString("\n\n")
+		MarkdownContext(buffer, event)
+	}
+}
+
+func writeMarkdownEscaped(buffer Buffer, s string) {
+	for _, r := range s {
+		switch r {
+		case '\\', '*', '_', '`', '[', ']', '<', '>':
+			buffer.AppendRune('\\')
+		case '\n', '\r':
+			r = ' '
+		}
+		buffer.AppendRune(r)
+	}
+}
+
+func writeMarkdownCode(buffer Buffer, s string) {
+	s = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(s)
+	if s == "" {
+		s = `""`
+	}
+	if !strings.Contains(s, "`") {
+		buffer.AppendRune('`')
+		buffer.AppendString(s)
+		buffer.AppendRune('`')
+		return
+	}
+	buffer.AppendString("`` ")
+	buffer.AppendString(s)
+	buffer.AppendString(" ``")
+}
@@ -21,9 +21,14 @@
 package format
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/bobziuchkovski/cue"
 	"github.com/bobziuchkovski/cue/internal/cuetest"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -47,6 +52,12 @@ func TestHumanMessage(t *testing.T) {
 	checkRendered(t, expected, RenderString(HumanMessage, cuetest.ErrorEvent))
 }
 
+func TestHumanMessageMultiError(t *testing.T) {
+	multi := &cue.MultiError{Errors: []error{errors.New("first"), errors.New("second")}}
+	event := cue.NewTestEvent(cue.EventLevel(cue.ERROR), cue.EventMessage("batch failed"), cue.EventError(multi))
+	checkRendered(t, "batch failed: first; second", RenderString(HumanMessage, event))
+}
+
 func TestHumanReadable(t *testing.T) {
 	expected := `Jan  2 15:04:00 DEBUG debug event k1="some value" k2=2 k3=3.5 k4=true`
 	checkRendered(t, expected, RenderString(HumanReadable, cuetest.DebugEventNoFrames))
@@ -83,6 +94,162 @@ func TestJSONMessage(t *testing.T) {
 	checkRendered(t, expected, RenderString(JSONMessage, cuetest.ErrorEvent))
 }
 
+func TestJSONEvent(t *testing.T) {
+	expected := `{"time":"2006-01-02T15:04:00Z","level":"DEBUG","message":"debug event",` +
+		`"file":"/path/github.com/bobziuchkovski/cue/frame3/file3.go","line":3,"function":"github.com/bobziuchkovski/cue/frame3.function3",` +
+		`"fields":{"k1":"some value","k2":2,"k3":3.5,"k4":true}}`
+	checkRendered(t, expected, RenderString(JSONEvent, cuetest.DebugEvent))
+
+	expected = `{"time":"2006-01-02T15:04:00Z","level":"ERROR","message":"error event","error":"error message",` +
+		`"fields":{"k1":"some value","k2":2,"k3":3.5,"k4":true}}`
+	checkRendered(t, expected, RenderString(JSONEvent, cuetest.ErrorEventNoFrames))
+
+	// Reserved keys in the context must not collide with event keys
+	ctx := cue.NewContext("test").WithValue("level", "bogus").WithValue("c", complex(1, 2))
+	e := cuetest.GenerateEvent(cue.INFO, ctx, "quote \" and newline \n", nil, 0)
+	expected = `{"time":"2006-01-02T15:04:00Z","level":"INFO","message":"quote \" and newline \n",` +
+		`"fields":{"c":"(1+2i)","level":"bogus"}}`
+	checkRendered(t, expected, RenderString(JSONEvent, e))
+
+	var decoded map[string]interface{}
+	err := json.Unmarshal(RenderBytes(JSONEvent, cuetest.ErrorEvent), &decoded)
+	if err != nil {
+		t.Errorf("Expected JSONEvent output to be valid JSON, but received error: %s", err)
+	}
+}
+
+func TestJSONStack(t *testing.T) {
+	expected := `{"time":"2006-01-02T15:04:00Z","level":"DEBUG","message":"debug event",` +
+		`"file":"/path/github.com/bobziuchkovski/cue/frame3/file3.go","line":3,"function":"github.com/bobziuchkovski/cue/frame3.function3",` +
+		`"stack":"github.com/bobziuchkovski/cue/frame3.function3 (/path/github.com/bobziuchkovski/cue/frame3/file3.go:3)\n` +
+		`github.com/bobziuchkovski/cue/frame2.function2 (/path/github.com/bobziuchkovski/cue/frame2/file2.go:2)\n` +
+		`github.com/bobziuchkovski/cue/frame1.function1 (/path/github.com/bobziuchkovski/cue/frame1/file1.go:1)",` +
+		`"fields":{"k1":"some value","k2":2,"k3":3.5,"k4":true}}`
+	checkRendered(t, expected, RenderString(JSON(JSONOptions{Stack: true}), cuetest.DebugEvent))
+
+	// The stack is omitted when frames are unavailable
+	expected = `{"time":"2006-01-02T15:04:00Z","level":"DEBUG","message":"debug event",` +
+		`"fields":{"k1":"some value","k2":2,"k3":3.5,"k4":true}}`
+	checkRendered(t, expected, RenderString(JSON(JSONOptions{Stack: true}), cuetest.DebugEventNoFrames))
+}
+
+func TestBunyan(t *testing.T) {
+	header := fmt.Sprintf(`{"v":0,"name":"test context","hostname":%q,"pid":%d,`, RenderString(FQDN, cuetest.DebugEvent), os.Getpid())
+	stack := `github.com/bobziuchkovski/cue/frame3.function3 (/path/github.com/bobziuchkovski/cue/frame3/file3.go:3)\n` +
+		`github.com/bobziuchkovski/cue/frame2.function2 (/path/github.com/bobziuchkovski/cue/frame2/file2.go:2)\n` +
+		`github.com/bobziuchkovski/cue/frame1.function1 (/path/github.com/bobziuchkovski/cue/frame1/file1.go:1)`
+	src := `"src":{"file":"/path/github.com/bobziuchkovski/cue/frame3/file3.go","line":3,"func":"github.com/bobziuchkovski/cue/frame3.function3"},`
+
+	expected := header + `"level":20,"msg":"debug event","time":"2006-01-02T15:04:00.000Z",` + src +
+		`"k1":"some value","k2":2,"k3":3.5,"k4":true}`
+	checkRendered(t, expected, RenderString(Bunyan, cuetest.DebugEvent))
+
+	expected = header + `"level":50,"msg":"error event","time":"2006-01-02T15:04:00.000Z",` + src +
+		`"err":{"message":"error message","name":"errors.errorString","stack":"` + stack + `"},` +
+		`"k1":"some value","k2":2,"k3":3.5,"k4":true}`
+	checkRendered(t, expected, RenderString(Bunyan, cuetest.ErrorEvent))
+
+	expected = header + `"level":50,"msg":"error event","time":"2006-01-02T15:04:00.000Z",` +
+		`"err":{"message":"error message","name":"errors.errorString"},` +
+		`"k1":"some value","k2":2,"k3":3.5,"k4":true}`
+	checkRendered(t, expected, RenderString(Bunyan, cuetest.ErrorEventNoFrames))
+
+	// Reserved keys in the context must not collide with Bunyan keys
+	ctx := cue.NewContext("test").WithValue("msg", "bogus").WithValue("c", complex(1, 2))
+	e := cuetest.GenerateEvent(cue.INFO, ctx, "info event", nil, 0)
+	expected = fmt.Sprintf(`{"v":0,"name":"test","hostname":%q,"pid":%d,`, RenderString(FQDN, e), os.Getpid()) +
+		`"level":30,"msg":"info event","time":"2006-01-02T15:04:00.000Z","c":"(1+2i)","_msg":"bogus"}`
+	checkRendered(t, expected, RenderString(Bunyan, e))
+}
+
+func TestBunyanLevels(t *testing.T) {
+	expected := map[cue.Level]int{
+		cue.TRACE: 10,
+		cue.DEBUG: 20,
+		cue.INFO:  30,
+		cue.WARN:  40,
+		cue.ERROR: 50,
+		cue.FATAL: 60,
+	}
+	for level, bunyan := range expected {
+		if bunyanLevel(level) != bunyan {
+			t.Errorf("Expected cue level %s to map to bunyan level %d, but got %d instead", level, bunyan, bunyanLevel(level))
+		}
+	}
+}
+
+func TestGELF(t *testing.T) {
+	expected := `{"version":"1.1","host":"example.com","short_message":"debug event",` +
+		`"full_message":"debug event\ngithub.com/bobziuchkovski/cue/frame3.function3 (/path/github.com/bobziuchkovski/cue/frame3/file3.go:3)\n` +
+		`github.com/bobziuchkovski/cue/frame2.function2 (/path/github.com/bobziuchkovski/cue/frame2/file2.go:2)\n` +
+		`github.com/bobziuchkovski/cue/frame1.function1 (/path/github.com/bobziuchkovski/cue/frame1/file1.go:1)",` +
+		`"timestamp":1136214240.000,"level":7,"_file":"/path/github.com/bobziuchkovski/cue/frame3/file3.go","_line":3,` +
+		`"_k1":"some value","_k2":2,"_k3":3.5,"_k4":"true"}`
+	checkRendered(t, expected, RenderString(GELFHost("example.com"), cuetest.DebugEvent))
+
+	expected = `{"version":"1.1","host":"example.com","short_message":"error event","full_message":"error event: error message",` +
+		`"timestamp":1136214240.000,"level":3,"_k1":"some value","_k2":2,"_k3":3.5,"_k4":"true"}`
+	checkRendered(t, expected, RenderString(GELFHost("example.com"), cuetest.ErrorEventNoFrames))
+
+	// Reserved and invalid keys are rewritten
+	ctx := cue.NewContext("test").WithValue("id", 1).WithValue("bad key!", "v")
+	e := cuetest.GenerateEvent(cue.INFO, ctx, "info", nil, 0)
+	e.Time = e.Time.Add(1500 * time.Millisecond)
+	expected = `{"version":"1.1","host":"example.com","short_message":"info","timestamp":1136214241.500,"level":6,"_bad_key_":"v","__id":1}`
+	checkRendered(t, expected, RenderString(GELFHost("example.com"), e))
+
+	// The local hostname is used by default
+	host := RenderString(Hostname, cuetest.DebugEvent)
+	if !strings.Contains(RenderString(GELF, cuetest.DebugEvent), `"host":`+strconv.Quote(host)) {
+		t.Errorf("Expected GELF output to include the local hostname %q, but it didn't", host)
+	}
+}
+
+func TestSampleRate(t *testing.T) {
+	checkRendered(t, "", RenderString(SampleRate, cuetest.DebugEvent))
+
+	e := cuetest.DebugEvent.Clone()
+	e.SampleRate = 10
+	checkRendered(t, "10", RenderString(SampleRate, e))
+
+	expected := `{"time":"2006-01-02T15:04:00Z","level":"DEBUG","message":"debug event",` +
+		`"file":"/path/github.com/bobziuchkovski/cue/frame3/file3.go","line":3,"function":"github.com/bobziuchkovski/cue/frame3.function3",` +
+		`"sample_rate":10,"fields":{"k1":"some value","k2":2,"k3":3.5,"k4":true}}`
+	checkRendered(t, expected, RenderString(JSONEvent, e))
+}
+
+func TestJSONKeys(t *testing.T) {
+	formatter := JSON(JSONOptions{Keys: JSONKeys{Time: "@timestamp", Level: "severity", Message: "msg", Fields: "labels"}})
+	expected := `{"@timestamp":"2006-01-02T15:04:00Z","severity":"DEBUG","msg":"debug event",` +
+		`"file":"/path/github.com/bobziuchkovski/cue/frame3/file3.go","line":3,"function":"github.com/bobziuchkovski/cue/frame3.function3",` +
+		`"labels":{"k1":"some value","k2":2,"k3":3.5,"k4":true}}`
+	checkRendered(t, expected, RenderString(formatter, cuetest.DebugEvent))
+
+	e := cuetest.ErrorEvent.Clone()
+	e.SampleRate = 10
+	formatter = JSON(JSONOptions{Stack: true, Keys: JSONKeys{
+		Error:      "error.message",
+		File:       "log.origin.file.name",
+		Line:       "log.origin.file.line",
+		Function:   "log.origin.function",
+		Stack:      "error.stack_trace",
+		SampleRate: "rate",
+	}})
+	var decoded map[string]interface{}
+	err := json.Unmarshal(RenderBytes(formatter, e), &decoded)
+	if err != nil {
+		t.Fatalf("Expected JSON output to be valid JSON, but received error: %s", err)
+	}
+	for _, key := range []string{"time", "level", "message", "error.message", "log.origin.file.name", "log.origin.file.line", "log.origin.function", "error.stack_trace", "rate", "fields"} {
+		if _, present := decoded[key]; !present {
+			t.Errorf("Expected to see key %q in the output, but didn't: %v", key, decoded)
+		}
+	}
+	if len(decoded) != 10 {
+		t.Errorf("Expected exactly 10 keys in the output, but saw %d instead: %v", len(decoded), decoded)
+	}
+}
+
 func TestJoin(t *testing.T) {
 	checkRendered(t, "1 2 3", RenderString(Join(" ", Literal("1"), Literal("2"), Literal("3")), cuetest.DebugEvent))
 	checkRendered(t, "1 3", RenderString(Join(" ", Literal("1"), Literal(""), Literal("3")), cuetest.DebugEvent))
@@ -90,6 +257,22 @@ func TestJoin(t *testing.T) {
 	checkRendered(t, "2 3", RenderString(Join(" ", Literal(""), Literal("2"), Literal("3")), cuetest.DebugEvent))
 }
 
+func TestCSV(t *testing.T) {
+	formatter := CSV(Level, Message, Error, HumanContext)
+	checkRendered(t, "DEBUG,debug event,,\"k1=\"\"some value\"\" k2=2 k3=3.5 k4=true\"\r\n", RenderString(formatter, cuetest.DebugEvent))
+	checkRendered(t, "ERROR,error event,error message,\"k1=\"\"some value\"\" k2=2 k3=3.5 k4=true\"\r\n", RenderString(formatter, cuetest.ErrorEvent))
+
+	event := cuetest.GenerateEvent(cue.INFO, cue.NewContext("test"), "comma, and\r\nnewline", nil, 0)
+	checkRendered(t, "\"comma, and\r\nnewline\"\r\n", RenderString(CSV(Message), event))
+	checkRendered(t, "\r\n", RenderString(CSV(), event))
+}
+
+func TestCSVHeader(t *testing.T) {
+	formatter := CSVHeader([]string{"level", "message, text"}, CSV(Level, Message))
+	checkRendered(t, "level,\"message, text\"\r\nDEBUG,debug event\r\n", RenderString(formatter, cuetest.DebugEvent))
+	checkRendered(t, "INFO,info event\r\n", RenderString(formatter, cuetest.InfoEvent))
+}
+
 func TestFormatf(t *testing.T) {
 	checkRendered(t, "1 + 2 = 3", RenderString(Formatf("%v + %v = %v", Literal("1"), Literal("2"), Literal("3")), cuetest.DebugEvent))
 	checkRendered(t, "1+2=3", RenderString(Formatf("%v+%v=%v", Literal("1"), Literal("2"), Literal("3")), cuetest.DebugEvent))
@@ -105,13 +288,113 @@ func TestFormatf(t *testing.T) {
 	checkRendered(t, "test %!v(MISSING)", RenderString(Formatf("test %v"), cuetest.DebugEvent))
 }
 
+func TestFormatfPadding(t *testing.T) {
+	checkRendered(t, "DEBUG debug event", RenderString(Formatf("%-5v %v", Level, Message), cuetest.DebugEvent))
+	checkRendered(t, "INFO  info event", RenderString(Formatf("%-5v %v", Level, Message), cuetest.InfoEvent))
+	checkRendered(t, " INFO|", RenderString(Formatf("%5v|", Level), cuetest.InfoEvent))
+	checkRendered(t, "[ERROR]", RenderString(Formatf("[%3v]", Level), cuetest.ErrorEvent))
+	checkRendered(t, "[ERROR]", RenderString(Formatf("[%-v]", Level), cuetest.ErrorEvent))
+	checkRendered(t, "héllo |", RenderString(Formatf("%-6v|", Literal("héllo")), cuetest.DebugEvent))
+	checkRendered(t, "    %!v(MISSING)", RenderString(Formatf("%3v %-4v", Literal("")), cuetest.DebugEvent))
+
+	// Escaping and malformed specs
+	checkRendered(t, "%5v", RenderString(Formatf("%%5v"), cuetest.DebugEvent))
+	checkRendered(t, "%-5d test", RenderString(Formatf("%-5d %v", Literal("test")), cuetest.DebugEvent))
+	checkRendered(t, "%- test", RenderString(Formatf("%- %v", Literal("test")), cuetest.DebugEvent))
+	checkRendered(t, "test%5", RenderString(Formatf("%v%5", Literal("test")), cuetest.DebugEvent))
+	checkRendered(t, "%99999999999999999999v", RenderString(Formatf("%99999999999999999999v", Literal("test")), cuetest.DebugEvent))
+}
+
 func TestColorize(t *testing.T) {
 	test := Literal("test")
+	checkRendered(t, "\x1b[34mtest\x1b[0m", RenderString(Colorize(test), cuetest.GenerateEvent(cue.TRACE, cuetest.DebugEvent.Context, "trace event", nil, 0)))
 	checkRendered(t, "\x1b[34mtest\x1b[0m", RenderString(Colorize(test), cuetest.DebugEvent))
 	checkRendered(t, "\x1b[32mtest\x1b[0m", RenderString(Colorize(test), cuetest.InfoEvent))
 	checkRendered(t, "\x1b[33mtest\x1b[0m", RenderString(Colorize(test), cuetest.WarnEvent))
 	checkRendered(t, "\x1b[31mtest\x1b[0m", RenderString(Colorize(test), cuetest.ErrorEvent))
 	checkRendered(t, "\x1b[31mtest\x1b[0m", RenderString(Colorize(test), cuetest.FatalEvent))
+
+	nested := Colorize(Join(" ", Colorize(Level), Message))
+	checkRendered(t, "\x1b[34m\x1b[34mDEBUG\x1b[0m\x1b[34m debug event\x1b[0m", RenderString(nested, cuetest.DebugEvent))
+}
+
+func TestColorizeLevelOnly(t *testing.T) {
+	checkRendered(t, "Jan  2 15:04:00 \x1b[34mDEBUG\x1b[0m file3.go:3 debug event k1=\"some value\" k2=2 k3=3.5 k4=true",
+		RenderString(HumanReadableLevelColors, cuetest.DebugEvent))
+	checkRendered(t, "\x1b[31mERROR\x1b[0m error event: error message",
+		RenderString(Join(" ", Colorize(Level), MessageWithError), cuetest.ErrorEvent))
+}
+
+func TestIsTerminal(t *testing.T) {
+	file, err := os.Create(filepath.Join(os.TempDir(), "cue-isterminal-test"))
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %s", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	if IsTerminal(file) {
+		t.Error("Expected a regular file not to be reported as a terminal")
+	}
+	if IsTerminal(nil) {
+		t.Error("Expected a nil file not to be reported as a terminal")
+	}
+
+	devnull, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %s", os.DevNull, err)
+	}
+	defer devnull.Close()
+	if !IsTerminal(devnull) {
+		t.Errorf("Expected %s to be reported as a character device", os.DevNull)
+	}
+}
+
+func TestColorizeIfTerminal(t *testing.T) {
+	file, err := os.Create(filepath.Join(os.TempDir(), "cue-colorize-test"))
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %s", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	checkRendered(t, "test", RenderString(ColorizeIfTerminal(Literal("test"), file), cuetest.DebugEvent))
+	checkRendered(t, "test", RenderString(ColorizeIfTerminal(Literal("test"), nil), cuetest.DebugEvent))
+
+	devnull, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %s", os.DevNull, err)
+	}
+	defer devnull.Close()
+	checkRendered(t, "\x1b[34mtest\x1b[0m", RenderString(ColorizeIfTerminal(Literal("test"), devnull), cuetest.DebugEvent))
+}
+
+var columnTests = []struct {
+	Content  string
+	Width    int
+	Align    Align
+	Expected string
+}{
+	{Content: "abc", Width: 6, Align: AlignLeft, Expected: "abc   "},
+	{Content: "abc", Width: 6, Align: AlignRight, Expected: "   abc"},
+	{Content: "abc", Width: 6, Align: AlignCenter, Expected: " abc  "},
+	{Content: "abcdef", Width: 6, Align: AlignLeft, Expected: "abcdef"},
+	{Content: "abcdef", Width: 6, Align: AlignRight, Expected: "abcdef"},
+	{Content: "abcdef", Width: 6, Align: AlignCenter, Expected: "abcdef"},
+	{Content: "abcdefgh", Width: 6, Align: AlignLeft, Expected: "abcde…"},
+	{Content: "abcdefgh", Width: 6, Align: AlignRight, Expected: "abcde…"},
+	{Content: "abcdefgh", Width: 6, Align: AlignCenter, Expected: "abcde…"},
+	{Content: "日本語", Width: 5, Align: AlignCenter, Expected: " 日本語 "},
+	{Content: "日本語テキスト", Width: 4, Align: AlignLeft, Expected: "日本語…"},
+	{Content: "abc", Width: 1, Align: AlignLeft, Expected: "…"},
+	{Content: "abc", Width: 0, Align: AlignLeft, Expected: ""},
+	{Content: "", Width: 2, Align: AlignRight, Expected: "  "},
+}
+
+func TestColumn(t *testing.T) {
+	for _, test := range columnTests {
+		checkRendered(t, test.Expected, RenderString(Column(Literal(test.Content), test.Width, test.Align), cuetest.DebugEvent))
+	}
 }
 
 func TestTrim(t *testing.T) {
@@ -121,6 +404,19 @@ func TestTrim(t *testing.T) {
 	checkRendered(t, "test", RenderString(Trim(Literal("\ntest\n")), cuetest.DebugEvent))
 }
 
+func TestUpper(t *testing.T) {
+	checkRendered(t, "MIXED CASE", RenderString(Upper(Literal("MiXeD case")), cuetest.DebugEvent))
+	checkRendered(t, "DEBUG", RenderString(Upper(Level), cuetest.DebugEvent))
+	checkRendered(t, "ÉTÉ", RenderString(Upper(Literal("été")), cuetest.DebugEvent))
+}
+
+func TestLower(t *testing.T) {
+	checkRendered(t, "mixed case", RenderString(Lower(Literal("MiXeD case")), cuetest.DebugEvent))
+	checkRendered(t, "debug", RenderString(Lower(Level), cuetest.DebugEvent))
+	checkRendered(t, "error", RenderString(Lower(Level), cuetest.ErrorEvent))
+	checkRendered(t, "été", RenderString(Lower(Literal("ÉTÉ")), cuetest.DebugEvent))
+}
+
 func TestEscape(t *testing.T) {
 	checkRendered(t, "test", RenderString(Escape(Literal("test")), cuetest.DebugEvent))
 	checkRendered(t, " test ", RenderString(Escape(Literal(" test ")), cuetest.DebugEvent))
@@ -131,10 +427,81 @@ func TestEscape(t *testing.T) {
 	checkRendered(t, "\\x00", RenderString(Escape(Literal(string(rune(0)))), cuetest.DebugEvent))
 }
 
+func TestStripANSI(t *testing.T) {
+	checkRendered(t, "test", RenderString(StripANSI(Literal("test")), cuetest.DebugEvent))
+	checkRendered(t, "日本", RenderString(StripANSI(Literal("日本")), cuetest.DebugEvent))
+	checkRendered(t, "red text", RenderString(StripANSI(Literal("\x1b[31mred\x1b[0m text")), cuetest.DebugEvent))
+	checkRendered(t, "bold", RenderString(StripANSI(Literal("\x1b[1;31mbold\x1b[m")), cuetest.DebugEvent))
+	checkRendered(t, "cursor moved", RenderString(StripANSI(Literal("cursor\x1b[2A\x1b[10;20H moved")), cuetest.DebugEvent))
+	checkRendered(t, "cleared", RenderString(StripANSI(Literal("\x1b[2Jcleared\x1b[K")), cuetest.DebugEvent))
+	checkRendered(t, "title", RenderString(StripANSI(Literal("\x1b]0;evil\x07title")), cuetest.DebugEvent))
+	checkRendered(t, "title", RenderString(StripANSI(Literal("\x1b]0;evil\x1b\\title")), cuetest.DebugEvent))
+	checkRendered(t, "reset", RenderString(StripANSI(Literal("\x1bcreset")), cuetest.DebugEvent))
+	checkRendered(t, "saved", RenderString(StripANSI(Literal("\x1b7saved\x1b8")), cuetest.DebugEvent))
+	checkRendered(t, "charset", RenderString(StripANSI(Literal("\x1b(Bcharset")), cuetest.DebugEvent))
+	checkRendered(t, "csi", RenderString(StripANSI(Literal("\u009b31mcsi")), cuetest.DebugEvent))
+	checkRendered(t, "trailing", RenderString(StripANSI(Literal("trailing\x1b")), cuetest.DebugEvent))
+	checkRendered(t, "unterminated", RenderString(StripANSI(Literal("unterminated\x1b[31")), cuetest.DebugEvent))
+}
+
 func TestTruncate(t *testing.T) {
 	checkRendered(t, "tes", RenderString(Truncate(Literal("test"), 3), cuetest.DebugEvent))
 }
 
+func TestTruncateValues(t *testing.T) {
+	checkRendered(t, `{"k1":"some…","k2":2,"k3":3.5,"k4":true}`, RenderString(TruncateValues(JSONContext, 5), cuetest.DebugEvent))
+	checkRendered(t, `{"k1":"so…","k2":2,"k3":3.5,"k4":"tr…"}`, RenderString(TruncateValues(JSONContext, 3), cuetest.DebugEvent))
+	checkRendered(t, `{"k1":"some value","k2":2,"k3":3.5,"k4":true}`, RenderString(TruncateValues(JSONContext, 10), cuetest.DebugEvent))
+	checkRendered(t, `{"k1":"some value","k2":2,"k3":3.5,"k4":true}`, RenderString(TruncateValues(JSONContext, 0), cuetest.DebugEvent))
+
+	e := cuetest.GenerateEvent(cue.INFO, cue.NewContext("test").WithValue("k", "日本語テキスト"), "message", nil, 0)
+	checkRendered(t, `k=日本語…`, RenderString(TruncateValues(HumanContext, 4), e))
+}
+
+func TestPrefix(t *testing.T) {
+	checkRendered(t, "error=error message", RenderString(Prefix(Error, "error="), cuetest.ErrorEvent))
+	checkRendered(t, "", RenderString(Prefix(Error, "error="), cuetest.DebugEvent))
+	checkRendered(t, "[file3.go:3", RenderString(Prefix(SourceWithLine, "["), cuetest.DebugEvent))
+	checkRendered(t, "", RenderString(Prefix(SourceWithLine, "["), cuetest.DebugEventNoFrames))
+}
+
+func TestSuffix(t *testing.T) {
+	checkRendered(t, "error message; ", RenderString(Suffix(Error, "; "), cuetest.ErrorEvent))
+	checkRendered(t, "", RenderString(Suffix(Error, "; "), cuetest.DebugEvent))
+	checkRendered(t, "[file3.go:3]", RenderString(Suffix(Prefix(SourceWithLine, "["), "]"), cuetest.DebugEvent))
+	checkRendered(t, "", RenderString(Suffix(Prefix(SourceWithLine, "["), "]"), cuetest.DebugEventNoFrames))
+}
+
+func TestWhenField(t *testing.T) {
+	checkRendered(t, "SLOW", RenderString(WhenField("k4", "true", Literal("SLOW")), cuetest.DebugEvent))
+	checkRendered(t, "two", RenderString(WhenField("k2", "2", Literal("two")), cuetest.DebugEvent))
+	checkRendered(t, "", RenderString(WhenField("k4", "false", Literal("SLOW")), cuetest.DebugEvent))
+	checkRendered(t, "", RenderString(WhenField("missing", "true", Literal("SLOW")), cuetest.DebugEvent))
+	checkRendered(t, "", RenderString(WhenField("missing", "<nil>", Literal("SLOW")), cuetest.DebugEvent))
+}
+
+func TestByLevel(t *testing.T) {
+	formatter := ByLevel(map[cue.Level]Formatter{
+		cue.DEBUG: Literal("debug"),
+		cue.ERROR: Message,
+		cue.WARN:  nil,
+	}, Literal("default"))
+	checkRendered(t, "debug", RenderString(formatter, cuetest.DebugEvent))
+	checkRendered(t, "error event", RenderString(formatter, cuetest.ErrorEvent))
+	checkRendered(t, "default", RenderString(formatter, cuetest.InfoEvent))
+	checkRendered(t, "default", RenderString(formatter, cuetest.WarnEvent))
+
+	formatter = ByLevel(map[cue.Level]Formatter{cue.DEBUG: Literal("debug")}, nil)
+	checkRendered(t, "", RenderString(formatter, cuetest.InfoEvent))
+}
+
+func TestDefault(t *testing.T) {
+	checkRendered(t, "test", RenderString(Default(Literal("test"), "-"), cuetest.DebugEvent))
+	checkRendered(t, "-", RenderString(Default(Literal(""), "-"), cuetest.DebugEvent))
+	checkRendered(t, "file3.go:3", RenderString(Default(SourceWithLine, "-"), cuetest.DebugEvent))
+	checkRendered(t, "-", RenderString(Default(SourceWithLine, "-"), cuetest.DebugEventNoFrames))
+}
+
 func TestLiteral(t *testing.T) {
 	checkRendered(t, "test", RenderString(Literal("test"), cuetest.DebugEvent))
 }
@@ -143,6 +510,36 @@ func TestTime(t *testing.T) {
 	checkRendered(t, "Jan  2 15:04:00", RenderString(Time(time.Stamp), cuetest.DebugEvent))
 }
 
+func TestTimeUTC(t *testing.T) {
+	event := cuetest.GenerateEvent(cue.DEBUG, cuetest.DebugEvent.Context, "", nil, 0)
+	event.Time = time.Date(2006, 1, 2, 15, 4, 5, 0, time.FixedZone("MST", -7*60*60))
+
+	checkRendered(t, "2006-01-02 15:04:05 MST", RenderString(Time("2006-01-02 15:04:05 MST"), event))
+	checkRendered(t, "2006-01-02 22:04:05 UTC", RenderString(TimeUTC("2006-01-02 15:04:05 MST"), event))
+}
+
+func TestTimeIn(t *testing.T) {
+	event := cuetest.GenerateEvent(cue.DEBUG, cuetest.DebugEvent.Context, "", nil, 0)
+	event.Time = time.Date(2006, 1, 2, 15, 4, 5, 0, time.FixedZone("MST", -7*60*60))
+
+	checkRendered(t, "2006-01-03T07:04:05+09:00", RenderString(TimeIn(time.FixedZone("JST", 9*60*60), time.RFC3339), event))
+	checkRendered(t, "2006-01-02T22:04:05Z", RenderString(TimeIn(nil, time.RFC3339), event))
+}
+
+func TestISO8601(t *testing.T) {
+	event := cuetest.GenerateEvent(cue.DEBUG, cuetest.DebugEvent.Context, "", nil, 0)
+	event.Time = time.Date(2006, 1, 2, 15, 4, 5, 123456789, time.FixedZone("MST", -7*60*60))
+
+	checkRendered(t, "2006-01-02T15:04:05-07:00", RenderString(ISO8601, event))
+	checkRendered(t, "2006-01-02T15:04:05.123-07:00", RenderString(ISO8601Millis, event))
+	checkRendered(t, "2006-01-02T22:04:05Z", RenderString(ISO8601UTC, event))
+	checkRendered(t, "2006-01-02T22:04:05.123Z", RenderString(ISO8601MillisUTC, event))
+
+	// Millisecond precision is fixed, even for whole seconds
+	event.Time = time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)
+	checkRendered(t, "2006-01-02T15:04:05.000Z", RenderString(ISO8601Millis, event))
+}
+
 func TestHostname(t *testing.T) {
 	host, err := os.Hostname()
 	if err != nil {
@@ -159,6 +556,17 @@ func TestFQDN(t *testing.T) {
 	checkRendered(t, host, RenderString(FQDN, cuetest.DebugEvent))
 }
 
+func TestCommand(t *testing.T) {
+	checkRendered(t, filepath.Base(os.Args[0]), RenderString(Command, cuetest.DebugEvent))
+}
+
+func TestCommandLine(t *testing.T) {
+	rendered := RenderString(CommandLine, cuetest.DebugEvent)
+	if !strings.HasPrefix(rendered, os.Args[0]) {
+		t.Errorf("Expected rendered command line to start with %q, but received %q instead", os.Args[0], rendered)
+	}
+}
+
 func TestLevel(t *testing.T) {
 	checkRendered(t, "DEBUG", RenderString(Level, cuetest.DebugEvent))
 	checkRendered(t, "INFO", RenderString(Level, cuetest.InfoEvent))
@@ -192,6 +600,24 @@ func TestLine(t *testing.T) {
 	checkRendered(t, "0", RenderString(Line, cuetest.DebugEventNoFrames))
 }
 
+func TestStackString(t *testing.T) {
+	expected := "github.com/bobziuchkovski/cue/frame3.function3 (/path/github.com/bobziuchkovski/cue/frame3/file3.go:3)\n" +
+		"github.com/bobziuchkovski/cue/frame2.function2 (/path/github.com/bobziuchkovski/cue/frame2/file2.go:2)\n" +
+		"github.com/bobziuchkovski/cue/frame1.function1 (/path/github.com/bobziuchkovski/cue/frame1/file1.go:1)"
+	checkRendered(t, expected, RenderString(StackString, cuetest.DebugEvent))
+	checkRendered(t, "", RenderString(StackString, cuetest.DebugEventNoFrames))
+
+	// The joined stack must embed as a single escaped JSON string
+	marshaled, err := json.Marshal(map[string]string{"stack": RenderString(StackString, cuetest.DebugEvent)})
+	if err != nil {
+		t.Fatalf("Encountered unexpected error marshaling stack: %s", err)
+	}
+	expectedJSON := `{"stack":"github.com/bobziuchkovski/cue/frame3.function3 (/path/github.com/bobziuchkovski/cue/frame3/file3.go:3)\n` +
+		`github.com/bobziuchkovski/cue/frame2.function2 (/path/github.com/bobziuchkovski/cue/frame2/file2.go:2)\n` +
+		`github.com/bobziuchkovski/cue/frame1.function1 (/path/github.com/bobziuchkovski/cue/frame1/file1.go:1)"}`
+	checkRendered(t, expectedJSON, string(marshaled))
+}
+
 func TestMessage(t *testing.T) {
 	checkRendered(t, "debug event", RenderString(Message, cuetest.DebugEvent))
 	checkRendered(t, "error event", RenderString(Message, cuetest.ErrorEvent))
@@ -212,6 +638,14 @@ func TestMessageWithError(t *testing.T) {
 	checkRendered(t, "error event: error message", RenderString(MessageWithError, cuetest.ErrorEvent))
 }
 
+func TestDiagnostics(t *testing.T) {
+	checkRendered(t, "", RenderString(Diagnostics, cuetest.FatalEvent))
+
+	event := cuetest.GenerateEvent(cue.FATAL, cuetest.FatalEvent.Context, "fatal event", nil, 0)
+	event.Goroutines = 42
+	checkRendered(t, "goroutines=42", RenderString(Diagnostics, event))
+}
+
 func TestSourceWithLine(t *testing.T) {
 	checkRendered(t, "file3.go:3", RenderString(SourceWithLine, cuetest.DebugEvent))
 	checkRendered(t, "", RenderString(SourceWithLine, cuetest.DebugEventNoFrames))
@@ -221,6 +655,17 @@ func TestContextName(t *testing.T) {
 	checkRendered(t, "test context", RenderString(ContextName, cuetest.DebugEvent))
 }
 
+func TestSelectFields(t *testing.T) {
+	checkRendered(t, `k1="some value" k3=3.5`, RenderString(SelectFields("k3", "k1", "missing"), cuetest.DebugEvent))
+	checkRendered(t, "", RenderString(SelectFields(), cuetest.DebugEvent))
+	checkRendered(t, `k1="some value" k2=2 k3=3.5 k4=true`, RenderString(HumanContext, cuetest.DebugEvent))
+}
+
+func TestExcludeFields(t *testing.T) {
+	checkRendered(t, `k2=2 k4=true`, RenderString(ExcludeFields("k1", "k3", "missing"), cuetest.DebugEvent))
+	checkRendered(t, `k1="some value" k2=2 k3=3.5 k4=true`, RenderString(ExcludeFields(), cuetest.DebugEvent))
+}
+
 func TestHumanContext(t *testing.T) {
 	checkRendered(t, `k1="some value" k2=2 k3=3.5 k4=true`, RenderString(HumanContext, cuetest.DebugEvent))
 
@@ -251,6 +696,127 @@ func TestHumanContext(t *testing.T) {
 	checkRendered(t, `"test\\test"="v1 v2"`, RenderString(HumanContext, e))
 }
 
+func TestDeferredContext(t *testing.T) {
+	calls := 0
+	ctx := cue.NewContext("test").WithValue("k1", cue.Deferred(func() interface{} {
+		calls++
+		return "deferred value"
+	}))
+	event := cuetest.GenerateEvent(cue.DEBUG, ctx, "debug event", nil, 0)
+
+	checkRendered(t, "debug event", RenderString(Message, event))
+	if calls != 0 {
+		t.Errorf("Expected deferred function to remain uncalled, but it was called %d times", calls)
+	}
+
+	checkRendered(t, `k1="deferred value"`, RenderString(HumanContext, event))
+	checkRendered(t, `{"k1":"deferred value"}`, RenderString(JSONContext, event))
+	checkRendered(t, `k1="deferred value"`, RenderString(StructuredContext, event))
+	if calls != 1 {
+		t.Errorf("Expected deferred function to be called exactly once, but it was called %d times", calls)
+	}
+}
+
+func TestByteSizeContext(t *testing.T) {
+	ctx := cue.NewContext("test").WithValue("bytes", cue.ByteSize(104857600))
+	event := cuetest.GenerateEvent(cue.DEBUG, ctx, "debug event", nil, 0)
+
+	checkRendered(t, "bytes=100MiB", RenderString(HumanContext, event))
+	checkRendered(t, `{"bytes":104857600}`, RenderString(JSONContext, event))
+}
+
+func TestDurationContext(t *testing.T) {
+	ctx := cue.NewContext("test").WithValue("elapsed", 1500*time.Millisecond)
+	event := cuetest.GenerateEvent(cue.DEBUG, ctx, "debug event", nil, 0)
+
+	checkRendered(t, "elapsed=1.5s", RenderString(HumanContext, event))
+	checkRendered(t, `{"elapsed":1500000000}`, RenderString(JSONContext, event))
+	checkRendered(t, `{"elapsed":1500}`, RenderString(JSONContextWith(JSONOptions{DurationUnit: time.Millisecond}), event))
+	checkRendered(t, `{"elapsed":1.5}`, RenderString(JSONContextWith(JSONOptions{DurationUnit: time.Second}), event))
+
+	expected := `{"time":"2006-01-02T15:04:00Z","level":"DEBUG","message":"debug event","fields":{"elapsed":1500}}`
+	checkRendered(t, expected, RenderString(JSON(JSONOptions{DurationUnit: time.Millisecond}), event))
+}
+
+func TestTimeContext(t *testing.T) {
+	stamp := time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)
+	ctx := cue.NewContext("test").WithValue("started", stamp)
+	event := cuetest.GenerateEvent(cue.DEBUG, ctx, "debug event", nil, 0)
+
+	checkRendered(t, `started="2006-01-02 15:04:05 +0000 UTC"`, RenderString(HumanContext, event))
+	checkRendered(t, `{"started":"2006-01-02T15:04:05Z"}`, RenderString(JSONContext, event))
+}
+
+func TestDurationField(t *testing.T) {
+	ctx := cue.NewContext("test").WithValue("elapsed", 1500*time.Millisecond).WithValue("other", "value")
+	event := cuetest.GenerateEvent(cue.DEBUG, ctx, "debug event", nil, 0)
+
+	checkRendered(t, "1500", RenderString(DurationField("elapsed", time.Millisecond), event))
+	checkRendered(t, "1.5", RenderString(DurationField("elapsed", time.Second), event))
+	checkRendered(t, "1500000000", RenderString(DurationField("elapsed", 0), event))
+	checkRendered(t, "value", RenderString(DurationField("other", time.Second), event))
+	checkRendered(t, "", RenderString(DurationField("missing", time.Second), event))
+}
+
+func TestMarkdownContext(t *testing.T) {
+	expected := "- **k1**: `some value`\n- **k2**: `2`\n- **k3**: `3.5`\n- **k4**: `true`"
+	checkRendered(t, expected, RenderString(MarkdownContext, cuetest.DebugEvent))
+
+	ctx := cue.NewContext("test").WithValue("a_*key*", "tick ` and\nnewline").WithValue("empty", "")
+	event := cuetest.GenerateEvent(cue.DEBUG, ctx, "debug event", nil, 0)
+	expected = "- **a\\_\\*key\\***: `` tick ` and newline ``\n- **empty**: `\"\"`"
+	checkRendered(t, expected, RenderString(MarkdownContext, event))
+}
+
+func TestMarkdown(t *testing.T) {
+	expected := "**ERROR** error event\n\n```\nerror message\n```\n\n" +
+		"- **k1**: `some value`\n- **k2**: `2`\n- **k3**: `3.5`\n- **k4**: `true`"
+	checkRendered(t, expected, RenderString(Markdown, cuetest.ErrorEvent))
+
+	event := cuetest.GenerateEvent(cue.INFO, cue.NewContext("test"), "info event", errors.New("has ``` fence"), 0)
+	checkRendered(t, "**INFO** info event\n\n````\nhas ``` fence\n````", RenderString(Markdown, event))
+	checkRendered(t, "**DEBUG** debug event", RenderString(Markdown, cuetest.GenerateEvent(cue.DEBUG, cue.NewContext("test"), "debug event", nil, 0)))
+}
+
+func TestFormEncodedContext(t *testing.T) {
+	checkRendered(t, "k1=some+value&k2=2&k3=3.5&k4=true", RenderString(FormEncodedContext, cuetest.DebugEvent))
+
+	ctx := cue.NewContext("test").WithValue("a&b", "c d&e=f").WithValue("x", "100%")
+	event := cuetest.GenerateEvent(cue.DEBUG, ctx, "debug event", nil, 0)
+	checkRendered(t, "a%26b=c+d%26e%3Df&x=100%25", RenderString(FormEncodedContext, event))
+
+	checkRendered(t, "", RenderString(FormEncodedContext, cuetest.GenerateEvent(cue.DEBUG, cue.NewContext("empty"), "debug event", nil, 0)))
+}
+
+func TestLogfmt(t *testing.T) {
+	expected := `time=2006-01-02T15:04:00Z level=debug msg="debug event" source=file3.go:3 k1="some value" k2=2 k3=3.5 k4=true`
+	checkRendered(t, expected, RenderString(Logfmt, cuetest.DebugEvent))
+
+	expected = `time=2006-01-02T15:04:00Z level=error msg="error event" error="error message" k1="some value" k2=2 k3=3.5 k4=true`
+	checkRendered(t, expected, RenderString(Logfmt, cuetest.ErrorEventNoFrames))
+
+	ctx := cue.NewContext("test").WithValue("a=b", "c=d").WithValue("empty", "").WithValue("quote", `"q"`).WithValue("key space", "日本")
+	event := cuetest.GenerateEvent(cue.INFO, ctx, "line1\nline2", nil, 0)
+	expected = `time=2006-01-02T15:04:00Z level=info msg="line1\nline2" a_b="c=d" empty="" key_space=日本 quote="\"q\""`
+	checkRendered(t, expected, RenderString(Logfmt, event))
+}
+
+func TestRenderNewTestEvent(t *testing.T) {
+	event := cue.NewTestEvent(
+		cue.EventLevel(cue.WARN),
+		cue.EventMessage("test message"),
+		cue.EventError(errors.New("test error")),
+		cue.EventTime(time.Date(2016, 1, 2, 15, 4, 5, 0, time.UTC)),
+		cue.EventContext(cue.NewContext("test").WithValue("k1", "v1")),
+		cue.EventFrames(2),
+	)
+
+	checkRendered(t, "Jan  2 15:04:05 WARN file2.go:2 test message: test error k1=v1", RenderString(HumanReadable, event))
+	checkRendered(t, "time=2016-01-02T15:04:05Z level=warn msg=\"test message\" error=\"test error\" source=file2.go:2 k1=v1", RenderString(Logfmt, event))
+	checkRendered(t, "example.com/frame2.function2", RenderString(Function, event))
+	checkRendered(t, "/path/example.com/frame2/file2.go:2", RenderString(Join(":", File, Line), event))
+}
+
 func TestJSONContext(t *testing.T) {
 	checkRendered(t, `{"k1":"some value","k2":2,"k3":3.5,"k4":true}`, RenderString(JSONContext, cuetest.DebugEvent))
 }
@@ -0,0 +1,205 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package hosted
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/collector"
+	"github.com/bobziuchkovski/cue/format"
+	"net/http"
+	"time"
+)
+
+const bugsnagPayloadVersion = "5"
+
+// Bugsnag represents configuration for the Bugsnag service.  Collected events
+// are sent to Bugsnag as error events, complete with relevant stack trace.
+// Bugsnag only supports error reporting, so collectors for the service should
+// only be registered at the ERROR or FATAL log levels.  FATAL events are
+// reported as unhandled errors.
+type Bugsnag struct {
+	// Required
+	APIKey string // Bugsnag project API key
+
+	// Optional
+	ReleaseStage string       // Release stage ("development", "production", etc.)
+	AppVersion   string       // Application version
+	ExtraContext cue.Context  // Additional context values to send with every event
+	Client       *http.Client // HTTP client for submitting events.  See the package docs for sharing clients.
+}
+
+// New returns a new collector based on the Bugsnag configuration.
+func (b Bugsnag) New() cue.Collector {
+	if b.APIKey == "" {
+		log.Warn("Bugsnag.New called to created a collector, but APIKey param is empty.  Returning nil collector.")
+		return nil
+	}
+	return &bugsnagCollector{
+		Bugsnag: b,
+		http:    collector.HTTP{RequestFormatter: b.formatRequest, Client: b.Client}.New(),
+	}
+}
+
+func (b Bugsnag) formatRequest(event *cue.Event) (request *http.Request, err error) {
+	body := format.RenderBytes(b.formatBody, event)
+	request, err = http.NewRequest("POST", "https://notify.bugsnag.com", bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Bugsnag-Api-Key", b.APIKey)
+	request.Header.Set("Bugsnag-Payload-Version", bugsnagPayloadVersion)
+	request.Header.Set("Bugsnag-Sent-At", time.Now().UTC().Format(time.RFC3339))
+	return
+}
+
+func (b Bugsnag) formatBody(buffer format.Buffer, event *cue.Event) {
+	post := &bugsnagPost{
+		APIKey: b.APIKey,
+		Notifier: bugsnagNotifier{
+			Name:    "github.com/bobziuchkovski/cue",
+			URL:     "https://github.com/bobziuchkovski/cue",
+			Version: fmt.Sprintf("%d.%d.%d", cue.Version.Major, cue.Version.Minor, cue.Version.Patch),
+		},
+		Events: []*bugsnagEvent{b.eventFor(event)},
+	}
+	marshalled, _ := json.Marshal(post)
+	buffer.Append(marshalled)
+}
+
+func (b Bugsnag) eventFor(event *cue.Event) *bugsnagEvent {
+	return &bugsnagEvent{
+		PayloadVersion: bugsnagPayloadVersion,
+		Exceptions: []*bugsnagException{{
+			ErrorClass: format.RenderString(format.ErrorType, event),
+			Message:    format.RenderString(format.MessageWithError, event),
+			Stacktrace: b.stacktraceFor(event),
+			Type:       "go",
+		}},
+		Context:   b.contextFor(event),
+		Severity:  bugsnagSeverity(event.Level),
+		Unhandled: event.Level == cue.FATAL,
+		App: bugsnagApp{
+			Version:      b.AppVersion,
+			ReleaseStage: b.ReleaseStage,
+		},
+		Device: bugsnagDevice{
+			Hostname: format.RenderString(format.FQDN, event),
+			Time:     event.Time.UTC().Format(time.RFC3339),
+		},
+		MetaData: map[string]cue.Fields{
+			"context": reportContext(event, b.ExtraContext).Fields(),
+		},
+	}
+}
+
+func (b Bugsnag) contextFor(event *cue.Event) string {
+	if len(event.Frames) == 0 || event.Frames[0].Function == cue.UnknownFunction {
+		return ""
+	}
+	return event.Frames[0].Function
+}
+
+func (b Bugsnag) stacktraceFor(event *cue.Event) []*bugsnagFrame {
+	// Bugsnag expects the innermost frame first, which matches our ordering.
+	stacktrace := []*bugsnagFrame{}
+	for _, frame := range event.Frames {
+		stacktrace = append(stacktrace, &bugsnagFrame{
+			File:       frame.File,
+			LineNumber: frame.Line,
+			Method:     frame.Function,
+		})
+	}
+	return stacktrace
+}
+
+func bugsnagSeverity(level cue.Level) string {
+	switch level {
+	case cue.FATAL, cue.ERROR:
+		return "error"
+	case cue.WARN:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+type bugsnagCollector struct {
+	Bugsnag
+	http cue.Collector
+}
+
+func (b *bugsnagCollector) String() string {
+	return fmt.Sprintf("Bugsnag(release_stage=%q, app_version=%q)", b.ReleaseStage, b.AppVersion)
+}
+
+func (b *bugsnagCollector) Collect(event *cue.Event) error {
+	return b.http.Collect(event)
+}
+
+type bugsnagPost struct {
+	APIKey   string          `json:"apiKey"`
+	Notifier bugsnagNotifier `json:"notifier"`
+	Events   []*bugsnagEvent `json:"events"`
+}
+
+type bugsnagNotifier struct {
+	Name    string `json:"name"`
+	URL     string `json:"url"`
+	Version string `json:"version"`
+}
+
+type bugsnagEvent struct {
+	PayloadVersion string                `json:"payloadVersion"`
+	Exceptions     []*bugsnagException   `json:"exceptions"`
+	Context        string                `json:"context,omitempty"`
+	Severity       string                `json:"severity"`
+	Unhandled      bool                  `json:"unhandled"`
+	App            bugsnagApp            `json:"app"`
+	Device         bugsnagDevice         `json:"device"`
+	MetaData       map[string]cue.Fields `json:"metaData"`
+}
+
+type bugsnagException struct {
+	ErrorClass string          `json:"errorClass"`
+	Message    string          `json:"message"`
+	Stacktrace []*bugsnagFrame `json:"stacktrace"`
+	Type       string          `json:"type"`
+}
+
+type bugsnagFrame struct {
+	File       string `json:"file"`
+	LineNumber int    `json:"lineNumber"`
+	Method     string `json:"method"`
+}
+
+type bugsnagApp struct {
+	Version      string `json:"version,omitempty"`
+	ReleaseStage string `json:"releaseStage,omitempty"`
+}
+
+type bugsnagDevice struct {
+	Hostname string `json:"hostname"`
+	Time     string `json:"time"`
+}
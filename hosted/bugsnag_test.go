@@ -0,0 +1,233 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package hosted
+
+import (
+	"errors"
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/format"
+	"github.com/bobziuchkovski/cue/internal/cuetest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const bugsnagJSON = `
+{
+  "apiKey": "test",
+  "notifier": {
+    "name": "github.com/bobziuchkovski/cue",
+    "url": "https://github.com/bobziuchkovski/cue",
+    "version": %q
+  },
+  "events": [
+    {
+      "payloadVersion": "5",
+      "exceptions": [
+        {
+          "errorClass": "errors.errorString",
+          "message": "error event: error message",
+          "stacktrace": [
+            {
+              "file": "/path/github.com/bobziuchkovski/cue/frame3/file3.go",
+              "lineNumber": 3,
+              "method": "github.com/bobziuchkovski/cue/frame3.function3"
+            },
+            {
+              "file": "/path/github.com/bobziuchkovski/cue/frame2/file2.go",
+              "lineNumber": 2,
+              "method": "github.com/bobziuchkovski/cue/frame2.function2"
+            },
+            {
+              "file": "/path/github.com/bobziuchkovski/cue/frame1/file1.go",
+              "lineNumber": 1,
+              "method": "github.com/bobziuchkovski/cue/frame1.function1"
+            }
+          ],
+          "type": "go"
+        }
+      ],
+      "context": "github.com/bobziuchkovski/cue/frame3.function3",
+      "severity": "error",
+      "unhandled": false,
+      "app": {
+        "version": "1.2.3",
+        "releaseStage": "test"
+      },
+      "device": {
+        "hostname": %q,
+        "time": %q
+      },
+      "metaData": {
+        "context": {
+          "extra": "extra value",
+          "k1": "some value",
+          "k2": 2,
+          "k3": 3.5,
+          "k4": true
+        }
+      }
+    }
+  ]
+}
+`
+
+const bugsnagNoFramesJSON = `
+{
+  "apiKey": "test",
+  "notifier": {
+    "name": "github.com/bobziuchkovski/cue",
+    "url": "https://github.com/bobziuchkovski/cue",
+    "version": %q
+  },
+  "events": [
+    {
+      "payloadVersion": "5",
+      "exceptions": [
+        {
+          "errorClass": "errors.errorString",
+          "message": "error event: error message",
+          "stacktrace": [],
+          "type": "go"
+        }
+      ],
+      "severity": "error",
+      "unhandled": false,
+      "app": {
+        "version": "1.2.3",
+        "releaseStage": "test"
+      },
+      "device": {
+        "hostname": %q,
+        "time": %q
+      },
+      "metaData": {
+        "context": {
+          "extra": "extra value",
+          "k1": "some value",
+          "k2": 2,
+          "k3": 3.5,
+          "k4": true
+        }
+      }
+    }
+  ]
+}
+`
+
+func TestBugsnagNilCollector(t *testing.T) {
+	c := Bugsnag{}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when the API key is missing, but got %s instead", c)
+	}
+}
+
+func TestBugsnag(t *testing.T) {
+	checkBugsnagEvent(t, cuetest.ErrorEvent, bugsnagJSON)
+}
+
+func TestBugsnagNoFrames(t *testing.T) {
+	checkBugsnagEvent(t, cuetest.ErrorEventNoFrames, bugsnagNoFramesJSON)
+}
+
+func TestBugsnagHeaders(t *testing.T) {
+	req, err := getBugsnagCollector().formatRequest(cuetest.ErrorEvent)
+	if err != nil {
+		t.Fatalf("Encountered unexpected error formatting http request: %s", err)
+	}
+	if req.Method != "POST" || req.URL.String() != "https://notify.bugsnag.com" {
+		t.Errorf("Expected a POST to the notify endpoint, but saw %s %s instead", req.Method, req.URL)
+	}
+	expectations := map[string]string{
+		"Bugsnag-Api-Key":         "test",
+		"Bugsnag-Payload-Version": "5",
+		"Content-Type":            "application/json",
+	}
+	for k, v := range expectations {
+		if req.Header.Get(k) != v {
+			t.Errorf("Expected header %s to be %q, but saw %q instead", k, v, req.Header.Get(k))
+		}
+	}
+	if req.Header.Get("Bugsnag-Sent-At") == "" {
+		t.Error("Expected the Bugsnag-Sent-At header to be set, but it wasn't")
+	}
+}
+
+func TestBugsnagUnhandled(t *testing.T) {
+	event := cuetest.GenerateEvent(cue.FATAL, cuetest.FatalEvent.Context, "fatal event", errors.New("fatal message"), 0)
+	req, err := getBugsnagCollector().formatRequest(event)
+	if err != nil {
+		t.Fatalf("Encountered unexpected error formatting http request: %s", err)
+	}
+	events := cuetest.NestedFetch(cuetest.ParseRequestJSON(req), "events").([]interface{})
+	decoded := events[0].(map[string]interface{})
+	if decoded["unhandled"] != true || decoded["severity"] != "error" {
+		t.Errorf("Expected FATAL events to be reported as unhandled errors, but got %v instead", decoded)
+	}
+}
+
+func TestBugsnagSeverity(t *testing.T) {
+	m := map[cue.Level]string{
+		cue.TRACE: "info",
+		cue.DEBUG: "info",
+		cue.INFO:  "info",
+		cue.WARN:  "warning",
+		cue.ERROR: "error",
+		cue.FATAL: "error",
+	}
+	for k, v := range m {
+		if bugsnagSeverity(k) != v {
+			t.Errorf("Expected cue level %q to map to bugsnag severity %q but it didn't", k, v)
+		}
+	}
+}
+
+func TestBugsnagString(t *testing.T) {
+	_ = fmt.Sprint(getBugsnagCollector())
+}
+
+func checkBugsnagEvent(t *testing.T, event *cue.Event, expected string) {
+	req, err := getBugsnagCollector().formatRequest(event)
+	if err != nil {
+		t.Errorf("Encountered unexpected error formatting http request: %s", err)
+	}
+	version := fmt.Sprintf("%d.%d.%d", cue.Version.Major, cue.Version.Minor, cue.Version.Patch)
+	hostname := format.RenderString(format.FQDN, event)
+	requestJSON := cuetest.ParseRequestJSON(req)
+	timestamp := event.Time.UTC().Format(time.RFC3339)
+	expectedJSON := cuetest.ParseStringJSON(fmt.Sprintf(expected, version, hostname, timestamp))
+	cuetest.NestedCompare(t, requestJSON, expectedJSON)
+}
+
+func getBugsnagCollector() *bugsnagCollector {
+	c := Bugsnag{
+		APIKey:       "test",
+		ReleaseStage: "test",
+		AppVersion:   "1.2.3",
+		ExtraContext: cue.NewContext("extra").WithValue("extra", "extra value"),
+	}.New()
+	bc, ok := c.(*bugsnagCollector)
+	if !ok {
+		panic(fmt.Sprintf("Expected to see a *bugsnagCollector but got %s instead", reflect.TypeOf(c)))
+	}
+	return bc
+}
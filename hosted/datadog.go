@@ -0,0 +1,219 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package hosted
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/collector"
+	"github.com/bobziuchkovski/cue/format"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	datadogDefaultHost = "http-intake.logs.datadoghq.com"
+	datadogMaxBatch    = 1000
+)
+
+// Datadog represents configuration for the Datadog logs service.  Events are
+// buffered and POSTed to the HTTPS intake as JSON arrays once BatchSize
+// events are pending or FlushInterval has elapsed, whichever comes first.
+// Pending events are flushed on Close.  Each event is rendered as a JSON
+// object with the following attributes:
+//
+//	message      The event message and error (see format.MessageWithError)
+//	status       The Datadog status: debug, info, warn, error, or critical
+//	timestamp    Milliseconds since the Unix epoch
+//	hostname     Host, or the local hostname (see format.FQDN) if Host is empty
+//	service      Service.  Omitted if empty.
+//	ddsource     Source.  Omitted if empty.
+//	ddtags       Tags, joined by commas.  Omitted if empty.
+//	logger       An object with the event context name
+//	error        An object with the error message, type, and stack.  Omitted
+//	             if the event's Error field is nil.
+//	<key>        Each context field
+//
+// Context keys that collide with the attributes above are written with a
+// leading underscore, e.g. "_status".
+//
+// Events are sent to the US intake by default.  Set Intake to the regional
+// intake host for accounts hosted elsewhere, e.g.
+// "http-intake.logs.datadoghq.eu" for the EU region.
+type Datadog struct {
+	// Required
+	APIKey string // Datadog API key
+
+	// Optional
+	Service       string        // Service name
+	Source        string        // Log source, e.g. "go"
+	Tags          []string      // Tags in "key:value" form to send with every event
+	Host          string        // Hostname to report.  Default: the local hostname.
+	Intake        string        // Intake host.  Default: "http-intake.logs.datadoghq.com"
+	BatchSize     int           // Default: 100.  Datadog accepts at most 1000 events per request.
+	FlushInterval time.Duration // Default: 5 seconds
+	Client        *http.Client  // HTTP client for submitting events.  See the package docs for sharing clients.
+}
+
+// New returns a new collector based on the Datadog configuration.
+func (d Datadog) New() cue.Collector {
+	if d.APIKey == "" {
+		log.Warn("Datadog.New called to created a collector, but the APIKey param is empty.  Returning nil collector.")
+		return nil
+	}
+	if d.Intake == "" {
+		d.Intake = datadogDefaultHost
+	}
+	if d.BatchSize <= 0 {
+		d.BatchSize = 100
+	}
+	if d.BatchSize > datadogMaxBatch {
+		d.BatchSize = datadogMaxBatch
+	}
+	return &datadogCollector{
+		Datadog: d,
+		http: collector.BatchHTTP{
+			RequestFormatter: d.formatRequest,
+			MaxSize:          d.BatchSize,
+			MaxDelay:         d.FlushInterval,
+			Client:           d.Client,
+		}.New(),
+	}
+}
+
+func (d Datadog) formatRequest(events []*cue.Event) (request *http.Request, err error) {
+	entries := make([]map[string]interface{}, len(events))
+	for i, event := range events {
+		entries[i] = d.entryFor(event)
+	}
+	body, err := json.Marshal(entries)
+	if err != nil {
+		return
+	}
+
+	request, err = http.NewRequest("POST", fmt.Sprintf("https://%s/api/v2/logs", d.Intake), bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	request.Header.Set("DD-API-KEY", d.APIKey)
+	request.Header.Set("Content-Type", "application/json")
+	return
+}
+
+func (d Datadog) entryFor(event *cue.Event) map[string]interface{} {
+	entry := make(map[string]interface{})
+	for k, v := range reportContext(event, nil).Fields() {
+		if datadogReserved[k] {
+			k = "_" + k
+		}
+		if _, err := json.Marshal(v); err != nil {
+			v = fmt.Sprint(v)
+		}
+		entry[k] = v
+	}
+
+	host := d.Host
+	if host == "" {
+		host = format.RenderString(format.FQDN, event)
+	}
+	entry["message"] = format.RenderString(format.MessageWithError, event)
+	entry["status"] = datadogStatus(event.Level)
+	entry["timestamp"] = event.Time.UnixNano() / int64(time.Millisecond)
+	entry["hostname"] = host
+	entry["logger"] = map[string]string{"name": event.Context.Name()}
+	if d.Service != "" {
+		entry["service"] = d.Service
+	}
+	if d.Source != "" {
+		entry["ddsource"] = d.Source
+	}
+	if len(d.Tags) > 0 {
+		entry["ddtags"] = strings.Join(d.Tags, ",")
+	}
+	if event.Error != nil {
+		entry["error"] = datadogError{
+			Message: event.Error.Error(),
+			Kind:    format.RenderString(format.ErrorType, event),
+			Stack:   format.RenderString(format.StackString, event),
+		}
+	}
+	return entry
+}
+
+var datadogReserved = map[string]bool{
+	"message":   true,
+	"status":    true,
+	"timestamp": true,
+	"hostname":  true,
+	"service":   true,
+	"ddsource":  true,
+	"ddtags":    true,
+	"logger":    true,
+	"error":     true,
+}
+
+type datadogError struct {
+	Message string `json:"message"`
+	Kind    string `json:"kind"`
+	Stack   string `json:"stack,omitempty"`
+}
+
+func datadogStatus(level cue.Level) string {
+	switch level {
+	case cue.TRACE, cue.DEBUG:
+		return "debug"
+	case cue.INFO:
+		return "info"
+	case cue.WARN:
+		return "warn"
+	case cue.ERROR:
+		return "error"
+	default:
+		return "critical"
+	}
+}
+
+type datadogCollector struct {
+	Datadog
+	http cue.Collector
+}
+
+func (d *datadogCollector) String() string {
+	return fmt.Sprintf("Datadog(intake=%s, service=%s)", d.Intake, d.Service)
+}
+
+func (d *datadogCollector) Collect(event *cue.Event) error {
+	return d.http.Collect(event)
+}
+
+func (d *datadogCollector) Close() error {
+	return d.http.(io.Closer).Close()
+}
+
+func (d *datadogCollector) Flush() error {
+	return d.http.(interface {
+		Flush() error
+	}).Flush()
+}
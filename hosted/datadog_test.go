@@ -0,0 +1,155 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package hosted
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/internal/cuetest"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestDatadogNilCollector(t *testing.T) {
+	c := Datadog{}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when the API key is missing, but got %s instead", c)
+	}
+}
+
+func TestDatadog(t *testing.T) {
+	recorder := cuetest.NewHTTPRequestRecorder()
+	c := Datadog{
+		APIKey:        "secret",
+		Service:       "api",
+		Source:        "go",
+		Tags:          []string{"env:test", "team:core"},
+		Host:          "example.com",
+		FlushInterval: time.Hour,
+		Client:        &http.Client{Transport: recorder},
+	}.New()
+
+	c.Collect(cuetest.DebugEvent)
+	c.Collect(cuetest.ErrorEvent)
+	cuetest.CloseCollector(c)
+	if len(recorder.Requests()) != 1 {
+		t.Fatalf("Expected exactly 1 request to be sent but saw %d instead", len(recorder.Requests()))
+	}
+
+	req := recorder.Requests()[0]
+	if req.Method != "POST" || req.Host != "http-intake.logs.datadoghq.com" || req.URL.Path != "/api/v2/logs" {
+		t.Errorf("Expected a POST to the US intake, but saw %s %s%s instead", req.Method, req.Host, req.URL.Path)
+	}
+	if req.Header.Get("DD-API-KEY") != "secret" {
+		t.Errorf("Expected the API key header to be set, but saw %q instead", req.Header.Get("DD-API-KEY"))
+	}
+	if req.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Expected a JSON content type, but saw %q instead", req.Header.Get("Content-Type"))
+	}
+
+	entries := decodeDatadogBody(t, req)
+	if len(entries) != 2 {
+		t.Fatalf("Expected the request to contain 2 entries, but saw %d instead", len(entries))
+	}
+	expectations := map[string]interface{}{
+		"message":   "error event: error message",
+		"status":    "error",
+		"timestamp": float64(cuetest.ErrorEvent.Time.UnixNano() / int64(time.Millisecond)),
+		"hostname":  "example.com",
+		"service":   "api",
+		"ddsource":  "go",
+		"ddtags":    "env:test,team:core",
+		"k1":        "some value",
+		"k2":        float64(2),
+	}
+	for k, v := range expectations {
+		if entries[1][k] != v {
+			t.Errorf("Expected %q to be %v but got %v instead", k, v, entries[1][k])
+		}
+	}
+	errObj, ok := entries[1]["error"].(map[string]interface{})
+	if !ok || errObj["message"] != "error message" || errObj["kind"] != "errors.errorString" || errObj["stack"] == "" {
+		t.Errorf("Expected an error object with message, kind, and stack, but got %v instead", entries[1]["error"])
+	}
+	if entries[0]["status"] != "debug" || entries[0]["error"] != nil {
+		t.Errorf("Expected a debug entry without an error object, but got %v instead", entries[0])
+	}
+}
+
+func TestDatadogReservedKeys(t *testing.T) {
+	recorder := cuetest.NewHTTPRequestRecorder()
+	c := Datadog{APIKey: "secret", Intake: "http-intake.logs.datadoghq.eu", Client: &http.Client{Transport: recorder}}.New()
+
+	ctx := cue.NewContext("test").WithValue("status", "bogus").WithValue("c", complex(1, 2))
+	c.Collect(cuetest.GenerateEvent(cue.INFO, ctx, "info event", nil, 0))
+	cuetest.CloseCollector(c)
+
+	req := recorder.Requests()[0]
+	if req.Host != "http-intake.logs.datadoghq.eu" {
+		t.Errorf("Expected a request to the EU intake, but saw %s instead", req.Host)
+	}
+	entry := decodeDatadogBody(t, req)[0]
+	if entry["status"] != "info" || entry["_status"] != "bogus" || entry["c"] != "(1+2i)" {
+		t.Errorf("Expected reserved keys to be prefixed and unmarshalable values stringified, but got %v instead", entry)
+	}
+	logger, ok := entry["logger"].(map[string]interface{})
+	if !ok || logger["name"] != "test" {
+		t.Errorf("Expected a logger object with the context name, but got %v instead", entry["logger"])
+	}
+}
+
+func TestDatadogStatus(t *testing.T) {
+	m := map[cue.Level]string{
+		cue.TRACE: "debug",
+		cue.DEBUG: "debug",
+		cue.INFO:  "info",
+		cue.WARN:  "warn",
+		cue.ERROR: "error",
+		cue.FATAL: "critical",
+	}
+	for k, v := range m {
+		if datadogStatus(k) != v {
+			t.Errorf("Expected cue level %q to map to datadog status %q but it didn't", k, v)
+		}
+	}
+}
+
+func TestDatadogString(t *testing.T) {
+	c := Datadog{APIKey: "secret", Service: "api"}.New()
+	defer cuetest.CloseCollector(c)
+	_ = fmt.Sprint(c)
+}
+
+func decodeDatadogBody(t *testing.T, req *http.Request) []map[string]interface{} {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		t.Fatalf("Encountered unexpected error reading request body: %s", err)
+	}
+	var entries []map[string]interface{}
+	err = json.Unmarshal(body, &entries)
+	if err != nil {
+		t.Fatalf("Expected request body to be a valid JSON array, but received error: %s", err)
+	}
+	return entries
+}
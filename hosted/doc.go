@@ -20,7 +20,9 @@
 
 /*
 Package hosted implements event collection for hosted third-party services.
-Collectors are provided for Honeybadger, Loggly, Opbeat, Rollbar, and Sentry.
+Collectors are provided for Bugsnag, Datadog, Honeybadger, Loggly (via syslog
+or HTTPS), Opbeat, Papertrail, Rollbar, Sentry, Slack, and Stackdriver
+(Google Cloud Logging).
 Additional collectors will be added upon request.
 
 Inclusion Criteria
@@ -51,6 +53,28 @@ Increasing the number of frames collected for ERROR and FATAL events is a
 good idea when using error reporting services.  See the cue.SetFrames docs for
 details.
 
+Sharing HTTP Clients
+
+The Bugsnag, Datadog, Honeybadger, LogglyHTTP, Opbeat, Rollbar, Sentry, and
+Slack collectors submit events via HTTP.  By default, these collectors share a
+single http.Client, and thus a single connection pool, with the
+cue/collector.HTTP collector.  A custom *http.Client may be specified via the
+collectors' Client param.  The Stackdriver collector accepts a Client as well,
+but it has no default: it only submits events via HTTP when given a client
+that's authorized to write log entries.  Passing the same client to multiple
+collectors allows them to reuse connections and bounds the total number of
+connections used for event submission:
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	cue.CollectAsync(cue.ERROR, 10000, hosted.Honeybadger{
+		Key:    os.Getenv("HONEYBADGER_KEY"),
+		Client: client,
+	}.New())
+	cue.CollectAsync(cue.ERROR, 10000, hosted.Sentry{
+		DSN:    os.Getenv("SENTRY_DSN"),
+		Client: client,
+	}.New())
+
 Nil Instances
 
 Collector implementations emit a WARN log event and return a nil collector
@@ -40,9 +40,10 @@ type Honeybadger struct {
 	Key string // Honeybadger API key
 
 	// Optional
-	Tags         []string    // Tags to send with every event
-	ExtraContext cue.Context // Additional context values to send with every event
-	Environment  string      // Environment name ("development", "production", etc.)
+	Tags         []string     // Tags to send with every event
+	ExtraContext cue.Context  // Additional context values to send with every event
+	Environment  string       // Environment name ("development", "production", etc.)
+	Client       *http.Client // HTTP client for submitting events.  See the package docs for sharing clients.
 }
 
 // New returns a new collector based on the Honeybadger configuration.
@@ -53,7 +54,7 @@ func (h Honeybadger) New() cue.Collector {
 	}
 	return &honeybadgerCollector{
 		Honeybadger: h,
-		http:        collector.HTTP{RequestFormatter: h.formatRequest}.New(),
+		http:        collector.HTTP{RequestFormatter: h.formatRequest, Client: h.Client}.New(),
 	}
 }
 
@@ -90,7 +91,7 @@ func (h Honeybadger) requestFor(event *cue.Event) honeybadgerRequest {
 		pkg = event.Frames[0].Package
 	}
 	return honeybadgerRequest{
-		Context:   cue.JoinContext("", event.Context, h.ExtraContext).Fields(),
+		Context:   reportContext(event, h.ExtraContext).Fields(),
 		Component: pkg,
 	}
 }
@@ -21,9 +21,11 @@
 package hosted
 
 import (
+	"errors"
 	"fmt"
 	"github.com/bobziuchkovski/cue"
 	"github.com/bobziuchkovski/cue/internal/cuetest"
+	"net/http"
 	"reflect"
 	"testing"
 )
@@ -123,10 +125,42 @@ func TestHoneybadgerNoFrames(t *testing.T) {
 	checkHoneybadgerEvent(t, cuetest.ErrorEventNoFrames, honeybadgerNoFramesJSON)
 }
 
+func TestHoneybadgerDiagnostics(t *testing.T) {
+	event := cuetest.GenerateEvent(cue.FATAL, cuetest.FatalEvent.Context, "fatal event", errors.New("fatal message"), 0)
+	event.Goroutines = 42
+
+	req, err := getHoneybadgerCollector().formatRequest(event)
+	if err != nil {
+		t.Errorf("Encountered unexpected error formatting http request: %s", err)
+	}
+	goroutines := cuetest.NestedFetch(cuetest.ParseRequestJSON(req), "request", "context", "goroutines")
+	if fmt.Sprint(goroutines) != "42" {
+		t.Errorf("Expected goroutines context value of 42 but saw %v instead", goroutines)
+	}
+}
+
 func TestHoneybadgerString(t *testing.T) {
 	_ = fmt.Sprint(getHoneybadgerCollector())
 }
 
+func TestHoneybadgerSharedClient(t *testing.T) {
+	recorder := cuetest.NewHTTPRequestRecorder()
+	client := &http.Client{Transport: recorder}
+
+	c1 := Honeybadger{Key: "test", Client: client}.New()
+	c2 := Opbeat{Token: "test", AppID: "test", OrganizationID: "test", Client: client}.New()
+
+	for _, c := range []cue.Collector{c1, c2} {
+		err := c.Collect(cuetest.ErrorEvent)
+		if err != nil {
+			t.Errorf("Encountered unexpected error: %s", err)
+		}
+	}
+	if len(recorder.Requests()) != 2 {
+		t.Errorf("Expected both collectors to submit requests via the shared client, but saw %d requests instead", len(recorder.Requests()))
+	}
+}
+
 func checkHoneybadgerEvent(t *testing.T, event *cue.Event, expected string) {
 	req, err := getHoneybadgerCollector().formatRequest(event)
 	if err != nil {
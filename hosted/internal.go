@@ -25,3 +25,13 @@ import (
 )
 
 var log = cue.NewLogger("github.com/bobziuchkovski/cue/hosted")
+
+// reportContext joins the event's context with extra and adds diagnostic
+// values for FATAL events.
+func reportContext(event *cue.Event, extra cue.Context) cue.Context {
+	joined := cue.JoinContext("", event.Context, extra)
+	if event.Goroutines > 0 {
+		joined = joined.WithValue("goroutines", event.Goroutines)
+	}
+	return joined
+}
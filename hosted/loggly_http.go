@@ -0,0 +1,98 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package hosted
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/collector"
+	"github.com/bobziuchkovski/cue/format"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const logglyHTTPURL = "https://logs-01.loggly.com/inputs/"
+
+var logglyHTTPFormatter = format.JSON(format.JSONOptions{Stack: true})
+
+// LogglyHTTP represents configuration for the Loggly service using Loggly's
+// HTTPS event endpoint.  Unlike the Loggly collector, which uses syslog
+// transport, LogglyHTTP always encrypts events in transit and doesn't require
+// an app name or facility.  Each event is POSTed as a JSON object containing
+// the event level, message, error, stack frames, and context fields.  See
+// format.JSON for the object layout.
+type LogglyHTTP struct {
+	// Required
+	Token string // Loggly customer token
+
+	// Optional
+	Tags   []string     // Tags to send with every event
+	Client *http.Client // HTTP client for submitting events.  See the package docs for sharing clients.
+}
+
+// New returns a new collector based on the LogglyHTTP configuration.
+func (l LogglyHTTP) New() cue.Collector {
+	if l.Token == "" {
+		log.Warn("LogglyHTTP.New called to created a collector, but the Token param is empty.  Returning nil collector.")
+		return nil
+	}
+	return &logglyHTTPCollector{
+		LogglyHTTP: l,
+		http:       collector.HTTP{RequestFormatter: l.formatRequest, Client: l.Client}.New(),
+	}
+}
+
+func (l LogglyHTTP) formatRequest(event *cue.Event) (request *http.Request, err error) {
+	body := format.RenderBytes(logglyHTTPFormatter, event)
+	request, err = http.NewRequest("POST", l.url(), bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	request.Header.Set("Content-Type", "application/json")
+	return
+}
+
+func (l LogglyHTTP) url() string {
+	u := logglyHTTPURL + url.PathEscape(l.Token) + "/"
+	if len(l.Tags) == 0 {
+		return u
+	}
+	escaped := make([]string, len(l.Tags))
+	for i, tag := range l.Tags {
+		escaped[i] = url.PathEscape(tag)
+	}
+	return u + "tag/" + strings.Join(escaped, ",") + "/"
+}
+
+type logglyHTTPCollector struct {
+	LogglyHTTP
+	http cue.Collector
+}
+
+func (l *logglyHTTPCollector) String() string {
+	return fmt.Sprintf("LogglyHTTP(tags=%s)", strings.Join(l.Tags, ","))
+}
+
+func (l *logglyHTTPCollector) Collect(event *cue.Event) error {
+	return l.http.Collect(event)
+}
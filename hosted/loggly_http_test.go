@@ -0,0 +1,103 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package hosted
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/bobziuchkovski/cue/internal/cuetest"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestLogglyHTTPNilCollector(t *testing.T) {
+	c := LogglyHTTP{}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when the token is missing, but got %s instead", c)
+	}
+}
+
+func TestLogglyHTTP(t *testing.T) {
+	recorder := cuetest.NewHTTPRequestRecorder()
+	c := LogglyHTTP{
+		Token:  "test",
+		Tags:   []string{"tag1", "tag2"},
+		Client: &http.Client{Transport: recorder},
+	}.New()
+
+	err := c.Collect(cuetest.ErrorEvent)
+	if err != nil {
+		t.Errorf("Encountered unexpected error: %s", err)
+	}
+	if len(recorder.Requests()) != 1 {
+		t.Fatalf("Expected exactly 1 request to be sent but saw %d instead", len(recorder.Requests()))
+	}
+
+	req := recorder.Requests()[0]
+	if req.Method != "POST" {
+		t.Errorf("Expected a POST request, but saw %s instead", req.Method)
+	}
+	if req.Host != "logs-01.loggly.com" || req.URL.Path != "/inputs/test/tag/tag1,tag2/" {
+		t.Errorf("Expected a request to the tagged inputs URL, but saw %s%s instead", req.Host, req.URL.Path)
+	}
+	if req.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Expected a JSON content type, but saw %q instead", req.Header.Get("Content-Type"))
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		t.Fatalf("Encountered unexpected error reading request body: %s", err)
+	}
+	var decoded map[string]interface{}
+	err = json.Unmarshal(body, &decoded)
+	if err != nil {
+		t.Fatalf("Expected request body to be valid JSON, but received error: %s", err)
+	}
+	expectations := map[string]interface{}{
+		"level":   "ERROR",
+		"message": "error event",
+		"error":   "error message",
+	}
+	for k, v := range expectations {
+		if decoded[k] != v {
+			t.Errorf("Expected %q to be %v but got %v instead", k, v, decoded[k])
+		}
+	}
+	if decoded["stack"] == nil {
+		t.Error("Expected the request body to contain stack frames but it didn't")
+	}
+	fields, ok := decoded["fields"].(map[string]interface{})
+	if !ok || fields["k1"] != "some value" {
+		t.Errorf("Expected the request body to contain context fields, but got %v instead", decoded["fields"])
+	}
+}
+
+func TestLogglyHTTPNoTags(t *testing.T) {
+	l := LogglyHTTP{Token: "test"}
+	if l.url() != "https://logs-01.loggly.com/inputs/test/" {
+		t.Errorf("Expected the untagged inputs URL, but got %s instead", l.url())
+	}
+}
+
+func TestLogglyHTTPString(t *testing.T) {
+	_ = fmt.Sprint(LogglyHTTP{Token: "test", Tags: []string{"tag1"}}.New())
+}
@@ -40,7 +40,8 @@ type Opbeat struct {
 	OrganizationID string // Organization ID
 
 	// Optional
-	ExtraContext cue.Context // Additional context values to send with every event
+	ExtraContext cue.Context  // Additional context values to send with every event
+	Client       *http.Client // HTTP client for submitting events.  See the package docs for sharing clients.
 }
 
 // New returns a new collector based on the Opbeat configuration.
@@ -51,7 +52,7 @@ func (o Opbeat) New() cue.Collector {
 	}
 	return &opbeatCollector{
 		Opbeat: o,
-		http:   collector.HTTP{RequestFormatter: o.formatRequest}.New(),
+		http:   collector.HTTP{RequestFormatter: o.formatRequest, Client: o.Client}.New(),
 	}
 }
 
@@ -75,7 +76,7 @@ func (o Opbeat) formatBody(buffer format.Buffer, event *cue.Event) {
 		Logger:     event.Context.Name(),
 		Message:    format.RenderString(format.MessageWithError, event),
 		Culprit:    o.culpritFor(event),
-		Extra:      cue.JoinContext("", event.Context, o.ExtraContext).Fields(),
+		Extra:      reportContext(event, o.ExtraContext).Fields(),
 		Exception:  o.exceptionFor(event),
 		Stacktrace: o.stacktraceFor(event),
 		Machine: opbeatMachine{
@@ -170,7 +171,7 @@ type opbeatMachine struct {
 
 func opbeatLevel(level cue.Level) string {
 	switch level {
-	case cue.DEBUG:
+	case cue.TRACE, cue.DEBUG:
 		return "debug"
 	case cue.INFO:
 		return "info"
@@ -116,6 +116,7 @@ func TestOpbeatString(t *testing.T) {
 
 func TestOpbeatLevels(t *testing.T) {
 	m := map[cue.Level]string{
+		cue.TRACE: "debug",
 		cue.DEBUG: "debug",
 		cue.INFO:  "info",
 		cue.WARN:  "warning",
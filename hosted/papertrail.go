@@ -0,0 +1,105 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package hosted
+
+import (
+	"crypto/tls"
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/collector"
+	"github.com/bobziuchkovski/cue/format"
+	"io"
+	"net"
+	"strconv"
+)
+
+// Papertrail represents configuration for the Papertrail service.  Events are
+// sent as RFC 5424 syslog messages over a TLS-encrypted TCP connection, which
+// is the transport Papertrail recommends.  Host and Port are the log
+// destination shown on Papertrail's "Log Destinations" page, e.g.
+// "logs2.papertrailapp.com" and 12345.
+//
+// Messages are rendered via format.HumanMessage by default, which includes
+// the event context.  Context data isn't duplicated in the syslog structured
+// data section.
+type Papertrail struct {
+	// Required
+	Host     string             // Log destination host, e.g. "logs2.papertrailapp.com"
+	Port     int                // Log destination port
+	App      string             // Syslog app name
+	Facility collector.Facility // Syslog facility
+
+	// Optional
+	Formatter format.Formatter // Default: format.HumanMessage
+	TLS       *tls.Config      // Default: verifies Host via the system root CAs
+}
+
+// New returns a new collector based on the Papertrail configuration.
+func (p Papertrail) New() cue.Collector {
+	if p.Host == "" {
+		log.Warn("Papertrail.New called to created a collector, but the Host param is empty.  Returning nil collector.")
+		return nil
+	}
+	if p.Port <= 0 {
+		log.Warn("Papertrail.New called to created a collector, but the Port param is empty.  Returning nil collector.")
+		return nil
+	}
+	if p.App == "" {
+		log.Warn("Papertrail.New called to created a collector, but the App param is empty.  Returning nil collector.")
+		return nil
+	}
+
+	if p.Formatter == nil {
+		p.Formatter = format.HumanMessage
+	}
+	if p.TLS == nil {
+		p.TLS = &tls.Config{ServerName: p.Host}
+	}
+
+	return &papertrailCollector{
+		Papertrail: p,
+		syslog: collector.StructuredSyslog{
+			Facility:            p.Facility,
+			App:                 p.App,
+			Network:             "tcp",
+			Address:             net.JoinHostPort(p.Host, strconv.Itoa(p.Port)),
+			TLS:                 p.TLS,
+			MessageFormatter:    p.Formatter,
+			StructuredFormatter: format.Literal(""),
+		}.New(),
+	}
+}
+
+type papertrailCollector struct {
+	Papertrail
+	syslog cue.Collector
+}
+
+func (p *papertrailCollector) String() string {
+	return fmt.Sprintf("Papertrail(host=%s, port=%d, app=%s, facility=%s)", p.Host, p.Port, p.App, p.Facility)
+}
+
+func (p *papertrailCollector) Collect(event *cue.Event) error {
+	return p.syslog.Collect(event)
+}
+
+func (p *papertrailCollector) Close() error {
+	return p.syslog.(io.Closer).Close()
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package hosted
+
+import (
+	"crypto/tls"
+	"github.com/bobziuchkovski/cue/collector"
+	"github.com/bobziuchkovski/cue/internal/cuetest"
+	"net"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestPapertrailNilCollector(t *testing.T) {
+	c := Papertrail{Port: 12345, App: "test"}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when the host is missing, but got %s instead", c)
+	}
+
+	c = Papertrail{Host: "logs.papertrailapp.com", App: "test"}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when the port is missing, but got %s instead", c)
+	}
+
+	c = Papertrail{Host: "logs.papertrailapp.com", Port: 12345}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when the app is missing, but got %s instead", c)
+	}
+}
+
+func TestPapertrailDefaultTLS(t *testing.T) {
+	c := Papertrail{Host: "logs.papertrailapp.com", Port: 12345, App: "test"}.New()
+	pc, ok := c.(*papertrailCollector)
+	if !ok {
+		t.Fatalf("Expected to see a *papertrailCollector but got %s instead", c)
+	}
+	if pc.TLS == nil || pc.TLS.ServerName != "logs.papertrailapp.com" || pc.TLS.InsecureSkipVerify {
+		t.Errorf("Expected TLS to verify the Papertrail host by default, but got %v instead", pc.TLS)
+	}
+}
+
+func TestPapertrail(t *testing.T) {
+	recorder := cuetest.NewTLSRecorder()
+	recorder.Start()
+	defer recorder.Close()
+
+	host, portStr, err := net.SplitHostPort(recorder.Address())
+	if err != nil {
+		t.Fatalf("Failed to parse recorder address: %s", err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		t.Fatalf("Failed to parse recorder port: %s", err)
+	}
+
+	c := Papertrail{
+		Host:     host,
+		Port:     port,
+		App:      "testapp",
+		Facility: collector.LOCAL4,
+		TLS:      &tls.Config{InsecureSkipVerify: true},
+	}.New()
+
+	err = c.Collect(cuetest.DebugEvent)
+	if err != nil {
+		t.Errorf("Encountered unexpected error: %s", err)
+	}
+	cuetest.CloseCollector(c)
+
+	pattern := `<167>1 2006-01-02T15:04:00.000000(Z|[-+]\d{2}:\d{2}) \S+ testapp testapp\[\d+\] - \[cue@47338\] debug event k1="some value" k2=2 k3=3.5 k4=true\n`
+	re := regexp.MustCompile(pattern)
+	if !re.Match(recorder.Contents()) {
+		t.Errorf("Expected content %q to match pattern %q but it didn't", recorder.Contents(), pattern)
+	}
+}
+
+func TestPapertrailString(t *testing.T) {
+	c := Papertrail{Host: "logs.papertrailapp.com", Port: 12345, App: "test", Facility: collector.LOCAL4}.New()
+	expected := "Papertrail(host=logs.papertrailapp.com, port=12345, app=test, facility=LOCAL4)"
+	if c.(*papertrailCollector).String() != expected {
+		t.Errorf("Expected a String() value of %q but got %q instead", expected, c)
+	}
+}
@@ -40,9 +40,10 @@ type Rollbar struct {
 	Environment string // Environment name ("development", "production", etc.)
 
 	// Optional
-	ExtraContext     cue.Context // Additional context values to send with every event
-	ProjectVersion   string      // Project version (SHA value, semantic version, etc.)
-	ProjectFramework string      // Project framework name
+	ExtraContext     cue.Context  // Additional context values to send with every event
+	ProjectVersion   string       // Project version (SHA value, semantic version, etc.)
+	ProjectFramework string       // Project framework name
+	Client           *http.Client // HTTP client for submitting events.  See the package docs for sharing clients.
 }
 
 // New returns a new collector based on the Rollbar configuration.
@@ -53,7 +54,7 @@ func (r Rollbar) New() cue.Collector {
 	}
 	return &rollbarCollector{
 		Rollbar: r,
-		http:    collector.HTTP{RequestFormatter: r.formatRequest}.New(),
+		http:    collector.HTTP{RequestFormatter: r.formatRequest, Client: r.Client}.New(),
 	}
 }
 
@@ -79,7 +80,7 @@ func (r Rollbar) formatBody(buffer format.Buffer, event *cue.Event) {
 		bodyFormatter = r.formatMessage
 	}
 
-	contextJSON, _ := json.Marshal(cue.JoinContext("", event.Context, r.ExtraContext).Fields())
+	contextJSON, _ := json.Marshal(reportContext(event, r.ExtraContext).Fields())
 	marshalled, _ := json.Marshal(&rollbarPost{
 		Token: r.Token,
 		Data: rollbarData{
@@ -207,7 +208,7 @@ type rollbarException struct {
 
 func rollbarLevel(level cue.Level) string {
 	switch level {
-	case cue.DEBUG:
+	case cue.TRACE, cue.DEBUG:
 		return "debug"
 	case cue.INFO:
 		return "info"
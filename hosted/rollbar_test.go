@@ -138,6 +138,7 @@ func TestRollbarString(t *testing.T) {
 
 func TestRollbarLevels(t *testing.T) {
 	m := map[cue.Level]string{
+		cue.TRACE: "debug",
 		cue.DEBUG: "debug",
 		cue.INFO:  "info",
 		cue.WARN:  "warning",
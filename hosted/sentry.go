@@ -30,6 +30,8 @@ import (
 	"github.com/bobziuchkovski/cue/format"
 	"net/http"
 	"net/url"
+	"reflect"
+	"strings"
 	"time"
 )
 
@@ -43,8 +45,15 @@ type Sentry struct {
 	DSN string // DSN for the app (e.g. https://<public>:<private>@app.getsentry.com/<appid>)
 
 	// Optional
-	ExtraContext   cue.Context // Additional context values to send with every event
-	ProjectVersion string      // Project version (SHA value, semantic version, etc.)
+	ExtraContext   cue.Context  // Additional context values to send with every event
+	ProjectVersion string       // Project version (SHA value, semantic version, etc.)
+	Client         *http.Client // HTTP client for submitting events.  See the package docs for sharing clients.
+
+	// EventID returns the Sentry event ID for an event, such as a trace ID
+	// taken from the event's context fields.  Sentry requires IDs to be 32
+	// hex characters, i.e. a UUID.  Dashes are removed from the result.  If
+	// EventID is nil or returns an empty string, a random UUID is used.
+	EventID func(event *cue.Event) string
 }
 
 // New returns a new collector based on the Sentry configuration.
@@ -55,7 +64,7 @@ func (s Sentry) New() cue.Collector {
 	}
 	return &sentryCollector{
 		Sentry: s,
-		http:   collector.HTTP{RequestFormatter: s.formatRequest}.New(),
+		http:   collector.HTTP{RequestFormatter: s.formatRequest, Client: s.Client}.New(),
 	}
 }
 
@@ -83,7 +92,7 @@ func (s Sentry) formatBody(buffer format.Buffer, event *cue.Event) {
 
 	post := &sentryPost{
 		Timestamp:  event.Time.UTC().Format("2006-01-02T15:04:05"),
-		EventID:    hex.EncodeToString(uuid()),
+		EventID:    s.eventIDFor(event),
 		Message:    message,
 		Exception:  s.exceptionFor(event),
 		Culprit:    s.culpritFor(event),
@@ -99,17 +108,52 @@ func (s Sentry) formatBody(buffer format.Buffer, event *cue.Event) {
 	buffer.Append(marshalled)
 }
 
-func (s Sentry) exceptionFor(event *cue.Event) *sentryException {
-	var exception *sentryException
-	if event.Level == cue.ERROR || event.Level == cue.FATAL {
-		exception = &sentryException{
-			Type:       format.RenderString(format.ErrorType, event),
-			Value:      event.Message,
-			Stacktrace: s.stacktraceFor(event),
+func (s Sentry) eventIDFor(event *cue.Event) string {
+	if s.EventID != nil {
+		id := strings.Replace(s.EventID(event), "-", "", -1)
+		if id != "" {
+			return id
+		}
+	}
+	return hex.EncodeToString(uuid())
+}
+
+func (s Sentry) exceptionFor(event *cue.Event) interface{} {
+	if event.Level != cue.ERROR && event.Level != cue.FATAL {
+		return nil
+	}
+	multi, ok := event.Error.(*cue.MultiError)
+	if !ok {
+		return s.singleExceptionFor(event)
+	}
+
+	// Sentry expects the most recent exception last, and only that entry
+	// carries the stacktrace.  We treat the causes as occurring in order.
+	exceptions := &sentryExceptions{}
+	for i, err := range multi.Errors {
+		exception := &sentryException{
+			Type:  errorType(err),
+			Value: err.Error(),
 		}
 		if len(event.Frames) > 0 && event.Frames[0].Package != cue.UnknownPackage {
 			exception.Module = event.Frames[0].Package
 		}
+		if i == len(multi.Errors)-1 {
+			exception.Stacktrace = s.stacktraceFor(event)
+		}
+		exceptions.Values = append(exceptions.Values, exception)
+	}
+	return exceptions
+}
+
+func (s Sentry) singleExceptionFor(event *cue.Event) *sentryException {
+	exception := &sentryException{
+		Type:       format.RenderString(format.ErrorType, event),
+		Value:      event.Message,
+		Stacktrace: s.stacktraceFor(event),
+	}
+	if len(event.Frames) > 0 && event.Frames[0].Package != cue.UnknownPackage {
+		exception.Module = event.Frames[0].Package
 	}
 	return exception
 }
@@ -140,12 +184,20 @@ func (s Sentry) stacktraceFor(event *cue.Event) *sentryStacktrace {
 
 func (s Sentry) tagsFor(event *cue.Event) []sentryTag {
 	var tags []sentryTag
-	cue.JoinContext("", event.Context, s.ExtraContext).Each(func(key string, value interface{}) {
+	reportContext(event, s.ExtraContext).Each(func(key string, value interface{}) {
 		tags = append(tags, sentryTag{Name: key, Value: fmt.Sprint(value)})
 	})
 	return tags
 }
 
+func errorType(err error) string {
+	rtype := reflect.TypeOf(err)
+	for rtype.Kind() == reflect.Ptr {
+		rtype = rtype.Elem()
+	}
+	return rtype.String()
+}
+
 func validDSN(dsn string) bool {
 	u, err := url.Parse(dsn)
 	if err != nil {
@@ -186,8 +238,8 @@ type sentryPost struct {
 	Logger    string `json:"logger,omitempty"`
 	Platform  string `json:"platform"`
 
-	// For errors
-	Exception *sentryException `json:"exception,omitempty"`
+	// For errors.  Either a *sentryException or *sentryExceptions.
+	Exception interface{} `json:"exception,omitempty"`
 
 	// Optional attrs
 	Culprit    string      `json:"culprit,omitempty"`
@@ -203,6 +255,10 @@ type sentryException struct {
 	Stacktrace *sentryStacktrace `json:"stacktrace,omitempty"`
 }
 
+type sentryExceptions struct {
+	Values []*sentryException `json:"values"`
+}
+
 type sentryStacktrace struct {
 	Frames []*sentryFrame `json:"frames"`
 }
@@ -237,7 +293,7 @@ func formatSentryAuth(public, private string) string {
 
 func sentryLevel(level cue.Level) string {
 	switch level {
-	case cue.DEBUG:
+	case cue.TRACE, cue.DEBUG:
 		return "debug"
 	case cue.INFO:
 		return "info"
@@ -21,6 +21,7 @@
 package hosted
 
 import (
+	"errors"
 	"fmt"
 	"github.com/bobziuchkovski/cue"
 	"github.com/bobziuchkovski/cue/internal/cuetest"
@@ -128,6 +129,65 @@ const sentryNoFramesJSON = `
 }
 `
 
+const sentryMultiErrorJSON = `
+{
+  "culprit": "github.com/bobziuchkovski/cue/frame1.function1",
+  "event_id": "679989e954034f948cc5e5cb220d32aa",
+  "exception": {
+    "values": [
+      {
+        "module": "github.com/bobziuchkovski/cue/frame1",
+        "type": "errors.errorString",
+        "value": "first cause"
+      },
+      {
+        "module": "github.com/bobziuchkovski/cue/frame1",
+        "stacktrace": {
+          "frames": [
+            {
+              "filename": "/path/github.com/bobziuchkovski/cue/frame1/file1.go",
+              "function": "github.com/bobziuchkovski/cue/frame1.function1",
+              "lineno": 1,
+              "module": "github.com/bobziuchkovski/cue/frame1"
+            }
+          ]
+        },
+        "type": "errors.errorString",
+        "value": "second cause"
+      }
+    ]
+  },
+  "level": "error",
+  "logger": "test context",
+  "message": "batch failed: first cause; second cause",
+  "platform": "go",
+  "server_name": "pegasus.bobbyz.org",
+  "tags": [
+    [
+      "extra",
+      "extra value"
+    ],
+    [
+      "k1",
+      "some value"
+    ],
+    [
+      "k2",
+      "2"
+    ],
+    [
+      "k3",
+      "3.5"
+    ],
+    [
+      "k4",
+      "true"
+    ]
+  ],
+  "timestamp": "2006-01-02T22:04:00"
+}
+`
+
 func TestSentryNilCollector(t *testing.T) {
 	c := Sentry{}.New()
 	if c != nil {
@@ -143,6 +203,38 @@ func TestSentryNoFrames(t *testing.T) {
 	checkSentryEvent(t, cuetest.ErrorEventNoFrames, sentryNoFramesJSON)
 }
 
+func TestSentryMultiError(t *testing.T) {
+	multi := &cue.MultiError{Errors: []error{errors.New("first cause"), errors.New("second cause")}}
+	event := cuetest.GenerateEvent(cue.ERROR, cuetest.ErrorEvent.Context, "batch failed", multi, 1)
+	checkSentryEvent(t, event, sentryMultiErrorJSON)
+}
+
+func TestSentryEventID(t *testing.T) {
+	c := getSentryCollector()
+	c.EventID = func(event *cue.Event) string {
+		return fmt.Sprint(event.Context.Fields()["trace_id"])
+	}
+	ctx := cue.NewContext("test").WithValue("trace_id", "0af76519-16cd-43dd-8448-eb211c80319c")
+	req, err := c.formatRequest(cuetest.GenerateEvent(cue.ERROR, ctx, "error event", errors.New("error message"), 0))
+	if err != nil {
+		t.Fatalf("Encountered unexpected error formatting http request: %s", err)
+	}
+	id := cuetest.NestedFetch(cuetest.ParseRequestJSON(req), "event_id")
+	if id != "0af7651916cd43dd8448eb211c80319c" {
+		t.Errorf("Expected the event ID to be derived from the context, but saw %v instead", id)
+	}
+
+	c.EventID = func(event *cue.Event) string { return "" }
+	req, err = c.formatRequest(cuetest.ErrorEvent)
+	if err != nil {
+		t.Fatalf("Encountered unexpected error formatting http request: %s", err)
+	}
+	id = cuetest.NestedFetch(cuetest.ParseRequestJSON(req), "event_id")
+	if s, ok := id.(string); !ok || len(s) != 32 {
+		t.Errorf("Expected a random event ID when EventID returns an empty string, but saw %v instead", id)
+	}
+}
+
 func TestSentryString(t *testing.T) {
 	_ = fmt.Sprint(getSentryCollector())
 }
@@ -164,6 +256,7 @@ func TestSentryValidDSN(t *testing.T) {
 
 func TestSentryLevels(t *testing.T) {
 	m := map[cue.Level]string{
+		cue.TRACE: "debug",
 		cue.DEBUG: "debug",
 		cue.INFO:  "info",
 		cue.WARN:  "warning",
@@ -0,0 +1,160 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package hosted
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/collector"
+	"github.com/bobziuchkovski/cue/format"
+	"net/http"
+	"sort"
+)
+
+// Slack represents configuration for posting events to a Slack channel via
+// an incoming webhook.  Each event is posted as a message attachment colored
+// by level, following the color semantics of format.Colorize: TRACE/DEBUG
+// events are blue, INFO events are green, WARN events are yellow, and
+// ERROR/FATAL events are red.  Context fields are rendered as attachment
+// fields.
+//
+// Slack enforces rate limits on incoming webhooks, so the collector is
+// intended for alerting and should be registered at the ERROR or FATAL
+// threshold.
+type Slack struct {
+	// Required
+	WebhookURL string // Incoming webhook URL
+
+	// Optional
+	Username  string       // Overrides the webhook's default username
+	Channel   string       // Overrides the webhook's default channel, e.g. "#alerts"
+	IconEmoji string       // Overrides the webhook's default icon, e.g. ":rotating_light:"
+	Client    *http.Client // HTTP client for submitting events.  See the package docs for sharing clients.
+}
+
+// New returns a new collector based on the Slack configuration.
+func (s Slack) New() cue.Collector {
+	if s.WebhookURL == "" {
+		log.Warn("Slack.New called to created a collector, but WebhookURL param is empty.  Returning nil collector.")
+		return nil
+	}
+	return &slackCollector{
+		Slack: s,
+		http:  collector.HTTP{RequestFormatter: s.formatRequest, Client: s.Client}.New(),
+	}
+}
+
+func (s Slack) formatRequest(event *cue.Event) (request *http.Request, err error) {
+	body := format.RenderBytes(s.formatBody, event)
+	request, err = http.NewRequest("POST", s.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	request.Header.Set("Content-Type", "application/json")
+	return
+}
+
+func (s Slack) formatBody(buffer format.Buffer, event *cue.Event) {
+	message := format.RenderString(format.MessageWithError, event)
+	attachment := slackAttachment{
+		Fallback:  fmt.Sprintf("%s: %s", event.Level, message),
+		Color:     slackColor(event.Level),
+		Title:     event.Level.String(),
+		Text:      message,
+		Footer:    format.RenderString(format.SourceWithLine, event),
+		Timestamp: event.Time.Unix(),
+	}
+
+	fields := reportContext(event, nil).Fields()
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		attachment.Fields = append(attachment.Fields, slackField{
+			Title: k,
+			Value: fmt.Sprint(fields[k]),
+			Short: true,
+		})
+	}
+
+	marshalled, _ := json.Marshal(&slackPost{
+		Username:    s.Username,
+		Channel:     s.Channel,
+		IconEmoji:   s.IconEmoji,
+		Attachments: []slackAttachment{attachment},
+	})
+	buffer.Append(marshalled)
+}
+
+type slackCollector struct {
+	Slack
+	http cue.Collector
+}
+
+func (s *slackCollector) String() string {
+	return fmt.Sprintf("Slack(channel=%s)", s.Channel)
+}
+
+func (s *slackCollector) Collect(event *cue.Event) error {
+	return s.http.Collect(event)
+}
+
+type slackPost struct {
+	Username    string            `json:"username,omitempty"`
+	Channel     string            `json:"channel,omitempty"`
+	IconEmoji   string            `json:"icon_emoji,omitempty"`
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+type slackAttachment struct {
+	Fallback  string       `json:"fallback"`
+	Color     string       `json:"color"`
+	Title     string       `json:"title"`
+	Text      string       `json:"text"`
+	Fields    []slackField `json:"fields,omitempty"`
+	Footer    string       `json:"footer,omitempty"`
+	Timestamp int64        `json:"ts"`
+}
+
+type slackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+func slackColor(level cue.Level) string {
+	switch level {
+	case cue.TRACE, cue.DEBUG:
+		return "#439fe0"
+	case cue.INFO:
+		return "good"
+	case cue.WARN:
+		return "warning"
+	case cue.ERROR, cue.FATAL:
+		return "danger"
+	default:
+		panic("cue/hosted: BUG unknown level")
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package hosted
+
+import (
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/internal/cuetest"
+	"reflect"
+	"testing"
+)
+
+const slackJSON = `
+{
+  "username": "cue",
+  "channel": "#alerts",
+  "icon_emoji": ":rotating_light:",
+  "attachments": [
+    {
+      "fallback": "ERROR: error event: error message",
+      "color": "danger",
+      "title": "ERROR",
+      "text": "error event: error message",
+      "fields": [
+        {"title": "k1", "value": "some value", "short": true},
+        {"title": "k2", "value": "2", "short": true},
+        {"title": "k3", "value": "3.5", "short": true},
+        {"title": "k4", "value": "true", "short": true}
+      ],
+      "footer": "file3.go:3",
+      "ts": 1136214240
+    }
+  ]
+}
+`
+
+func TestSlackNilCollector(t *testing.T) {
+	c := Slack{}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when the webhook URL is missing, but got %s instead", c)
+	}
+}
+
+func TestSlack(t *testing.T) {
+	req, err := getSlackCollector().formatRequest(cuetest.ErrorEvent)
+	if err != nil {
+		t.Errorf("Encountered unexpected error formatting http request: %s", err)
+	}
+	if req.URL.String() != "https://hooks.slack.com/services/test" {
+		t.Errorf("Expected a request to the webhook URL, but saw %s instead", req.URL)
+	}
+	if req.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Expected a JSON content type, but saw %q instead", req.Header.Get("Content-Type"))
+	}
+	cuetest.NestedCompare(t, cuetest.ParseRequestJSON(req), cuetest.ParseStringJSON(slackJSON))
+}
+
+func TestSlackString(t *testing.T) {
+	_ = fmt.Sprint(getSlackCollector())
+}
+
+func TestSlackColors(t *testing.T) {
+	m := map[cue.Level]string{
+		cue.TRACE: "#439fe0",
+		cue.DEBUG: "#439fe0",
+		cue.INFO:  "good",
+		cue.WARN:  "warning",
+		cue.ERROR: "danger",
+		cue.FATAL: "danger",
+	}
+	for k, v := range m {
+		if slackColor(k) != v {
+			t.Errorf("Expected cue level %q to map to slack color %q but it didn't", k, v)
+		}
+	}
+}
+
+func getSlackCollector() *slackCollector {
+	c := Slack{
+		WebhookURL: "https://hooks.slack.com/services/test",
+		Username:   "cue",
+		Channel:    "#alerts",
+		IconEmoji:  ":rotating_light:",
+	}.New()
+	sc, ok := c.(*slackCollector)
+	if !ok {
+		panic(fmt.Sprintf("Expected to see a *slackCollector but got %s instead", reflect.TypeOf(c)))
+	}
+	return sc
+}
@@ -0,0 +1,265 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package hosted
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/collector"
+	"github.com/bobziuchkovski/cue/format"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	stackdriverURL           = "https://logging.googleapis.com/v2/entries:write"
+	stackdriverSourceKey     = "logging.googleapis.com/sourceLocation"
+	stackdriverReservedScope = "logging.googleapis.com/"
+)
+
+// Stackdriver represents configuration for Google Cloud Logging (formerly
+// Stackdriver).  By default, events are written to Writer as single-line
+// JSON objects in the structured logging format that the Cloud Logging agent
+// scrapes from container output on GKE, Cloud Run, and similar environments:
+//
+//	severity      DEBUG, INFO, WARNING, ERROR, or CRITICAL
+//	message       The event message and error (see format.MessageWithError)
+//	timestamp     An object with the event time in seconds and nanos
+//	logging.googleapis.com/sourceLocation
+//	              An object with the file, line, and function of the event's
+//	              first frame.  Omitted if frames weren't collected.
+//	<key>         Each context field
+//
+// If Client is set, events are instead buffered and POSTed to the Cloud
+// Logging API in batches, as with Datadog.  Client must be authorized to
+// write log entries, e.g. via golang.org/x/oauth2/google.DefaultClient, and
+// ProjectID must be set.  Each entry's jsonPayload holds the message and
+// context fields, and its severity, timestamp, and sourceLocation are set
+// from the event.
+//
+// Context keys that collide with the attributes above are written with a
+// leading underscore, e.g. "_severity".
+type Stackdriver struct {
+	// Optional
+	Writer io.Writer // Default: os.Stdout.  Ignored if Client is set.
+
+	// Optional, for submission via the Cloud Logging API
+	Client        *http.Client  // Authorized HTTP client.  If set, events are POSTed to the API.
+	ProjectID     string        // Google Cloud project ID.  Required if Client is set.
+	LogName       string        // Default: "cue"
+	ResourceType  string        // Monitored resource type.  Default: "global"
+	BatchSize     int           // Default: 100
+	FlushInterval time.Duration // Default: 5 seconds
+}
+
+// New returns a new collector based on the Stackdriver configuration.
+func (s Stackdriver) New() cue.Collector {
+	if s.Client == nil {
+		return &stackdriverCollector{
+			Stackdriver: s,
+			target: collector.Writer{
+				Writer:    s.Writer,
+				Formatter: s.formatLine,
+			}.New(),
+		}
+	}
+
+	if s.ProjectID == "" {
+		log.Warn("Stackdriver.New called to created a collector, but the ProjectID param is empty.  Returning nil collector.")
+		return nil
+	}
+	if s.LogName == "" {
+		s.LogName = "cue"
+	}
+	if s.ResourceType == "" {
+		s.ResourceType = "global"
+	}
+	return &stackdriverCollector{
+		Stackdriver: s,
+		target: collector.BatchHTTP{
+			RequestFormatter: s.formatRequest,
+			MaxSize:          s.BatchSize,
+			MaxDelay:         s.FlushInterval,
+			Client:           s.Client,
+		}.New(),
+	}
+}
+
+func (s Stackdriver) formatLine(buffer format.Buffer, event *cue.Event) {
+	entry := s.payloadFor(event)
+	entry["severity"] = stackdriverSeverity(event.Level)
+	entry["timestamp"] = stackdriverTimestamp{
+		Seconds: event.Time.Unix(),
+		Nanos:   event.Time.Nanosecond(),
+	}
+	if location := stackdriverLocationFor(event); location != nil {
+		entry[stackdriverSourceKey] = location
+	}
+
+	marshaled, err := json.Marshal(entry)
+	if err != nil {
+		log.Error(err, "Failed to marshal Stackdriver entry")
+		return
+	}
+	buffer.Append(marshaled)
+}
+
+func (s Stackdriver) formatRequest(events []*cue.Event) (request *http.Request, err error) {
+	entries := make([]stackdriverEntry, len(events))
+	for i, event := range events {
+		entries[i] = stackdriverEntry{
+			Severity:       stackdriverSeverity(event.Level),
+			Timestamp:      event.Time.UTC().Format(time.RFC3339Nano),
+			JSONPayload:    s.payloadFor(event),
+			SourceLocation: stackdriverLocationFor(event),
+		}
+	}
+	body, err := json.Marshal(stackdriverRequest{
+		LogName:  fmt.Sprintf("projects/%s/logs/%s", s.ProjectID, url.PathEscape(s.LogName)),
+		Resource: stackdriverResource{Type: s.ResourceType},
+		Entries:  entries,
+	})
+	if err != nil {
+		return
+	}
+
+	request, err = http.NewRequest("POST", stackdriverURL, bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	request.Header.Set("Content-Type", "application/json")
+	return
+}
+
+func (s Stackdriver) payloadFor(event *cue.Event) map[string]interface{} {
+	payload := make(map[string]interface{})
+	for k, v := range reportContext(event, nil).Fields() {
+		if stackdriverReserved[k] || strings.HasPrefix(k, stackdriverReservedScope) {
+			k = "_" + k
+		}
+		if _, err := json.Marshal(v); err != nil {
+			v = fmt.Sprint(v)
+		}
+		payload[k] = v
+	}
+	payload["message"] = format.RenderString(format.MessageWithError, event)
+	return payload
+}
+
+var stackdriverReserved = map[string]bool{
+	"message":   true,
+	"severity":  true,
+	"timestamp": true,
+}
+
+type stackdriverRequest struct {
+	LogName  string              `json:"logName"`
+	Resource stackdriverResource `json:"resource"`
+	Entries  []stackdriverEntry  `json:"entries"`
+}
+
+type stackdriverResource struct {
+	Type string `json:"type"`
+}
+
+type stackdriverEntry struct {
+	Severity       string                     `json:"severity"`
+	Timestamp      string                     `json:"timestamp"`
+	JSONPayload    map[string]interface{}     `json:"jsonPayload"`
+	SourceLocation *stackdriverSourceLocation `json:"sourceLocation,omitempty"`
+}
+
+type stackdriverTimestamp struct {
+	Seconds int64 `json:"seconds"`
+	Nanos   int   `json:"nanos"`
+}
+
+type stackdriverSourceLocation struct {
+	File     string `json:"file"`
+	Line     string `json:"line"`
+	Function string `json:"function"`
+}
+
+func stackdriverLocationFor(event *cue.Event) *stackdriverSourceLocation {
+	if len(event.Frames) == 0 {
+		return nil
+	}
+	frame := event.Frames[0]
+	return &stackdriverSourceLocation{
+		File:     frame.File,
+		Line:     strconv.Itoa(frame.Line),
+		Function: frame.Function,
+	}
+}
+
+func stackdriverSeverity(level cue.Level) string {
+	switch level {
+	case cue.TRACE, cue.DEBUG:
+		return "DEBUG"
+	case cue.INFO:
+		return "INFO"
+	case cue.WARN:
+		return "WARNING"
+	case cue.ERROR:
+		return "ERROR"
+	default:
+		return "CRITICAL"
+	}
+}
+
+type stackdriverCollector struct {
+	Stackdriver
+	target cue.Collector
+}
+
+func (s *stackdriverCollector) String() string {
+	if s.Client == nil {
+		return "Stackdriver(output=writer)"
+	}
+	return fmt.Sprintf("Stackdriver(project=%s, log=%s)", s.ProjectID, s.LogName)
+}
+
+func (s *stackdriverCollector) Collect(event *cue.Event) error {
+	return s.target.Collect(event)
+}
+
+func (s *stackdriverCollector) Close() error {
+	closer, ok := s.target.(io.Closer)
+	if !ok {
+		return nil
+	}
+	return closer.Close()
+}
+
+func (s *stackdriverCollector) Flush() error {
+	flusher, ok := s.target.(interface {
+		Flush() error
+	})
+	if !ok {
+		return nil
+	}
+	return flusher.Flush()
+}
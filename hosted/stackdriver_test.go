@@ -0,0 +1,170 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package hosted
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/bobziuchkovski/cue"
+	"github.com/bobziuchkovski/cue/internal/cuetest"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStackdriverNilCollector(t *testing.T) {
+	c := Stackdriver{Client: &http.Client{}}.New()
+	if c != nil {
+		t.Errorf("Expected a nil collector when the project ID is missing, but got %s instead", c)
+	}
+}
+
+func TestStackdriverWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	c := Stackdriver{Writer: buf}.New()
+	c.Collect(cuetest.ErrorEvent)
+	cuetest.CloseCollector(c)
+
+	if !strings.HasSuffix(buf.String(), "\n") || strings.Count(buf.String(), "\n") != 1 {
+		t.Errorf("Expected a single newline-terminated entry, but got %q instead", buf.String())
+	}
+	event := cuetest.ErrorEvent
+	expected := map[string]interface{}{
+		"severity": "ERROR",
+		"message":  "error event: error message",
+		"timestamp": map[string]interface{}{
+			"seconds": json.Number(strconv.FormatInt(event.Time.Unix(), 10)),
+			"nanos":   json.Number(strconv.Itoa(event.Time.Nanosecond())),
+		},
+		"logging.googleapis.com/sourceLocation": map[string]interface{}{
+			"file":     event.Frames[0].File,
+			"line":     strconv.Itoa(event.Frames[0].Line),
+			"function": event.Frames[0].Function,
+		},
+		"k1": "some value",
+		"k2": json.Number("2"),
+		"k3": json.Number("3.5"),
+		"k4": true,
+	}
+	cuetest.NestedCompare(t, cuetest.ParseStringJSON(buf.String()), expected)
+}
+
+func TestStackdriverReservedKeys(t *testing.T) {
+	buf := &bytes.Buffer{}
+	c := Stackdriver{Writer: buf}.New()
+	ctx := cue.NewContext("test").
+		WithValue("severity", "bogus").
+		WithValue("logging.googleapis.com/labels", "bogus").
+		WithValue("c", complex(1, 2))
+	c.Collect(cuetest.GenerateEvent(cue.WARN, ctx, "warn event", nil, 0))
+	cuetest.CloseCollector(c)
+
+	entry := cuetest.ParseStringJSON(buf.String())
+	if entry["severity"] != "WARNING" || entry["_severity"] != "bogus" || entry["_logging.googleapis.com/labels"] != "bogus" || entry["c"] != "(1+2i)" {
+		t.Errorf("Expected reserved keys to be prefixed and unmarshalable values stringified, but got %v instead", entry)
+	}
+	if _, present := entry["logging.googleapis.com/sourceLocation"]; present {
+		t.Errorf("Expected the source location to be omitted for events without frames, but got %v instead", entry)
+	}
+}
+
+func TestStackdriverAPI(t *testing.T) {
+	recorder := cuetest.NewHTTPRequestRecorder()
+	c := Stackdriver{
+		Client:        &http.Client{Transport: recorder},
+		ProjectID:     "my-project",
+		LogName:       "app/events",
+		FlushInterval: time.Hour,
+	}.New()
+
+	c.Collect(cuetest.DebugEvent)
+	c.Collect(cuetest.FatalEvent)
+	cuetest.CloseCollector(c)
+	if len(recorder.Requests()) != 1 {
+		t.Fatalf("Expected exactly 1 request to be sent but saw %d instead", len(recorder.Requests()))
+	}
+
+	req := recorder.Requests()[0]
+	if req.Method != "POST" || req.Host != "logging.googleapis.com" || req.URL.Path != "/v2/entries:write" {
+		t.Errorf("Expected a POST to the entries:write endpoint, but saw %s %s%s instead", req.Method, req.Host, req.URL.Path)
+	}
+	body := cuetest.ParseRequestJSON(req)
+	if body["logName"] != "projects/my-project/logs/app%2Fevents" {
+		t.Errorf("Expected an escaped log name, but saw %v instead", body["logName"])
+	}
+	if cuetest.NestedFetch(body, "resource", "type") != "global" {
+		t.Errorf("Expected the global resource type, but saw %v instead", body["resource"])
+	}
+
+	entries, ok := body["entries"].([]interface{})
+	if !ok || len(entries) != 2 {
+		t.Fatalf("Expected the request to contain 2 entries, but saw %v instead", body["entries"])
+	}
+	event := cuetest.FatalEvent
+	expected := map[string]interface{}{
+		"severity":  "CRITICAL",
+		"timestamp": event.Time.UTC().Format(time.RFC3339Nano),
+		"jsonPayload": map[string]interface{}{
+			"message": "fatal event: fatal message",
+			"k1":      "some value",
+			"k2":      json.Number("2"),
+			"k3":      json.Number("3.5"),
+			"k4":      true,
+		},
+		"sourceLocation": map[string]interface{}{
+			"file":     event.Frames[0].File,
+			"line":     strconv.Itoa(event.Frames[0].Line),
+			"function": event.Frames[0].Function,
+		},
+	}
+	cuetest.NestedCompare(t, entries[1].(map[string]interface{}), expected)
+}
+
+func TestStackdriverSeverity(t *testing.T) {
+	m := map[cue.Level]string{
+		cue.TRACE: "DEBUG",
+		cue.DEBUG: "DEBUG",
+		cue.INFO:  "INFO",
+		cue.WARN:  "WARNING",
+		cue.ERROR: "ERROR",
+		cue.FATAL: "CRITICAL",
+	}
+	for k, v := range m {
+		if stackdriverSeverity(k) != v {
+			t.Errorf("Expected cue level %q to map to stackdriver severity %q but it didn't", k, v)
+		}
+	}
+}
+
+func TestStackdriverString(t *testing.T) {
+	c := Stackdriver{Writer: &bytes.Buffer{}}.New()
+	defer cuetest.CloseCollector(c)
+	if c.(*stackdriverCollector).String() != "Stackdriver(output=writer)" {
+		t.Errorf("Unexpected String() value: %s", c)
+	}
+
+	c = Stackdriver{Client: &http.Client{}, ProjectID: "my-project"}.New()
+	defer cuetest.CloseCollector(c)
+	if c.(*stackdriverCollector).String() != "Stackdriver(project=my-project, log=cue)" {
+		t.Errorf("Unexpected String() value: %s", c)
+	}
+}
@@ -25,7 +25,7 @@ import (
 )
 
 // The uuid function is used by the sentry collector to generate unique event
-// IDs.
+// IDs when Sentry.EventID isn't set.
 func uuid() []byte {
 	uuid := make([]byte, 16)
 	_, err := rand.Read(uuid)
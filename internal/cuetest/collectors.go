@@ -21,69 +21,14 @@
 package cuetest
 
 import (
-	"github.com/bobziuchkovski/cue"
-	"sync"
-	"time"
+	public "github.com/bobziuchkovski/cue/cuetest"
 )
 
 // CapturingCollector captures events that are sent to its Collect method.
-type CapturingCollector struct {
-	captured []*cue.Event
-	cond     *sync.Cond
-	mu       sync.Mutex
-}
+// It's an alias for the exported cue/cuetest.CapturingCollector.
+type CapturingCollector = public.CapturingCollector
 
 // NewCapturingCollector returns a new CapturingCollector instance.
 func NewCapturingCollector() *CapturingCollector {
-	c := &CapturingCollector{}
-	c.cond = sync.NewCond(&c.mu)
-	return c
-}
-
-// Collect captures the input event for later inspection.
-func (c *CapturingCollector) Collect(event *cue.Event) error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.captured = append(c.captured, event)
-	c.cond.Broadcast()
-	return nil
-}
-
-// Captured returns a slice of captured events.
-func (c *CapturingCollector) Captured() []*cue.Event {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	dup := make([]*cue.Event, len(c.captured))
-	for i, event := range c.captured {
-		dup[i] = event
-	}
-	return dup
-}
-
-// WaitCaptured waits for count events to be captured.  If count events aren't
-// captured within maxWait time, it panics.
-func (c *CapturingCollector) WaitCaptured(count int, maxWait time.Duration) {
-	finished := make(chan struct{})
-	go c.waitAsync(count, finished)
-
-	select {
-	case <-finished:
-		return
-	case <-time.After(maxWait):
-		panic("WaitCaptured timed-out waiting for events")
-	}
-}
-
-func (c *CapturingCollector) waitAsync(count int, finished chan struct{}) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	for len(c.captured) != count {
-		c.cond.Wait()
-	}
-	close(finished)
-}
-
-// String returns a string representation of the CapturingCollector.
-func (c *CapturingCollector) String() string {
-	return "CapturingCollector()"
+	return public.NewCapturingCollector()
 }
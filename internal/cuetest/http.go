@@ -26,12 +26,13 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"net/http/httputil"
 	"sync"
 )
 
-// HTTPRequestRecorder implements http.RoundTripper, capturing all requests
-// that are sent to it.
+// HTTPRequestRecorder implements http.Handler and http.RoundTripper,
+// capturing all requests that are sent to it.
 type HTTPRequestRecorder struct {
 	mu       sync.Mutex
 	requests []*http.Request
@@ -42,7 +43,7 @@ func NewHTTPRequestRecorder() *HTTPRequestRecorder {
 	return &HTTPRequestRecorder{}
 }
 
-// ServeHTTP is implemented to satisfy the http.RoundTripper interface.
+// ServeHTTP is implemented to satisfy the http.Handler interface.
 func (rr *HTTPRequestRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	dump, err := httputil.DumpRequest(req, true)
 	if err != nil {
@@ -64,6 +65,14 @@ func (rr *HTTPRequestRecorder) ServeHTTP(w http.ResponseWriter, req *http.Reques
 	rr.requests = append(rr.requests, dupe)
 }
 
+// RoundTrip is implemented to satisfy the http.RoundTripper interface.  The
+// request is captured and a 200 OK response is returned.
+func (rr *HTTPRequestRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	w := httptest.NewRecorder()
+	rr.ServeHTTP(w, req)
+	return w.Result(), nil
+}
+
 // Requests returns a slice of the requests captured by the recorder.
 func (rr *HTTPRequestRecorder) Requests() []*http.Request {
 	rr.mu.Lock()
@@ -27,6 +27,7 @@ import (
 	"reflect"
 	"sync"
 	"testing"
+	"time"
 )
 
 // NetRecorder is an interface representing a network listener/recorder.  The
@@ -62,6 +63,18 @@ type NetRecorder interface {
 	Err() error
 }
 
+// DatagramRecorder is a NetRecorder for packet-oriented networks.  In addition
+// to the NetRecorder methods, it records the boundaries of each datagram it
+// receives.  Close waits briefly for in-flight datagrams to arrive before
+// stopping the recorder.
+type DatagramRecorder interface {
+	NetRecorder
+
+	// Datagrams returns the individual datagrams that have been sent to the
+	// recorder, in the order they were received.
+	Datagrams() [][]byte
+}
+
 type netRecorder struct {
 	done   chan struct{}
 	cancel chan struct{}
@@ -192,6 +205,127 @@ func (nr *netRecorder) run() {
 	close(nr.done)
 }
 
+type udpRecorder struct {
+	done   chan struct{}
+	cancel chan struct{}
+	err    *firstError
+
+	startOnce sync.Once
+	closeOnce sync.Once
+
+	mu        sync.Mutex
+	address   string
+	datagrams [][]byte
+	conn      net.PacketConn
+}
+
+// NewUDPRecorder returns a DatagramRecorder that listens for UDP datagrams.
+func NewUDPRecorder() DatagramRecorder {
+	return &udpRecorder{
+		done:    make(chan struct{}),
+		cancel:  make(chan struct{}),
+		address: randomUDPAddress(),
+		err:     &firstError{},
+	}
+}
+
+func (ur *udpRecorder) Start() {
+	ur.startOnce.Do(func() {
+		conn, err := net.ListenPacket("udp", ur.address)
+		if err != nil {
+			panic(err)
+		}
+		ur.conn = conn
+		go ur.run()
+	})
+}
+
+func (ur *udpRecorder) Address() string {
+	return ur.address
+}
+
+func (ur *udpRecorder) Contents() []byte {
+	return bytes.Join(ur.Datagrams(), nil)
+}
+
+func (ur *udpRecorder) Datagrams() [][]byte {
+	<-ur.done
+
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+	return ur.datagrams
+}
+
+func (ur *udpRecorder) CheckByteContents(t *testing.T, expectation []byte) {
+	if !reflect.DeepEqual(ur.Contents(), expectation) {
+		t.Errorf("Expected recorded content of %x but got %x instead", expectation, ur.Contents())
+	}
+}
+
+func (ur *udpRecorder) CheckStringContents(t *testing.T, expectation string) {
+	if string(ur.Contents()) != expectation {
+		t.Errorf("Expected recorded content of %q but got %q instead", expectation, ur.Contents())
+	}
+}
+
+func (ur *udpRecorder) Done() <-chan struct{} {
+	return ur.done
+}
+
+func (ur *udpRecorder) Err() error {
+	<-ur.done
+	return ur.err.Error()
+}
+
+func (ur *udpRecorder) Close() error {
+	ur.closeOnce.Do(func() {
+		close(ur.cancel)
+		if ur.conn != nil {
+			<-ur.done
+		}
+	})
+
+	return ur.err.Error()
+}
+
+func (ur *udpRecorder) run() {
+	defer close(ur.done)
+	defer ur.conn.Close()
+
+	// Datagrams may still be in-flight when Close is called, so we keep
+	// reading until the socket has been idle for a short period.
+	buf := make([]byte, 65536)
+	draining := false
+	for {
+		timeout := 10 * time.Millisecond
+		select {
+		case <-ur.cancel:
+			draining = true
+			timeout = 50 * time.Millisecond
+		default:
+		}
+		ur.conn.SetReadDeadline(time.Now().Add(timeout))
+
+		n, _, err := ur.conn.ReadFrom(buf)
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				if draining {
+					return
+				}
+				continue
+			}
+			ur.err.Set(err)
+			return
+		}
+
+		datagram := make([]byte, n)
+		copy(datagram, buf[:n])
+		ur.mu.Lock()
+		ur.datagrams = append(ur.datagrams, datagram)
+		ur.mu.Unlock()
+	}
+}
+
 type firstError struct {
 	mu  sync.Mutex
 	err error
@@ -220,3 +354,12 @@ func randomAddress() string {
 	}
 	return l.Addr().String()
 }
+
+func randomUDPAddress() string {
+	conn, err := net.ListenPacket("udp", "localhost:0")
+	if err != nil {
+		panic(err)
+	}
+	defer conn.Close()
+	return conn.LocalAddr().String()
+}
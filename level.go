@@ -20,7 +20,12 @@
 
 package cue
 
-// OFF, FATAL, ERROR, WARN, INFO, and DEBUG are logging Level constants.
+import (
+	"fmt"
+	"strings"
+)
+
+// OFF, FATAL, ERROR, WARN, INFO, DEBUG, and TRACE are logging Level constants.
 const (
 	OFF Level = iota
 	FATAL
@@ -28,18 +33,21 @@ const (
 	WARN
 	INFO
 	DEBUG
+	TRACE
 )
 
 // Level represents the severity level for a logged event.  Events are only
 // generated and collected if their severity level is within the threshold
 // level for one or more registered Collectors.  Calling Logger.Info, for
 // example, will only generate an event if a Collector is registered at the
-// INFO or DEBUG threshold levels.
+// INFO, DEBUG, or TRACE threshold levels.
 type Level uint
 
 // String returns the level's name.
 func (l Level) String() string {
 	switch l {
+	case TRACE:
+		return "TRACE"
 	case DEBUG:
 		return "DEBUG"
 	case INFO:
@@ -56,3 +64,17 @@ func (l Level) String() string {
 		return "INVALID LEVEL"
 	}
 }
+
+// ParseLevel returns the Level corresponding to the given name.  Matching is
+// case-insensitive, so "warn", "WARN", and "Warn" all return WARN.  The
+// accepted names are those returned by Level.String(): "off", "fatal",
+// "error", "warn", "info", "debug", and "trace".  An error is returned for
+// any other input.
+func ParseLevel(s string) (Level, error) {
+	for l := OFF; l <= TRACE; l++ {
+		if strings.EqualFold(s, l.String()) {
+			return l, nil
+		}
+	}
+	return OFF, fmt.Errorf("cue: unknown level %q", s)
+}
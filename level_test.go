@@ -28,6 +28,9 @@ func TestLevelString(t *testing.T) {
 	if OFF.String() != "OFF" {
 		t.Errorf("OFF.String value is incorrect.  Expected %q but received %q instead", "OFF", OFF.String())
 	}
+	if TRACE.String() != "TRACE" {
+		t.Errorf("TRACE.String value is incorrect.  Expected %q but received %q instead", "TRACE", TRACE.String())
+	}
 	if DEBUG.String() != "DEBUG" {
 		t.Errorf("DEBUG.String value is incorrect.  Expected %q but received %q instead", "DEBUG", DEBUG.String())
 	}
@@ -47,3 +50,48 @@ func TestLevelString(t *testing.T) {
 		t.Error("Expected to see INVALID LEVEL for bogus level")
 	}
 }
+
+var parseLevelTests = []struct {
+	Input    string
+	Expected Level
+}{
+	{Input: "off", Expected: OFF},
+	{Input: "fatal", Expected: FATAL},
+	{Input: "error", Expected: ERROR},
+	{Input: "warn", Expected: WARN},
+	{Input: "info", Expected: INFO},
+	{Input: "debug", Expected: DEBUG},
+	{Input: "trace", Expected: TRACE},
+	{Input: "WARN", Expected: WARN},
+	{Input: "Debug", Expected: DEBUG},
+}
+
+func TestParseLevel(t *testing.T) {
+	for _, test := range parseLevelTests {
+		level, err := ParseLevel(test.Input)
+		if err != nil {
+			t.Errorf("Encountered unexpected error parsing %q: %s", test.Input, err)
+		}
+		if level != test.Expected {
+			t.Errorf("Parsed level is incorrect.  Input: %q, Expected: %s, Received: %s", test.Input, test.Expected, level)
+		}
+	}
+}
+
+func TestParseLevelRoundTrip(t *testing.T) {
+	for l := OFF; l <= TRACE; l++ {
+		parsed, err := ParseLevel(l.String())
+		if err != nil || parsed != l {
+			t.Errorf("Expected %s to round-trip, but received %s (err: %v) instead", l, parsed, err)
+		}
+	}
+}
+
+func TestParseLevelInvalid(t *testing.T) {
+	for _, input := range []string{"", "warning", "bogus", "INVALID LEVEL"} {
+		_, err := ParseLevel(input)
+		if err == nil {
+			t.Errorf("Expected an error parsing %q, but didn't receive one", input)
+		}
+	}
+}
@@ -23,7 +23,9 @@ package cue
 import (
 	"errors"
 	"fmt"
+	"os"
 	"runtime"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -38,8 +40,23 @@ var (
 	// Sending represents the number of sends currently in-process.
 	// It is updated atomically and used to safely terminate workers.
 	sending int32
+
+	// Delivered counts events dispatched to at least one collector since the
+	// last Close.  It's updated atomically and reported by the close marker.
+	delivered uint64
+
+	// Exit is called by Fatal and Fatalf.  It's swapped out for testing.
+	exit = os.Exit
 )
 
+// FatalExitCode is the process exit code used by the Logger Fatal and Fatalf
+// methods.
+var FatalExitCode = 1
+
+// FatalFlushTimeout is the maximum time the Logger Fatal and Fatalf methods
+// wait for asynchronous collectors to flush their buffers before exiting.
+var FatalFlushTimeout = 5 * time.Second
+
 // Collector is the interface representing event subscribers.  Log events are
 // only generated and dispatched if collectors are registered with corresponding
 // threshold levels.
@@ -53,13 +70,28 @@ type Collector interface {
 // Logger is the interface for logging instances.
 type Logger interface {
 	// WithFields returns a new logger instance with fields added to the current
-	// logger's context.
+	// logger's context.  Values in fields replace existing values for the same
+	// keys.  See Context.WithFields for details.
 	WithFields(fields Fields) Logger
 
 	// WithValue returns a new logger instance with key and value added to the
 	// current logger's context.
 	WithValue(key string, value interface{}) Logger
 
+	// WithError returns a new logger instance with err added to the current
+	// logger's context under the "error" key.  This is useful for emitting
+	// several messages about the same failure.  The Event.Error field is
+	// unaffected; it's only set by the Error and Errorf methods.  If err is
+	// nil, the logger's context is left unaltered.
+	WithError(err error) Logger
+
+	// Trace logs a message at the TRACE level.
+	Trace(message string)
+
+	// Tracef logs a message at the TRACE level using formatting rules from
+	// the fmt package.
+	Tracef(format string, values ...interface{})
+
 	// Debug logs a message at the DEBUG level.
 	Debug(message string)
 
@@ -83,14 +115,23 @@ type Logger interface {
 
 	// Error logs the given error and message at the ERROR level and returns
 	// the same error value. If err is nil, Error returns without emitting
-	// a log event.
+	// a log event.  The emitted level may be adjusted via SetErrorLevelFunc.
 	Error(err error, message string) error
 
 	// Errorf logs the given error at the ERROR level using formatting rules
 	// from the fmt package and returns the same error value.  If err is nil,
-	// Errorf returns without emitting a log event.
+	// Errorf returns without emitting a log event.  The emitted level may be
+	// adjusted via SetErrorLevelFunc.
 	Errorf(err error, format string, values ...interface{}) error
 
+	// Errors logs the given errors and message as a single event at the
+	// ERROR level and returns the combined error.  Nil entries are skipped.
+	// If errs contains no non-nil errors, Errors returns nil without emitting
+	// a log event.  If it contains exactly one, that error is logged and
+	// returned as-is.  Otherwise, the errors are combined into a *MultiError.
+	// The emitted level may be adjusted via SetErrorLevelFunc.
+	Errors(errs []error, message string) error
+
 	// Panic logs the given cause and message at the FATAL level and then
 	// calls panic(cause).  Panic does nothing is cause is nil.
 	Panic(cause interface{}, message string)
@@ -100,6 +141,20 @@ type Logger interface {
 	// cause is nil.
 	Panicf(cause interface{}, format string, values ...interface{})
 
+	// Fatal logs the given error and message at the FATAL level, flushes
+	// asynchronous collectors via Close, and then terminates the program by
+	// calling os.Exit(FatalExitCode).  As with the standard library's
+	// log.Fatal, deferred functions are not run.  Unlike Panic, Fatal can't
+	// be recovered.  The event is emitted even if err is nil.
+	Fatal(err error, message string)
+
+	// Fatalf logs the given error at the FATAL level using formatting rules
+	// from the fmt package, flushes asynchronous collectors via Close, and
+	// then terminates the program by calling os.Exit(FatalExitCode).  As with
+	// the standard library's log.Fatalf, deferred functions are not run.  The
+	// event is emitted even if err is nil.
+	Fatalf(err error, format string, values ...interface{})
+
 	// Recover recovers from panics and logs the recovered value and message
 	// at the FATAL level.  Recover must be called via defer. If a logger's
 	// Panic or Panicf method is used to trigger the panic, Recover returns
@@ -116,10 +171,25 @@ type Logger interface {
 	// ReportRecovery.  ReportRecovery does nothing if cause is nil.
 	ReportRecovery(cause interface{}, message string)
 
+	// Request logs the outcome of an HTTP request.  The method, path, status,
+	// and duration values are added to the event context, and the message
+	// takes the conventional "GET /path 200 (12ms)" form.  Events for 5xx
+	// statuses are logged at the ERROR level, 4xx statuses at the WARN level,
+	// and all other statuses at the INFO level.  The Event.Error field is
+	// left unset.
+	Request(method, path string, status int, duration time.Duration)
+
 	// Wrap returns a logging instance that skips one additional frame when
 	// capturing frames for a call site.  Wrap should only be used when logging
 	// calls are wrapped by an additional library function or method.
 	Wrap() Logger
+
+	// WrapN returns a logging instance that skips n additional frames when
+	// capturing frames for a call site.  WrapN(1) is equivalent to Wrap().
+	// WrapN is useful when logging calls are wrapped by multiple layers of
+	// library functions or methods.  If n is less than 1, WrapN returns an
+	// equivalent logger that skips no additional frames.
+	WrapN(n int) Logger
 }
 
 // logger is the default logger implementation
@@ -140,6 +210,17 @@ func NewLogger(name string) Logger {
 	}
 }
 
+// NewLoggerWithSkip returns a new logger instance using name for the
+// context that skips skip additional frames when capturing frames for a call
+// site.  It's intended for adapters that expose cue through another logging
+// facade: skip should be the number of adapter frames between the user's
+// call and the cue logging method, so that captured frames point at the
+// user's code.  NewLoggerWithSkip(name, n) is equivalent to
+// NewLogger(name).WrapN(n).
+func NewLoggerWithSkip(name string, skip int) Logger {
+	return NewLogger(name).WrapN(skip)
+}
+
 func (l *logger) String() string {
 	return fmt.Sprintf("Logger(name=%s)", l.context.Name())
 }
@@ -156,12 +237,33 @@ func (l *logger) WithValue(key string, value interface{}) Logger {
 	return new
 }
 
+func (l *logger) WithError(err error) Logger {
+	if err == nil {
+		return l.clone()
+	}
+	return l.WithValue("error", err)
+}
+
 func (l *logger) Wrap() Logger {
+	return l.WrapN(1)
+}
+
+func (l *logger) WrapN(n int) Logger {
 	new := l.clone()
-	new.skipFrames++
+	if n > 0 {
+		new.skipFrames += n
+	}
 	return new
 }
 
+func (l *logger) Trace(message string) {
+	l.send(TRACE, nil, message)
+}
+
+func (l *logger) Tracef(format string, values ...interface{}) {
+	l.sendf(TRACE, nil, format, values...)
+}
+
 func (l *logger) Debug(message string) {
 	l.send(DEBUG, nil, message)
 }
@@ -190,7 +292,24 @@ func (l *logger) Error(err error, message string) error {
 	if err == nil {
 		return nil
 	}
-	l.send(ERROR, err, message)
+	level := cfg.get().errorLevel(err)
+	if level == OFF {
+		return err
+	}
+	l.send(level, err, message)
+	return err
+}
+
+func (l *logger) Errors(errs []error, message string) error {
+	err := combineErrors(errs)
+	if err == nil {
+		return nil
+	}
+	level := cfg.get().errorLevel(err)
+	if level == OFF {
+		return err
+	}
+	l.send(level, err, message)
 	return err
 }
 
@@ -198,7 +317,11 @@ func (l *logger) Errorf(err error, format string, values ...interface{}) error {
 	if err == nil {
 		return nil
 	}
-	l.sendf(ERROR, err, format, values...)
+	level := cfg.get().errorLevel(err)
+	if level == OFF {
+		return err
+	}
+	l.sendf(level, err, format, values...)
 	return err
 }
 
@@ -216,6 +339,16 @@ func (l *logger) Panicf(cause interface{}, format string, values ...interface{})
 	l.sendPanicf(cause, format, values...)
 }
 
+func (l *logger) Fatal(err error, message string) {
+	l.send(FATAL, err, message)
+	exitFatal()
+}
+
+func (l *logger) Fatalf(err error, format string, values ...interface{}) {
+	l.sendf(FATAL, err, format, values...)
+	exitFatal()
+}
+
 func (l *logger) Recover(message string) {
 	cause := recover()
 	if cause == nil || ourPanic() {
@@ -231,6 +364,28 @@ func (l *logger) ReportRecovery(cause interface{}, message string) {
 	l.sendRecovery(cause, message)
 }
 
+func (l *logger) Request(method, path string, status int, duration time.Duration) {
+	new := l.clone()
+	new.context = new.context.WithFields(Fields{
+		"method":   method,
+		"path":     path,
+		"status":   status,
+		"duration": duration,
+	})
+	new.sendf(requestLevel(status), nil, "%s %s %d (%s)", method, path, status, duration)
+}
+
+func requestLevel(status int) Level {
+	switch {
+	case status >= 500 && status < 600:
+		return ERROR
+	case status >= 400 && status < 500:
+		return WARN
+	default:
+		return INFO
+	}
+}
+
 func (l *logger) send(level Level, err error, message string) {
 	config := cfg.get()
 	if level > config.threshold {
@@ -306,11 +461,16 @@ func (l *logger) sendRecovery(cause interface{}, message string) {
 func (l *logger) dispatchEvent(event *Event) {
 	atomic.AddInt32(&sending, 1)
 	defer atomic.AddInt32(&sending, -1)
+	sent := false
 	for _, entry := range cfg.get().registry {
 		if entry.threshold >= event.Level && !entry.degraded {
 			entry.worker.Send(event)
+			sent = true
 		}
 	}
+	if sent {
+		atomic.AddUint64(&delivered, 1)
+	}
 }
 
 func (l *logger) clone() *logger {
@@ -329,7 +489,7 @@ func (l *logger) clone() *logger {
 // the collector's Collect method returns successfully.  This is dangerous
 // if the collector performs blocking operations or returns errors.
 func Collect(threshold Level, c Collector) {
-	collect(threshold, 0, c)
+	collect("", threshold, 0, c, AsyncOptions{})
 }
 
 // CollectAsync registers a Collector for the given threshold using
@@ -344,7 +504,8 @@ func Collect(threshold Level, c Collector) {
 // incremented atomically.  This ensures asynchronous logging calls never
 // block.  The worker goroutine detects changes in the atomic drop counter and
 // surfaces drop events as collector errors.  See the cue/collector docs for
-// details on collector error handling.
+// details on collector error handling.  Use CollectAsyncWith to drop the
+// oldest queued event instead, or to be notified of drops.
 //
 // When asynchronous logging is enabled, Close must be called to flush queued
 // events on program termination.  Close is safe to call even if asynchronous
@@ -354,29 +515,112 @@ func Collect(threshold Level, c Collector) {
 // signal handlers to capture SIGINT (ctrl+c) and SIGTERM (kill <pid>).  See
 // the Signals example and os/signals package docs for details.
 func CollectAsync(threshold Level, bufsize int, c Collector) {
-	collect(threshold, bufsize, c)
+	collect("", threshold, bufsize, c, AsyncOptions{})
 }
 
-func collect(threshold Level, bufsize int, c Collector) {
+// AsyncOptions customizes asynchronous event collection.  See
+// CollectAsyncWith for details.
+type AsyncOptions struct {
+	// If DropOldest is set and the collector's buffer is full, the oldest
+	// queued event is discarded to make room for the new event.  This favors
+	// fresh state over history.  By default, the new event is discarded
+	// instead.  Either way, the drop counter is incremented.
+	DropOldest bool
+
+	// If OnDrop is set, it's called with the collector's total drop count
+	// when the drop counter is incremented, whether due to a full buffer or a
+	// failed collection.  OnDrop is called from a separate goroutine, so
+	// logging calls never block on it.  Calls are coalesced if drops occur
+	// faster than OnDrop returns, so dropped may increase by more than 1
+	// between calls.  This is useful for emitting metrics as soon as drops
+	// begin.  OnDrop must not log via cue.
+	OnDrop func(dropped uint64)
+
+	// If FlushInterval is positive, the worker goroutine wakes every
+	// FlushInterval to drain its queue.  If the collector implements a
+	// Flush() error method, it's then called so the collector may submit any
+	// events it has buffered internally, such as partial batches.  This
+	// bounds delivery latency for batching collectors in low-volume services,
+	// where buffered events might otherwise wait for further events to push
+	// them out.  Flush errors are logged.
+	FlushInterval time.Duration
+}
+
+// CollectAsyncWith is equivalent to CollectAsync, but customizes the
+// collector's asynchronous behavior with the provided options.
+func CollectAsyncWith(threshold Level, bufsize int, c Collector, opts AsyncOptions) {
+	collect("", threshold, bufsize, c, opts)
+}
+
+// CollectNamed is equivalent to Collect, but additionally registers the
+// collector under the given name.  The name may be passed to SetLevelByName
+// and DisposeByName to manage the collector without holding a reference to
+// it, such as from an administrative endpoint.  Names must be unique.  If the
+// name is already in use, the collector isn't registered and a WARN event is
+// emitted instead.
+func CollectNamed(name string, threshold Level, c Collector) {
+	collect(name, threshold, 0, c, AsyncOptions{})
+}
+
+// CollectTemporary registers a Collector for the given threshold using
+// synchronous event collection, as with Collect, and disposes it once d
+// elapses.  This is useful for attaching a collector for a short period,
+// such as capturing live debugging output from an administrative endpoint,
+// without disturbing other registrations.  Disposal waits for in-process
+// sends to complete, so the collector receives no events after it's disposed.
+// If c is already registered, CollectTemporary does nothing.
+func CollectTemporary(threshold Level, d time.Duration, c Collector) {
+	if c == nil {
+		return
+	}
+	w, _ := register("", threshold, 0, c, AsyncOptions{})
+	if w == nil {
+		return
+	}
+	time.AfterFunc(d, func() {
+		disposeWorker(c, w)
+	})
+}
+
+func collect(name string, threshold Level, bufsize int, c Collector, opts AsyncOptions) {
 	if c == nil {
 		return
 	}
 
+	// The warning is emitted after registration releases the config lock
+	_, existing := register(name, threshold, bufsize, c, opts)
+	if existing != nil {
+		internalLogger.Warnf("Collector name %q is already registered to %s.  Ignoring registration for %s.", name, existing, c)
+	}
+}
+
+// register adds the collector to the registry and returns its worker.  If the
+// collector is already registered, a nil worker is returned.  If the name is
+// already in use, the collector isn't registered and the existing collector is
+// returned.
+func register(name string, threshold Level, bufsize int, c Collector, opts AsyncOptions) (w worker, existing Collector) {
 	cfg.lock()
 	defer cfg.unlock()
 
 	new := cfg.get().clone()
 	_, present := new.registry[c]
 	if present {
-		return
+		return nil, nil
+	}
+	existing, _, present = new.registry.named(name)
+	if present {
+		return nil, existing
 	}
 
+	w = newWorker(c, bufsize, opts)
 	new.registry[c] = &entry{
+		name:      name,
 		threshold: threshold,
-		worker:    newWorker(c, bufsize),
+		worker:    w,
 	}
 	new.updateThreshold()
 	cfg.set(new)
+	return w, nil
 }
 
 // SetLevel changes a registered collector's threshold level.  The OFF value
@@ -397,6 +641,188 @@ func SetLevel(threshold Level, c Collector) {
 	cfg.set(new)
 }
 
+// SetThreshold changes the threshold level of every registered collector at
+// once.  It's useful for adjusting verbosity at runtime when references to the
+// registered collectors weren't retained, such as when they were constructed
+// inline via Collect(INFO, collector.Terminal{}.New()).  Collectors registered
+// after the call keep the threshold they're registered with.  See SetLevel for
+// details.
+func SetThreshold(threshold Level) {
+	cfg.lock()
+	defer cfg.unlock()
+
+	new := cfg.get().clone()
+	for _, entry := range new.registry {
+		entry.threshold = threshold
+	}
+	new.updateThreshold()
+	cfg.set(new)
+}
+
+// SetLevelByName changes the threshold level of the collector registered via
+// CollectNamed with the given name.  It does nothing if no collector is
+// registered with the name.  See SetLevel for details.
+func SetLevelByName(name string, threshold Level) {
+	cfg.lock()
+	defer cfg.unlock()
+
+	new := cfg.get().clone()
+	_, entry, present := new.registry.named(name)
+	if !present {
+		return
+	}
+	entry.threshold = threshold
+	new.updateThreshold()
+	cfg.set(new)
+}
+
+// CollectorInfo describes a registered collector.  See Collectors for
+// details.
+type CollectorInfo struct {
+	Collector Collector
+	Name      string // Name passed to CollectNamed, or "" if unnamed
+	Threshold Level
+	Degraded  bool // Set while the collector is degraded due to errors
+}
+
+// String returns a string representation of the collector info.
+func (ci CollectorInfo) String() string {
+	if ci.Name == "" {
+		return fmt.Sprintf("%s (threshold=%s, degraded=%t)", ci.Collector, ci.Threshold, ci.Degraded)
+	}
+	return fmt.Sprintf("%s (name=%s, threshold=%s, degraded=%t)", ci.Collector, ci.Name, ci.Threshold, ci.Degraded)
+}
+
+// Collectors returns information about the registered collectors, sorted by
+// their string representations.  This is useful for reporting the active
+// logging configuration, such as at startup, or for diagnosing missing log
+// output.
+func Collectors() []CollectorInfo {
+	cfg.lock()
+	defer cfg.unlock()
+
+	var infos []CollectorInfo
+	for c, entry := range cfg.get().registry {
+		infos = append(infos, CollectorInfo{
+			Collector: c,
+			Name:      entry.name,
+			Threshold: entry.threshold,
+			Degraded:  entry.degraded,
+		})
+	}
+	sort.Sort(collectorInfos(infos))
+	return infos
+}
+
+type collectorInfos []CollectorInfo
+
+func (ci collectorInfos) Len() int           { return len(ci) }
+func (ci collectorInfos) Less(i, j int) bool { return ci[i].String() < ci[j].String() }
+func (ci collectorInfos) Swap(i, j int)      { ci[i], ci[j] = ci[j], ci[i] }
+
+// ConfigSnapshot is an opaque copy of cue's configuration.  See Snapshot for
+// details.
+type ConfigSnapshot struct {
+	config *config
+}
+
+// Snapshot captures the current configuration: registered collectors and
+// their thresholds, frame counts, and other settings applied via the Set*
+// functions.  The snapshot may later be passed to Restore to revert
+// configuration changes atomically.  Taking a snapshot has no effect on
+// registered collectors or their workers.
+func Snapshot() ConfigSnapshot {
+	cfg.lock()
+	defer cfg.unlock()
+
+	return ConfigSnapshot{config: cfg.get().clone()}
+}
+
+// Restore reverts configuration to that captured by snapshot.  Collectors
+// registered since the snapshot was taken are terminated, flushing their
+// buffered events, and removed from the registry.  Collectors that remain
+// registered have their snapshot thresholds and names restored.  Collectors
+// that were disposed since the snapshot was taken, either directly or via
+// Close, can't be revived and are omitted.  Degraded state always reflects
+// current collector health rather than the snapshot.
+//
+// Restoring a zero-value ConfigSnapshot does nothing.
+func Restore(snapshot ConfigSnapshot) {
+	if snapshot.config == nil {
+		return
+	}
+
+	cfg.lock()
+	current := cfg.get()
+	new := snapshot.config.clone()
+	for c, entry := range new.registry {
+		live, present := current.registry[c]
+		if !present {
+			delete(new.registry, c)
+			continue
+		}
+		entry.degraded = live.degraded
+		entry.worker = live.worker
+	}
+	var removed []worker
+	for c, entry := range current.registry {
+		if _, present := new.registry[c]; !present {
+			removed = append(removed, entry.worker)
+		}
+	}
+	new.updateThreshold()
+	cfg.set(new)
+	cfg.unlock()
+
+	// Workers are terminated outside the lock since degraded collectors
+	// update the config while flushing.
+	waitForSends()
+	flush := true
+	for _, w := range removed {
+		w.Terminate(flush)
+	}
+}
+
+// DisposeByName terminates the collector registered via CollectNamed with the
+// given name, discards any events buffered for it, and removes it from the
+// registry entirely.  It does nothing if no collector is registered with the
+// name.
+func DisposeByName(name string) {
+	c, _, present := cfg.get().registry.named(name)
+	if !present {
+		return
+	}
+	dispose(c)
+}
+
+// ErrorLevelFunc adjusts the level of events logged via Logger.Error,
+// Logger.Errorf, and Logger.Errors.  It's passed the logged error and the
+// proposed level (ERROR) and returns the level to emit.  See SetErrorLevelFunc
+// for details.
+type ErrorLevelFunc func(err error, proposed Level) Level
+
+// SetErrorLevelFunc registers fn to escalate or deescalate the level of events
+// logged via Logger.Error, Logger.Errorf, and Logger.Errors based on the logged
+// error's properties.  This is useful for error types that carry their own
+// severity: fn may detect them via type or interface assertion and return an
+// adjusted level.  Returning OFF suppresses the event entirely, and invalid
+// levels are ignored.  The Error, Errorf, and Errors methods return the logged
+// error regardless.  Passing a nil fn (the default) restores the identity
+// behavior, where errors are always logged at the ERROR level.
+// SetErrorLevelFunc may be called any number of times during program
+// execution.
+//
+// fn is called synchronously for every Error, Errorf, and Errors call, so it
+// should be cheap and must not log via cue.
+func SetErrorLevelFunc(fn ErrorLevelFunc) {
+	cfg.lock()
+	defer cfg.unlock()
+
+	new := cfg.get().clone()
+	new.errorLevelFunc = fn
+	cfg.set(new)
+}
+
 // SetFrames specifies the number of stack frames to collect for log events.
 // The frames parameter specifies the frame count to collect for DEBUG, INFO,
 // and WARN events.  The errorFrames parameter specifies the frame count to
@@ -422,6 +848,39 @@ func SetFrames(frames int, errorFrames int) {
 	cfg.set(new)
 }
 
+// SetMaxContextValues limits the number of key/value pairs that may be stored
+// in a single Context.  Once a context holds max pairs, calls to WithValue and
+// WithFields that add new keys return the context unaltered and emit a WARN
+// event.  Values for existing keys may still be replaced.  This guards
+// against runaway memory growth caused by repeatedly adding values to a
+// long-lived logger or context.  A max value of 0 (the default) disables the
+// limit.  SetMaxContextValues may be called any number of times during program
+// execution.
+func SetMaxContextValues(max int) {
+	cfg.lock()
+	defer cfg.unlock()
+
+	new := cfg.get().clone()
+	new.maxContextValues = max
+	cfg.set(new)
+}
+
+// SetCloseMarker controls whether Close emits a final INFO event to all
+// collectors before flushing and terminating them.  The marker reads
+// "cue: flushing and shutting down, N events delivered", where N is the
+// number of events dispatched to collectors since cue was last closed.  This
+// is useful when tailing logs to distinguish a clean shutdown from a killed
+// process.  The marker is disabled by default.  Like other configuration, the
+// setting is reset by Close.
+func SetCloseMarker(enabled bool) {
+	cfg.lock()
+	defer cfg.unlock()
+
+	new := cfg.get().clone()
+	new.closeMarker = enabled
+	cfg.set(new)
+}
+
 // setDegraded is called by worker instances to temporarily disable a degraded
 // collector
 func setDegraded(c Collector, degraded bool) {
@@ -444,6 +903,8 @@ func setDegraded(c Collector, degraded bool) {
 // the given timeout, Close returns nil.  Otherwise it returns an error.
 // Close may be called regardless of asynchronous logging state. It returns
 // immediately if no events are buffered and no workers need to be terminated.
+// If enabled via SetCloseMarker, a final marker event is sent to each
+// collector before its worker is terminated.
 //
 // Close may be called multiple times throughout program execution.  If Close
 // returns nil, cue is guaranteed to be reset to it's initial state.  This is
@@ -460,6 +921,85 @@ func Close(timeout time.Duration) error {
 	}
 }
 
+// Flush is used to flush asynchronous logging buffers without terminating
+// workers.  Unlike Close, Flush leaves workers running and collectors
+// registered, so logging may continue normally afterwards.  This is useful
+// for ensuring buffered events are written at checkpoints in long-running
+// programs, or before calling Snapshot.  Flush waits for events queued prior
+// to the call to be sent to their collectors.  If all events flush within
+// the given timeout, Flush returns nil.  Otherwise it returns an error.
+func Flush(timeout time.Duration) error {
+	return flushWorkers(timeout)
+}
+
+// CheckpointFlushTimeout is the maximum time Checkpoint waits for
+// asynchronous logging buffers to flush.
+var CheckpointFlushTimeout = 5 * time.Second
+
+// OnCheckpoint registers fn to be called by Checkpoint.  Collectors and
+// applications may use checkpoint hooks to act on phase boundaries without
+// shutting down cue, such as by rotating or uploading log files.  Hooks are
+// called synchronously, in registration order, with the name passed to
+// Checkpoint.  Like other configuration, registered hooks are discarded by
+// Close.
+func OnCheckpoint(fn func(name string)) {
+	cfg.lock()
+	defer cfg.unlock()
+
+	new := cfg.get().clone()
+	new.checkpointHooks = append(new.checkpointHooks, fn)
+	cfg.set(new)
+}
+
+// Checkpoint marks a phase boundary, such as the completion of a CI or batch
+// job step.  Checkpoint flushes asynchronous logging buffers, leaving workers
+// running and collectors registered, and then calls the hooks registered via
+// OnCheckpoint with the given name.  Hence hooks observe all events logged
+// prior to the Checkpoint call.  If the buffers fail to flush within
+// CheckpointFlushTimeout, Checkpoint returns an error without calling the
+// hooks.
+func Checkpoint(name string) error {
+	err := flushWorkers(CheckpointFlushTimeout)
+	if err != nil {
+		return err
+	}
+	for _, hook := range cfg.get().checkpointHooks {
+		hook(name)
+	}
+	return nil
+}
+
+// flushWorkers waits for the workers' currently queued events to be sent to
+// their collectors.  We intentionally don't hold the config lock while
+// waiting, since workers acquire it when handling degraded collectors.
+func flushWorkers(timeout time.Duration) error {
+	result := make(chan struct{})
+	go func() {
+		var wg sync.WaitGroup
+		for _, entry := range cfg.get().registry {
+			wg.Add(1)
+			go func(worker worker) {
+				worker.Flush()
+				wg.Done()
+			}(entry.worker)
+		}
+		wg.Wait()
+		close(result)
+	}()
+
+	select {
+	case <-result:
+		return nil
+	case <-time.After(timeout):
+		return errors.New("cue: timeout waiting for buffers to flush")
+	}
+}
+
+func exitFatal() {
+	Close(FatalFlushTimeout)
+	exit(FatalExitCode)
+}
+
 func terminateAsync(result chan<- error) {
 	cfg.lock()
 	defer cfg.unlock()
@@ -467,17 +1007,31 @@ func terminateAsync(result chan<- error) {
 	current := cfg.get()
 	cfg.set(newConfig())
 
+	if current.closeMarker {
+		sendCloseMarker(current.registry)
+	}
+	atomic.StoreUint64(&delivered, 0)
 	terminateWorkers(current.registry)
 	result <- nil
 }
 
-func terminateWorkers(reg registry) {
-	// We have to wait until in-process sends are complete before signaling the
-	// workers to terminate.  Otherwise, in-process sends could attempt sending
-	// on a closed channel, which would panic.
-	for atomic.LoadInt32(&sending) != 0 {
-		runtime.Gosched() // Yield the processor
+// sendCloseMarker sends the close marker event directly to the workers in
+// reg.  The active config has already been swapped out, so waiting for
+// in-process sends ensures the marker is the last event each worker sees.
+func sendCloseMarker(reg registry) {
+	waitForSends()
+
+	message := fmt.Sprintf("cue: flushing and shutting down, %d events delivered", atomic.LoadUint64(&delivered))
+	event := newEvent(internalContext, INFO, nil, message)
+	for _, entry := range reg {
+		if entry.threshold >= event.Level && !entry.degraded {
+			entry.worker.Send(event)
+		}
 	}
+}
+
+func terminateWorkers(reg registry) {
+	waitForSends()
 
 	var wg sync.WaitGroup
 	for _, entry := range reg {
@@ -491,22 +1045,43 @@ func terminateWorkers(reg registry) {
 	wg.Wait()
 }
 
+// waitForSends blocks until in-process sends are complete.  This must be called
+// after swapping out the config and before signaling workers to terminate.
+// Otherwise, in-process sends could attempt sending on a closed channel, which
+// would panic.
+func waitForSends() {
+	for atomic.LoadInt32(&sending) != 0 {
+		runtime.Gosched() // Yield the processor
+	}
+}
+
 // dispose terminates the collector, discards any buffered messages for it, and
 // removes the collector from the registry entirely.
 func dispose(c Collector) {
-	cfg.lock()
-	defer cfg.unlock()
+	disposeWorker(c, nil)
+}
 
+// disposeWorker disposes the collector if it's registered with worker w, or
+// with any worker if w is nil.  The former guards against disposing a
+// collector that was disposed and then registered again in the meantime.
+func disposeWorker(c Collector, w worker) {
+	cfg.lock()
 	new := cfg.get().clone()
 	entry, present := new.registry[c]
-	if !present {
+	if !present || (w != nil && entry.worker != w) {
+		cfg.unlock()
 		return
 	}
 
 	delete(new.registry, c)
 	new.updateThreshold()
 	cfg.set(new)
+	cfg.unlock()
 
+	// In-process sends may still reference the worker via the old config.
+	// The lock is released first since degraded collectors update the config
+	// while sending.
+	waitForSends()
 	flush := false
 	entry.worker.Terminate(flush)
 }
@@ -59,6 +59,16 @@ var loggerContextTests = []struct {
 		Logger:     NewLogger("Chained2").WithValue("k1", "v1").WithFields(Fields{"k2": 2, "k3": 3.0}),
 		FieldEquiv: Fields{"k1": "v1", "k2": 2, "k3": 3.0},
 	},
+	{
+		Name:       "WithError",
+		Logger:     NewLogger("WithError").WithError(errors.New("failure")),
+		FieldEquiv: Fields{"error": "failure"},
+	},
+	{
+		Name:       "WithErrorNil",
+		Logger:     NewLogger("WithErrorNil").WithValue("k1", "v1").WithError(nil),
+		FieldEquiv: Fields{"k1": "v1"},
+	},
 }
 
 func TestLoggerContext(t *testing.T) {
@@ -75,6 +85,59 @@ func TestLoggerContext(t *testing.T) {
 	}
 }
 
+func TestLoggerWithErrorLeavesEventError(t *testing.T) {
+	defer resetCue()
+	c := newCapturingCollector()
+	Collect(DEBUG, c)
+
+	log := NewLogger("test").WithError(errors.New("context error"))
+	log.Warn("first")
+	log.Error(errors.New("event error"), "second")
+
+	if len(c.Captured()) != 2 {
+		t.Fatalf("Expected 2 log events but received %d", len(c.Captured()))
+	}
+	if c.Captured()[0].Error != nil {
+		t.Errorf("Expected WithError to leave event.Error nil, but received %s", c.Captured()[0].Error)
+	}
+	if c.Captured()[1].Error == nil || c.Captured()[1].Error.Error() != "event error" {
+		t.Errorf("Expected event.Error to hold the Error method's cause, but received %v", c.Captured()[1].Error)
+	}
+	for _, event := range c.Captured() {
+		if event.Context.Fields()["error"] != "context error" {
+			t.Errorf("Expected the error context field to be retained, but received %v", event.Context.Fields()["error"])
+		}
+	}
+}
+
+func TestLoggerTrace(t *testing.T) {
+	defer resetCue()
+	c := newCapturingCollector()
+	Collect(TRACE, c)
+
+	log := NewLogger("test")
+	log.Trace("Trace Test")
+
+	if len(c.Captured()) != 1 {
+		t.Errorf("Expected only a single log event but received %d", len(c.Captured()))
+	}
+	checkEventExpectation(t, c.Captured()[0], TRACE, "Trace Test", nil)
+}
+
+func TestLoggerTracef(t *testing.T) {
+	defer resetCue()
+	c := newCapturingCollector()
+	Collect(TRACE, c)
+
+	log := NewLogger("test")
+	log.Tracef("Tracef %s", "Test")
+
+	if len(c.Captured()) != 1 {
+		t.Errorf("Expected only a single log event but received %d", len(c.Captured()))
+	}
+	checkEventExpectation(t, c.Captured()[0], TRACE, "Tracef Test", nil)
+}
+
 func TestLoggerDebug(t *testing.T) {
 	defer resetCue()
 	c := newCapturingCollector()
@@ -178,6 +241,45 @@ func TestLoggerError(t *testing.T) {
 	checkEventExpectation(t, c.Captured()[0], ERROR, "Error Test", cause)
 }
 
+func TestLoggerErrors(t *testing.T) {
+	defer resetCue()
+	c := newCapturingCollector()
+	Collect(DEBUG, c)
+
+	first := errors.New("first")
+	second := errors.New("second")
+	log := NewLogger("test")
+	result := log.Errors([]error{first, nil, second}, "Errors Test")
+	multi, ok := result.(*MultiError)
+	if !ok {
+		t.Fatalf("Expected a *MultiError return value but got %#v instead", result)
+	}
+	if !reflect.DeepEqual(multi.Errors, []error{first, second}) {
+		t.Errorf("Expected nil errors to be skipped, but got %v instead", multi.Errors)
+	}
+	if !errors.Is(result, second) {
+		t.Error("Expected errors.Is to match an underlying error but it didn't")
+	}
+	if log.Errors([]error{nil, first}, "Errors Test, single") != first {
+		t.Error("Expected a single non-nil error to be returned as-is but it wasn't")
+	}
+	if log.Errors([]error{nil, nil}, "Errors Test, nil") != nil {
+		t.Error("Expected a nil return value for all-nil errors but didn't get one")
+	}
+	if log.Errors(nil, "Errors Test, empty") != nil {
+		t.Error("Expected a nil return value for empty errors but didn't get one")
+	}
+
+	if len(c.Captured()) != 2 {
+		t.Fatalf("Expected exactly 2 log events but received %d", len(c.Captured()))
+	}
+	checkEventExpectation(t, c.Captured()[0], ERROR, "Errors Test", result)
+	checkEventExpectation(t, c.Captured()[1], ERROR, "Errors Test, single", first)
+	if result.Error() != "first; second" {
+		t.Errorf("Expected combined error message %q but got %q instead", "first; second", result.Error())
+	}
+}
+
 func TestLoggerErrorf(t *testing.T) {
 	defer resetCue()
 	c := newCapturingCollector()
@@ -197,6 +299,150 @@ func TestLoggerErrorf(t *testing.T) {
 	checkEventExpectation(t, c.Captured()[0], ERROR, "Errorf Test", cause)
 }
 
+type transientError struct{}
+
+func (transientError) Error() string {
+	return "transient error"
+}
+
+func TestSetErrorLevelFunc(t *testing.T) {
+	defer resetCue()
+	c := newCapturingCollector()
+	Collect(DEBUG, c)
+
+	SetErrorLevelFunc(func(err error, proposed Level) Level {
+		if _, ok := err.(transientError); ok {
+			return WARN
+		}
+		return proposed
+	})
+
+	log := NewLogger("test")
+	transient := transientError{}
+	if log.Error(transient, "Error Test") != transient {
+		t.Error("Expected to receive the same error cause as the return value but didn't")
+	}
+	log.Errorf(transient, "Errorf %s", "Test")
+	cause := errors.New("Error Cause")
+	log.Error(cause, "Error Test")
+
+	if len(c.Captured()) != 3 {
+		t.Fatalf("Expected 3 log events but received %d", len(c.Captured()))
+	}
+	checkEventExpectation(t, c.Captured()[0], WARN, "Error Test", transient)
+	checkEventExpectation(t, c.Captured()[1], WARN, "Errorf Test", transient)
+	checkEventExpectation(t, c.Captured()[2], ERROR, "Error Test", cause)
+}
+
+func TestSetErrorLevelFuncOffAndInvalid(t *testing.T) {
+	defer resetCue()
+	c := newCapturingCollector()
+	Collect(DEBUG, c)
+
+	log := NewLogger("test")
+	cause := errors.New("Error Cause")
+
+	SetErrorLevelFunc(func(err error, proposed Level) Level { return OFF })
+	if log.Error(cause, "Suppressed") != cause {
+		t.Error("Expected to receive the same error cause as the return value but didn't")
+	}
+	SetErrorLevelFunc(func(err error, proposed Level) Level { return Level(42) })
+	log.Error(cause, "Invalid")
+	SetErrorLevelFunc(nil)
+	log.Error(cause, "Identity")
+
+	if len(c.Captured()) != 2 {
+		t.Fatalf("Expected 2 log events but received %d", len(c.Captured()))
+	}
+	checkEventExpectation(t, c.Captured()[0], ERROR, "Invalid", cause)
+	checkEventExpectation(t, c.Captured()[1], ERROR, "Identity", cause)
+}
+
+func TestLoggerRequest(t *testing.T) {
+	defer resetCue()
+	c := newCapturingCollector()
+	Collect(DEBUG, c)
+
+	log := NewLogger("test")
+	log.Request("GET", "/widgets", 200, 12*time.Millisecond)
+	log.Request("POST", "/widgets", 404, time.Second)
+	log.Request("DELETE", "/widgets/1", 503, 1500*time.Millisecond)
+	log.Request("GET", "/moved", 301, 0)
+
+	if len(c.Captured()) != 4 {
+		t.Fatalf("Expected 4 log events but received %d", len(c.Captured()))
+	}
+	checkEventExpectation(t, c.Captured()[0], INFO, "GET /widgets 200 (12ms)", nil)
+	checkEventExpectation(t, c.Captured()[1], WARN, "POST /widgets 404 (1s)", nil)
+	checkEventExpectation(t, c.Captured()[2], ERROR, "DELETE /widgets/1 503 (1.5s)", nil)
+	checkEventExpectation(t, c.Captured()[3], INFO, "GET /moved 301 (0s)", nil)
+
+	expected := Fields{
+		"method":   "POST",
+		"path":     "/widgets",
+		"status":   404,
+		"duration": time.Second,
+	}
+	if !reflect.DeepEqual(c.Captured()[1].Context.Fields(), expected) {
+		t.Errorf("Expected request fields %v but received %v", expected, c.Captured()[1].Context.Fields())
+	}
+}
+
+func TestLoggerFatal(t *testing.T) {
+	defer resetCue()
+	codes := stubExit()
+	c := newCapturingCollector()
+	Collect(DEBUG, c)
+
+	cause := errors.New("Fatal Cause")
+	NewLogger("test").Fatal(cause, "Fatal Test")
+
+	if len(c.Captured()) != 1 {
+		t.Fatalf("Expected a single log event but received %d", len(c.Captured()))
+	}
+	checkEventExpectation(t, c.Captured()[0], FATAL, "Fatal Test", cause)
+	if len(*codes) != 1 || (*codes)[0] != 1 {
+		t.Errorf("Expected exit to be called once with code 1, but saw %v instead", *codes)
+	}
+}
+
+func TestLoggerFatalf(t *testing.T) {
+	defer resetCue()
+	codes := stubExit()
+	c := newCapturingCollector()
+	CollectAsync(DEBUG, 10, c)
+
+	cause := errors.New("Fatalf Cause")
+	NewLogger("test").Fatalf(cause, "Fatalf %s", "Test")
+
+	// Async buffers must be flushed before exiting
+	if len(c.Captured()) != 1 {
+		t.Fatalf("Expected a single log event but received %d", len(c.Captured()))
+	}
+	checkEventExpectation(t, c.Captured()[0], FATAL, "Fatalf Test", cause)
+	if len(*codes) != 1 || (*codes)[0] != 1 {
+		t.Errorf("Expected exit to be called once with code 1, but saw %v instead", *codes)
+	}
+}
+
+func TestLoggerFatalExitCode(t *testing.T) {
+	defer resetCue()
+	defer func() { FatalExitCode = 1 }()
+	codes := stubExit()
+	c := newCapturingCollector()
+	Collect(DEBUG, c)
+
+	FatalExitCode = 3
+	NewLogger("test").Fatal(nil, "Fatal Test, nil")
+
+	if len(c.Captured()) != 1 {
+		t.Errorf("Expected a log event to be emitted for a nil error, but received %d events", len(c.Captured()))
+	}
+	if len(*codes) != 1 || (*codes)[0] != 3 {
+		t.Errorf("Expected exit to be called once with code 3, but saw %v instead", *codes)
+	}
+}
+
 func TestLoggerPanic(t *testing.T) {
 	defer resetCue()
 	c := newCapturingCollector()
@@ -220,6 +466,9 @@ func TestLoggerPanic(t *testing.T) {
 		t.Errorf("Expected 2 log events but received %d", len(c.Captured()))
 	}
 	checkEventExpectation(t, c.Captured()[0], FATAL, "Panic Test, error", cause)
+	if c.Captured()[0].Goroutines <= 0 {
+		t.Errorf("Expected panic event to carry a positive goroutine count, but saw %d instead", c.Captured()[0].Goroutines)
+	}
 
 	// We can't use checkEventExpectation with the "other" cause
 	if c.Captured()[1].Level != FATAL {
@@ -464,9 +713,116 @@ func TestLoggerWrap(t *testing.T) {
 	}
 }
 
+func wrappedHelper(log Logger, message string) {
+	nestedWrappedHelper(log, message)
+}
+
+func nestedWrappedHelper(log Logger, message string) {
+	log.Info(message)
+}
+
+// facadeAdapter mimics an adapter exposing cue through another logging API.
+// User calls pass through two adapter frames before reaching cue.
+type facadeAdapter struct {
+	log Logger
+}
+
+func (a facadeAdapter) Info(message string) {
+	a.log2(INFO, message)
+}
+
+func (a facadeAdapter) log2(level Level, message string) {
+	switch level {
+	case INFO:
+		a.log.Info(message)
+	}
+}
+
+func TestNewLoggerWithSkip(t *testing.T) {
+	defer resetCue()
+	c := newCapturingCollector()
+	Collect(DEBUG, c)
+
+	facadeAdapter{log: NewLoggerWithSkip("adapter", 2)}.Info("through the adapter")
+	facadeAdapter{log: NewLogger("adapter")}.Info("unadjusted")
+
+	if len(c.Captured()) != 2 {
+		t.Fatalf("Expected to receive 2 events but received %d", len(c.Captured()))
+	}
+	thisfunc := "github.com/bobziuchkovski/cue.TestNewLoggerWithSkip"
+	if c.Captured()[0].Frames[0].Function != thisfunc {
+		t.Errorf("Event has incorrect source function.  Expected %s, Received %s", thisfunc, c.Captured()[0].Frames[0].Function)
+	}
+	adapterfunc := "github.com/bobziuchkovski/cue.facadeAdapter.log2"
+	if c.Captured()[1].Frames[0].Function != adapterfunc {
+		t.Errorf("Unadjusted event has incorrect source function.  Expected %s, Received %s", adapterfunc, c.Captured()[1].Frames[0].Function)
+	}
+	if c.Captured()[0].Context.Name() != "adapter" {
+		t.Errorf("Expected the context name %q, but saw %q instead", "adapter", c.Captured()[0].Context.Name())
+	}
+}
+
+func TestLoggerWrapN(t *testing.T) {
+	defer resetCue()
+	c := newCapturingCollector()
+	Collect(DEBUG, c)
+
+	log := NewLogger("wrapped")
+	wrappedHelper(log.WrapN(2), "wrapped logger message from nested helpers")
+	wrappedHelper(log.Wrap().Wrap(), "chained wrap message from nested helpers")
+	wrappedHelper(log, "unwrapped logger message from nested helpers")
+
+	if len(c.Captured()) != 3 {
+		t.Errorf("Expected to receive 3 events but received %d", len(c.Captured()))
+	}
+	thisfunc := "github.com/bobziuchkovski/cue.TestLoggerWrapN"
+	for i, event := range c.Captured()[:2] {
+		if event.Frames[0].Function != thisfunc {
+			t.Errorf("Event %d has incorrect source function.  Expected %s, Received %s", i, thisfunc, event.Frames[0].Function)
+		}
+	}
+	helperfunc := "github.com/bobziuchkovski/cue.nestedWrappedHelper"
+	if c.Captured()[2].Frames[0].Function != helperfunc {
+		t.Errorf("Unwrapped event has incorrect source function.  Expected %s, Received %s", helperfunc, c.Captured()[2].Frames[0].Function)
+	}
+}
+
+// depthHelper logs message from depth nested helper frames below its caller.
+func depthHelper(log Logger, depth int, message string) {
+	if depth > 1 {
+		depthHelper(log, depth-1, message)
+		return
+	}
+	log.Info(message)
+}
+
+func TestLoggerWrapNDepths(t *testing.T) {
+	defer resetCue()
+	c := newCapturingCollector()
+	Collect(DEBUG, c)
+
+	log := NewLogger("wrapped")
+	thisfunc := "github.com/bobziuchkovski/cue.TestLoggerWrapNDepths"
+	helperfunc := "github.com/bobziuchkovski/cue.depthHelper"
+	for depth := 1; depth <= 3; depth++ {
+		depthHelper(log.WrapN(depth), depth, "exact skip")
+		depthHelper(log.WrapN(depth-1), depth, "one frame short")
+
+		captured := c.Captured()
+		exact, short := captured[len(captured)-2], captured[len(captured)-1]
+		if exact.Frames[0].Function != thisfunc {
+			t.Errorf("Depth %d event has incorrect source function.  Expected %s, Received %s", depth, thisfunc, exact.Frames[0].Function)
+		}
+		if short.Frames[0].Function != helperfunc {
+			t.Errorf("Depth %d event with one fewer skip has incorrect source function.  Expected %s, Received %s", depth, helperfunc, short.Frames[0].Function)
+		}
+	}
+}
+
 func TestThresholds(t *testing.T) {
 	defer resetCue()
 
+	tracec := newCapturingCollector()
 	debugc := newCapturingCollector()
 	infoc := newCapturingCollector()
 	warnc := newCapturingCollector()
@@ -479,6 +835,7 @@ func TestThresholds(t *testing.T) {
 	log.Debugf("Uncollected Debugf %s", "event")
 	callWithLoggerRecover(func() { panic("Uncollected Panic") }, log, "Uncollected Recover")
 
+	Collect(TRACE, tracec)
 	Collect(DEBUG, debugc)
 	Collect(INFO, infoc)
 	Collect(WARN, warnc)
@@ -486,6 +843,8 @@ func TestThresholds(t *testing.T) {
 	Collect(FATAL, fatalc)
 	Collect(OFF, offc)
 
+	log.Trace("Trace event")
+	log.Tracef("Tracef %s", "event")
 	log.Debug("Debug event")
 	log.Debugf("Debugf %s", "event")
 	log.Info("Info event")
@@ -503,6 +862,9 @@ func TestThresholds(t *testing.T) {
 		log.Panic(cause, "Panic event")
 	})
 
+	if len(tracec.Captured()) != 12 {
+		t.Errorf("Expected collector at TRACE threshold to receive 12 events, but it received %d instead", len(tracec.Captured()))
+	}
 	if len(debugc.Captured()) != 10 {
 		t.Errorf("Expected collector at DEBUG threshold to receive 10 events, but it received %d instead", len(debugc.Captured()))
 	}
@@ -571,6 +933,49 @@ func TestClose(t *testing.T) {
 	}
 }
 
+func TestCloseMarker(t *testing.T) {
+	defer resetCue()
+	sync := newCapturingCollector()
+	async := newCapturingCollector()
+	Collect(DEBUG, sync)
+	CollectAsync(INFO, 100, async)
+	SetCloseMarker(true)
+
+	log := NewLogger("test")
+	log.Debug("message 1")
+	log.Info("message 2")
+
+	err := Close(time.Minute)
+	if err != nil {
+		panic("Failed to close within a minute.  Panicking because we are now in an unknown state.")
+	}
+
+	expected := "cue: flushing and shutting down, 2 events delivered"
+	for _, c := range []*capturingCollector{sync, async} {
+		captured := c.Captured()
+		if len(captured) == 0 {
+			t.Fatal("Expected to see a close marker event but saw no events")
+		}
+		last := captured[len(captured)-1]
+		if last.Level != INFO || last.Message != expected {
+			t.Errorf("Expected the last event to be the INFO close marker %q, but saw %s %q instead", expected, last.Level, last.Message)
+		}
+	}
+	if len(sync.Captured()) != 3 || len(async.Captured()) != 2 {
+		t.Errorf("Expected 3 sync and 2 async events, but saw %d and %d instead", len(sync.Captured()), len(async.Captured()))
+	}
+
+	// The setting is reset by Close.
+	Collect(DEBUG, sync)
+	err = Close(time.Minute)
+	if err != nil {
+		panic("Failed to close within a minute.  Panicking because we are now in an unknown state.")
+	}
+	if len(sync.Captured()) != 3 {
+		t.Errorf("Expected no close marker after the setting was reset, but saw %d events", len(sync.Captured()))
+	}
+}
+
 func TestCloseTimeout(t *testing.T) {
 	defer resetCue()
 	async := newCapturingCollector()
@@ -588,6 +993,117 @@ func TestCloseTimeout(t *testing.T) {
 	}
 }
 
+func TestCheckpoint(t *testing.T) {
+	defer resetCue()
+	async := newCapturingCollector()
+	blocking := newBlockingCollector(async)
+	CollectAsync(DEBUG, 10, blocking)
+
+	var names []string
+	var flushed []int
+	OnCheckpoint(func(name string) {
+		names = append(names, name)
+		flushed = append(flushed, len(async.Captured()))
+	})
+
+	log := NewLogger("test")
+	log.Debug("message 1")
+	log.Debug("message 2")
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		blocking.Unblock()
+	}()
+	err := Checkpoint("phase1")
+	if err != nil {
+		t.Errorf("Encountered unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(names, []string{"phase1"}) {
+		t.Errorf("Expected the checkpoint hook to be called once with %q, but saw %v instead", "phase1", names)
+	}
+	if !reflect.DeepEqual(flushed, []int{2}) {
+		t.Errorf("Expected the checkpoint hook to observe 2 flushed events, but saw %v instead", flushed)
+	}
+
+	// Workers remain running after a checkpoint
+	log.Debug("message 3")
+	async.WaitCaptured(3, time.Second)
+	if len(async.Captured()) != 3 {
+		t.Errorf("Expected a total of 3 async events but received %d instead", len(async.Captured()))
+	}
+}
+
+func TestCheckpointTimeout(t *testing.T) {
+	defer resetCue()
+	defer func(timeout time.Duration) {
+		CheckpointFlushTimeout = timeout
+	}(CheckpointFlushTimeout)
+	CheckpointFlushTimeout = 50 * time.Millisecond
+
+	async := newCapturingCollector()
+	blocking := newBlockingCollector(async)
+	defer blocking.Unblock()
+	CollectAsync(DEBUG, 10, blocking)
+
+	called := false
+	OnCheckpoint(func(name string) {
+		called = true
+	})
+	NewLogger("test").Debug("message 1")
+
+	err := Checkpoint("phase1")
+	if err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Error("Expected to see timeout error waiting for blocked worker to flush")
+	}
+	if called {
+		t.Error("Expected checkpoint hooks to be skipped after a flush timeout, but they were called")
+	}
+}
+
+func TestFlush(t *testing.T) {
+	defer resetCue()
+	async := newCapturingCollector()
+	blocking := newBlockingCollector(async)
+	CollectAsync(DEBUG, 10, blocking)
+
+	log := NewLogger("test")
+	log.Debug("message 1")
+	log.Debug("message 2")
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		blocking.Unblock()
+	}()
+	err := Flush(time.Minute)
+	if err != nil {
+		t.Errorf("Encountered unexpected error: %s", err)
+	}
+	if len(async.Captured()) != 2 {
+		t.Errorf("Expected 2 events to be flushed, but saw %d instead", len(async.Captured()))
+	}
+
+	// Workers remain running after a flush
+	log.Debug("message 3")
+	async.WaitCaptured(3, time.Second)
+	if len(async.Captured()) != 3 {
+		t.Errorf("Expected the worker to remain running after Flush, but saw %d events instead", len(async.Captured()))
+	}
+}
+
+func TestFlushTimeout(t *testing.T) {
+	defer resetCue()
+	async := newCapturingCollector()
+	blocking := newBlockingCollector(async)
+	defer blocking.Unblock()
+	CollectAsync(DEBUG, 10, blocking)
+
+	NewLogger("test").Debug("message 1")
+	err := Flush(50 * time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Error("Expected to see timeout error waiting for blocked worker to flush")
+	}
+}
+
 func TestCloseNoop(t *testing.T) {
 	defer resetCue()
 	err := Close(time.Minute)
@@ -657,6 +1173,68 @@ func TestCollectAsync(t *testing.T) {
 	}
 }
 
+func TestCollectTemporary(t *testing.T) {
+	defer resetCue()
+	permanent := newCapturingCollector()
+	temporary := newCapturingCollector()
+	Collect(DEBUG, permanent)
+	CollectTemporary(DEBUG, 50*time.Millisecond, temporary)
+
+	log := NewLogger("test")
+	log.Info("during the window")
+
+	// Log concurrently throughout the window to exercise teardown
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				log.Debug("background message")
+			}
+		}
+	}()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for len(Collectors()) != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the temporary collector to be disposed, but it's still registered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(done)
+	<-stopped
+
+	captured := len(temporary.Captured())
+	log.Info("after the window")
+	if len(temporary.Captured()) != captured {
+		t.Errorf("Expected no events after disposal, but %d were collected", len(temporary.Captured())-captured)
+	}
+	if captured == 0 || temporary.Captured()[0].Message != "during the window" {
+		t.Error("Expected the temporary collector to receive events during the window, but it didn't")
+	}
+	if Collectors()[0].Collector != permanent {
+		t.Error("Expected the permanent collector to remain registered, but it didn't")
+	}
+}
+
+func TestCollectTemporaryReregistered(t *testing.T) {
+	defer resetCue()
+	c := newCapturingCollector()
+	CollectTemporary(DEBUG, 50*time.Millisecond, c)
+	dispose(c)
+	Collect(DEBUG, c)
+
+	// The timer must not dispose the collector's new registration
+	time.Sleep(100 * time.Millisecond)
+	if len(Collectors()) != 1 {
+		t.Error("Expected the re-registered collector to remain registered, but it didn't")
+	}
+}
+
 func TestDispose(t *testing.T) {
 	defer resetCue()
 	c := newCapturingCollector()
@@ -703,6 +1281,117 @@ func TestSetFrames(t *testing.T) {
 	}
 }
 
+func TestSetThreshold(t *testing.T) {
+	defer resetCue()
+	c1 := newCapturingCollector()
+	c2 := newCapturingCollector()
+	Collect(DEBUG, c1)
+	Collect(WARN, c2)
+
+	log := NewLogger("test")
+	SetThreshold(INFO)
+	log.Debug("message 1")
+	log.Info("message 2")
+	if len(c1.Captured()) != 1 || len(c2.Captured()) != 1 {
+		t.Errorf("Expected each collector to capture exactly 1 event but found %d and %d instead", len(c1.Captured()), len(c2.Captured()))
+	}
+	SetThreshold(OFF)
+	log.Error(errors.New("error"), "message 3")
+	if len(c1.Captured()) != 1 || len(c2.Captured()) != 1 {
+		t.Errorf("Expected each collector to capture exactly 1 event but found %d and %d instead", len(c1.Captured()), len(c2.Captured()))
+	}
+	for _, info := range Collectors() {
+		if info.Threshold != OFF {
+			t.Errorf("Expected collector %s to have an OFF threshold", info)
+		}
+	}
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	defer resetCue()
+	c1 := newCapturingCollector()
+	c2 := newCapturingCollector()
+	Collect(INFO, c1)
+	snapshot := Snapshot()
+
+	SetLevel(DEBUG, c1)
+	SetFrames(0, 0)
+	CollectAsync(DEBUG, 10, c2)
+	log := NewLogger("test")
+	log.Debug("message 1")
+	if len(c1.Captured()) != 1 || len(c1.Captured()[0].Frames) != 0 {
+		t.Fatal("Expected the updated settings to take effect, but they didn't")
+	}
+
+	Restore(snapshot)
+	if len(c2.Captured()) != 1 {
+		t.Errorf("Expected the removed collector to be flushed, but it captured %d events", len(c2.Captured()))
+	}
+	log.Debug("message 2")
+	log.Info("message 3")
+	if len(c1.Captured()) != 2 {
+		t.Fatalf("Expected the original threshold to be restored, but saw %d events", len(c1.Captured()))
+	}
+	if len(c1.Captured()[1].Frames) != 1 {
+		t.Errorf("Expected the original frame settings to be restored, but saw %d frames", len(c1.Captured()[1].Frames))
+	}
+	if len(c2.Captured()) != 1 {
+		t.Errorf("Expected the removed collector to receive no further events, but it captured %d events", len(c2.Captured()))
+	}
+	infos := Collectors()
+	if len(infos) != 1 || infos[0].Collector != c1 || infos[0].Threshold != INFO {
+		t.Errorf("Expected only the original collector to remain registered, but found %v", infos)
+	}
+}
+
+func TestRestoreDisposedCollector(t *testing.T) {
+	defer resetCue()
+	c := newCapturingCollector()
+	CollectNamed("disposed", INFO, c)
+	snapshot := Snapshot()
+
+	DisposeByName("disposed")
+	Restore(snapshot)
+	if len(Collectors()) != 0 {
+		t.Errorf("Expected disposed collectors to remain unregistered, but found %v", Collectors())
+	}
+
+	// A zero-value snapshot is a no-op
+	Collect(INFO, c)
+	Restore(ConfigSnapshot{})
+	if len(Collectors()) != 1 {
+		t.Errorf("Expected a zero-value snapshot to leave collectors registered, but found %v", Collectors())
+	}
+}
+
+func TestCollectors(t *testing.T) {
+	defer resetCue()
+	if len(Collectors()) != 0 {
+		t.Errorf("Expected no collectors but found %d instead", len(Collectors()))
+	}
+
+	c1 := newCapturingCollector()
+	c2 := newCapturingCollector()
+	Collect(DEBUG, c1)
+	CollectNamed("named", WARN, c2)
+	SetLevel(INFO, c1)
+
+	infos := Collectors()
+	if len(infos) != 2 {
+		t.Fatalf("Expected 2 collectors but found %d instead", len(infos))
+	}
+	// Sorted by String(), so the named collector comes first
+	expected := []CollectorInfo{
+		{Collector: c2, Name: "named", Threshold: WARN},
+		{Collector: c1, Threshold: INFO},
+	}
+	for i := range expected {
+		if infos[i] != expected[i] {
+			t.Errorf("Expected collector info %s but got %s instead", expected[i], infos[i])
+		}
+	}
+}
+
 func TestSetLevel(t *testing.T) {
 	defer resetCue()
 	c := newCapturingCollector()
@@ -730,6 +1419,71 @@ func TestSetLevel(t *testing.T) {
 	}
 }
 
+func TestCollectNamed(t *testing.T) {
+	defer resetCue()
+	c1 := newCapturingCollector()
+	c2 := newCapturingCollector()
+	CollectNamed("first", DEBUG, c1)
+	CollectNamed("second", DEBUG, c2)
+
+	log := NewLogger("test")
+	log.Debug("message 1")
+
+	SetLevelByName("first", INFO)
+	log.Debug("message 2")
+	if len(c1.Captured()) != 1 {
+		t.Errorf("Expected c1 to collect exactly 1 event but found %d instead", len(c1.Captured()))
+	}
+	if len(c2.Captured()) != 2 {
+		t.Errorf("Expected c2 to collect exactly 2 events but found %d instead", len(c2.Captured()))
+	}
+
+	DisposeByName("second")
+	log.Info("message 3")
+	if len(c1.Captured()) != 2 {
+		t.Errorf("Expected c1 to collect exactly 2 events but found %d instead", len(c1.Captured()))
+	}
+	if len(c2.Captured()) != 2 {
+		t.Errorf("Expected c2 to collect exactly 2 events after disposal but found %d instead", len(c2.Captured()))
+	}
+}
+
+func TestCollectNamedDuplicateName(t *testing.T) {
+	defer resetCue()
+	c1 := newCapturingCollector()
+	c2 := newCapturingCollector()
+	CollectNamed("dup", DEBUG, c1)
+	CollectNamed("dup", DEBUG, c2)
+
+	NewLogger("test").Debug("message")
+	if len(c1.Captured()) != 2 {
+		t.Fatalf("Expected c1 to collect exactly 2 events but found %d instead", len(c1.Captured()))
+	}
+	if c1.Captured()[0].Level != WARN || !strings.Contains(c1.Captured()[0].Message, `Collector name "dup" is already registered`) {
+		t.Errorf("Expected to see a duplicate name warning sent to c1, but saw %#v instead", c1.Captured()[0])
+	}
+	if len(c2.Captured()) != 0 {
+		t.Errorf("Expected c2 to collect 0 events but found %d instead", len(c2.Captured()))
+	}
+}
+
+func TestByNameNotPresent(t *testing.T) {
+	defer resetCue()
+	c := newCapturingCollector()
+	Collect(DEBUG, c)
+
+	// Unnamed collectors must not be matched by the empty name
+	SetLevelByName("", OFF)
+	DisposeByName("")
+	SetLevelByName("bogus", OFF)
+	DisposeByName("bogus")
+
+	NewLogger("test").Debug("message")
+	if len(c.Captured()) != 1 {
+		t.Errorf("Expected to collect exactly 1 event but found %d instead", len(c.Captured()))
+	}
+}
+
 func TestSetLevelCollectorNotPresent(t *testing.T) {
 	// Make sure nothing blows-up
 	defer resetCue()
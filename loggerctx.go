@@ -0,0 +1,136 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cue
+
+import (
+	gocontext "context"
+	"time"
+)
+
+// loggerKey is the context.Context key for Logger values.  It's unexported
+// to prevent collisions with keys defined in other packages.
+type loggerKey struct{}
+
+// NewContextWithLogger returns a copy of ctx that carries the given logger.
+// This is useful for propagating request-scoped loggers, such as loggers
+// carrying request IDs, down a call chain.  Use LoggerFromContext to
+// retrieve the logger.
+func NewContextWithLogger(ctx gocontext.Context, l Logger) gocontext.Context {
+	return gocontext.WithValue(ctx, loggerKey{}, l)
+}
+
+// LoggerFromContext returns the Logger stored in ctx by NewContextWithLogger.
+// If ctx doesn't carry a logger, LoggerFromContext returns a no-op logger, so
+// callers needn't check for nil.  The no-op logger doesn't emit events, but
+// its Panic, Fatal, and Recover methods retain their control flow semantics.
+func LoggerFromContext(ctx gocontext.Context) Logger {
+	if l, ok := ctx.Value(loggerKey{}).(Logger); ok && l != nil {
+		return l
+	}
+	return nopLogger{}
+}
+
+// NewNopLogger returns a Logger that never emits events.  It's intended as a
+// safe default for libraries that accept a Logger from their callers.  Unlike
+// NewLogger, the returned logger doesn't create events or capture frames, so
+// it has no dependence on registered collectors.  Its Error, Errorf, and Errors
+// methods still return the passed errors, and its Panic, Fatal, and Recover
+// methods retain their control flow semantics.
+func NewNopLogger() Logger {
+	return nopLogger{}
+}
+
+// nopLogger is a Logger that never emits events.
+type nopLogger struct{}
+
+func (l nopLogger) String() string {
+	return "Logger(nop)"
+}
+
+func (l nopLogger) WithFields(fields Fields) Logger {
+	return l
+}
+
+func (l nopLogger) WithValue(key string, value interface{}) Logger {
+	return l
+}
+
+func (l nopLogger) WithError(err error) Logger {
+	return l
+}
+
+func (l nopLogger) Wrap() Logger {
+	return l
+}
+
+func (l nopLogger) WrapN(n int) Logger {
+	return l
+}
+
+func (l nopLogger) Trace(message string)                             {}
+func (l nopLogger) Tracef(format string, values ...interface{})      {}
+func (l nopLogger) Debug(message string)                             {}
+func (l nopLogger) Debugf(format string, values ...interface{})      {}
+func (l nopLogger) Info(message string)                              {}
+func (l nopLogger) Infof(format string, values ...interface{})       {}
+func (l nopLogger) Warn(message string)                              {}
+func (l nopLogger) Warnf(format string, values ...interface{})       {}
+func (l nopLogger) ReportRecovery(cause interface{}, message string) {}
+
+func (l nopLogger) Request(method, path string, status int, duration time.Duration) {}
+
+func (l nopLogger) Error(err error, message string) error {
+	return err
+}
+
+func (l nopLogger) Errors(errs []error, message string) error {
+	return combineErrors(errs)
+}
+
+func (l nopLogger) Errorf(err error, format string, values ...interface{}) error {
+	return err
+}
+
+func (l nopLogger) Panic(cause interface{}, message string) {
+	if cause == nil {
+		return
+	}
+	panic(cause)
+}
+
+func (l nopLogger) Panicf(cause interface{}, format string, values ...interface{}) {
+	if cause == nil {
+		return
+	}
+	panic(cause)
+}
+
+func (l nopLogger) Fatal(err error, message string) {
+	exitFatal()
+}
+
+func (l nopLogger) Fatalf(err error, format string, values ...interface{}) {
+	exitFatal()
+}
+
+func (l nopLogger) Recover(message string) {
+	recover()
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cue
+
+import (
+	gocontext "context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestLoggerFromContext(t *testing.T) {
+	defer resetCue()
+	c := newCapturingCollector()
+	Collect(DEBUG, c)
+
+	log := NewLogger("test").WithValue("request_id", 42)
+	ctx := NewContextWithLogger(gocontext.Background(), log)
+	LoggerFromContext(ctx).Info("from context")
+
+	if len(c.Captured()) != 1 {
+		t.Fatalf("Expected 1 log event but received %d", len(c.Captured()))
+	}
+	event := c.Captured()[0]
+	if event.Message != "from context" {
+		t.Errorf("Expected message %q but got %q instead", "from context", event.Message)
+	}
+	if event.Context.Fields()["request_id"] != 42 {
+		t.Errorf("Expected request_id field to be 42 but got %v instead", event.Context.Fields()["request_id"])
+	}
+}
+
+func TestLoggerFromContextMissing(t *testing.T) {
+	defer resetCue()
+	c := newCapturingCollector()
+	Collect(DEBUG, c)
+
+	cause := errors.New("Error Cause")
+	log := LoggerFromContext(gocontext.Background())
+	log = log.WithFields(Fields{"k": "v"}).WithValue("k2", "v2").WithError(cause).Wrap().WrapN(2)
+	log.Trace("trace")
+	log.Debugf("debug %d", 1)
+	log.Info("info")
+	log.Warnf("warn %d", 1)
+	if log.Error(cause, "error") != cause {
+		t.Error("Expected to receive the same error cause as the return value but didn't")
+	}
+	if log.Errorf(cause, "error %d", 1) != cause {
+		t.Error("Expected to receive the same error cause as the return value but didn't")
+	}
+	log.ReportRecovery(cause, "recovery")
+
+	if len(c.Captured()) != 0 {
+		t.Errorf("Expected no log events but received %d", len(c.Captured()))
+	}
+}
+
+func TestNewNopLogger(t *testing.T) {
+	defer resetCue()
+	c := newCapturingCollector()
+	Collect(DEBUG, c)
+
+	cause := errors.New("Error Cause")
+	log := NewNopLogger()
+	if s := fmt.Sprint(log); s != "Logger(nop)" {
+		t.Errorf("Expected a String() value of %q but got %q instead", "Logger(nop)", s)
+	}
+	log.WithValue("k", "v").Info("info")
+	if log.Error(cause, "error") != cause {
+		t.Error("Expected to receive the same error cause as the return value but didn't")
+	}
+	if log.Errors([]error{nil, cause}, "errors") != cause {
+		t.Error("Expected to receive the single non-nil error as the return value but didn't")
+	}
+	if len(c.Captured()) != 0 {
+		t.Errorf("Expected no log events but received %d", len(c.Captured()))
+	}
+}
+
+func TestLoggerFromContextMissingPanic(t *testing.T) {
+	defer resetCue()
+	log := LoggerFromContext(gocontext.Background())
+
+	recovered := func() (cause interface{}) {
+		defer func() {
+			cause = recover()
+		}()
+		log.Panic("cause", "panic")
+		return nil
+	}()
+	if recovered != "cause" {
+		t.Errorf("Expected the no-op logger to panic with %q but got %v instead", "cause", recovered)
+	}
+
+	func() {
+		defer log.Recover("recovered")
+		panic("cause")
+	}()
+}
+
+func TestLoggerFromContextMissingFatal(t *testing.T) {
+	defer resetCue()
+	codes := stubExit()
+
+	LoggerFromContext(gocontext.Background()).Fatal(errors.New("fatal"), "fatal")
+	if len(*codes) != 1 || (*codes)[0] != FatalExitCode {
+		t.Errorf("Expected a single exit with code %d but got %v instead", FatalExitCode, *codes)
+	}
+}
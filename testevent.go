@@ -0,0 +1,113 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package cue
+
+import (
+	"fmt"
+	"time"
+)
+
+// EventOption configures events built by NewTestEvent.
+type EventOption func(event *Event)
+
+// NewTestEvent returns a new event for use in tests.  It's intended for
+// exercising custom collectors and formatters without going through a
+// Logger.  Production code should always log via a Logger, which captures
+// frames and diagnostics that NewTestEvent leaves unset.
+//
+// Without options, the returned event is an INFO event with an empty
+// message, an empty context named "test", the current time, and no frames.
+// Options are applied in order, so later options override earlier ones.
+func NewTestEvent(opts ...EventOption) *Event {
+	event := &Event{
+		Time:    time.Now(),
+		Level:   INFO,
+		Context: NewContext("test"),
+	}
+	for _, opt := range opts {
+		opt(event)
+	}
+	return event
+}
+
+// EventLevel sets the event's Level field.
+func EventLevel(level Level) EventOption {
+	return func(event *Event) {
+		event.Level = level
+	}
+}
+
+// EventMessage sets the event's Message field.
+func EventMessage(message string) EventOption {
+	return func(event *Event) {
+		event.Message = message
+	}
+}
+
+// EventError sets the event's Error field.
+func EventError(err error) EventOption {
+	return func(event *Event) {
+		event.Error = err
+	}
+}
+
+// EventTime sets the event's Time field.
+func EventTime(t time.Time) EventOption {
+	return func(event *Event) {
+		event.Time = t
+	}
+}
+
+// EventContext sets the event's Context field.  A nil context is replaced
+// with an empty context, since formatters and collectors expect a non-nil
+// Context.
+func EventContext(context Context) EventOption {
+	return func(event *Event) {
+		if context == nil {
+			context = NewContext("test")
+		}
+		event.Context = context
+	}
+}
+
+// EventFrames sets the event's Frames field to n synthetic frames.  Frames
+// are ordered from the call site outward, as they are for logged events, and
+// follow a pattern based on their depth.  The call site frame, Frames[0],
+// for EventFrames(3) is:
+//
+//	Package:  "example.com/frame3"
+//	Function: "example.com/frame3.function3"
+//	File:     "/path/example.com/frame3/file3.go"
+//	Line:     3
+//
+// If n is less than 1, the Frames field is cleared.
+func EventFrames(n int) EventOption {
+	return func(event *Event) {
+		event.Frames = nil
+		for i := n; i > 0; i-- {
+			event.Frames = append(event.Frames, &Frame{
+				Package:  fmt.Sprintf("example.com/frame%d", i),
+				Function: fmt.Sprintf("example.com/frame%d.function%d", i, i),
+				File:     fmt.Sprintf("/path/example.com/frame%d/file%d.go", i, i),
+				Line:     i,
+			})
+		}
+	}
+}
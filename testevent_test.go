@@ -0,0 +1,100 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package cue
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewTestEventDefaults(t *testing.T) {
+	before := time.Now()
+	event := NewTestEvent()
+	if event.Level != INFO {
+		t.Errorf("Expected a default level of INFO, but saw %s instead", event.Level)
+	}
+	if event.Message != "" || event.Error != nil || event.Frames != nil {
+		t.Errorf("Expected an empty message, error, and frames, but saw %#v instead", event)
+	}
+	if event.Context == nil || event.Context.NumValues() != 0 {
+		t.Errorf("Expected an empty, non-nil context, but saw %v instead", event.Context)
+	}
+	if event.Time.Before(before) {
+		t.Errorf("Expected the event time to default to the current time, but saw %s instead", event.Time)
+	}
+}
+
+func TestNewTestEventOptions(t *testing.T) {
+	err := errors.New("test error")
+	when := time.Date(2016, 1, 2, 15, 4, 5, 0, time.UTC)
+	ctx := NewContext("ctx").WithValue("k1", "v1")
+	event := NewTestEvent(
+		EventLevel(ERROR),
+		EventMessage("test message"),
+		EventError(err),
+		EventTime(when),
+		EventContext(ctx),
+		EventFrames(2),
+	)
+
+	if event.Level != ERROR {
+		t.Errorf("Expected level ERROR, but saw %s instead", event.Level)
+	}
+	if event.Message != "test message" {
+		t.Errorf("Expected message %q, but saw %q instead", "test message", event.Message)
+	}
+	if event.Error != err {
+		t.Errorf("Expected error %v, but saw %v instead", err, event.Error)
+	}
+	if !event.Time.Equal(when) {
+		t.Errorf("Expected time %s, but saw %s instead", when, event.Time)
+	}
+	if event.Context != ctx {
+		t.Errorf("Expected context %v, but saw %v instead", ctx, event.Context)
+	}
+
+	expected := []*Frame{
+		{
+			Package:  "example.com/frame2",
+			Function: "example.com/frame2.function2",
+			File:     "/path/example.com/frame2/file2.go",
+			Line:     2,
+		},
+		{
+			Package:  "example.com/frame1",
+			Function: "example.com/frame1.function1",
+			File:     "/path/example.com/frame1/file1.go",
+			Line:     1,
+		},
+	}
+	if !reflect.DeepEqual(event.Frames, expected) {
+		t.Errorf("Expected frames %#v, but saw %#v instead", expected, event.Frames)
+	}
+
+	event = NewTestEvent(EventFrames(3), EventFrames(0), EventContext(nil))
+	if event.Frames != nil {
+		t.Errorf("Expected EventFrames(0) to clear frames, but saw %#v instead", event.Frames)
+	}
+	if event.Context == nil {
+		t.Error("Expected a nil context to be replaced with an empty context")
+	}
+}
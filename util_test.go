@@ -22,6 +22,7 @@ package cue
 
 import (
 	"fmt"
+	"os"
 	"sync"
 	"time"
 )
@@ -237,4 +238,15 @@ func resetCue() {
 	if err != nil {
 		panic("Cue failed to reset within a minute")
 	}
+	exit = os.Exit
+}
+
+// stubExit replaces the exit function with a stub that records exit codes.
+// Calling resetCue restores the original.
+func stubExit() *[]int {
+	codes := &[]int{}
+	exit = func(code int) {
+		*codes = append(*codes, code)
+	}
+	return codes
 }
@@ -0,0 +1,61 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cue
+
+import (
+	"strconv"
+	"strings"
+)
+
+var byteUnits = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+
+// ByteSize is a context value type representing a count of bytes.  Human
+// formats render ByteSize values in binary units ("100MiB"), while JSON
+// formats render the raw integer value (104857600).
+//
+//	log.WithValue("bytes", cue.ByteSize(n)).Info("Upload complete")
+type ByteSize int64
+
+// String returns a human-readable representation of the byte count using
+// binary (base 1024) units, rounded to at most two decimal places.
+func (b ByteSize) String() string {
+	value := float64(b)
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+
+	unit := 0
+	for value >= 1024 && unit < len(byteUnits)-1 {
+		value /= 1024
+		unit++
+	}
+
+	formatted := strconv.FormatFloat(value, 'f', 2, 64)
+	formatted = strings.TrimRight(strings.TrimRight(formatted, "0"), ".")
+	return sign + formatted + byteUnits[unit]
+}
+
+// MarshalJSON returns the raw byte count as a JSON integer.
+func (b ByteSize) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(int64(b), 10)), nil
+}
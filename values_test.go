@@ -0,0 +1,62 @@
+// Copyright (c) 2016 Bob Ziuchkovski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cue
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+var byteSizeTests = []struct {
+	Size  ByteSize
+	Human string
+	JSON  string
+}{
+	{Size: 0, Human: "0B", JSON: "0"},
+	{Size: 512, Human: "512B", JSON: "512"},
+	{Size: 1024, Human: "1KiB", JSON: "1024"},
+	{Size: 1536, Human: "1.5KiB", JSON: "1536"},
+	{Size: 104857600, Human: "100MiB", JSON: "104857600"},
+	{Size: 1288490189, Human: "1.2GiB", JSON: "1288490189"},
+	{Size: 5 << 40, Human: "5TiB", JSON: "5497558138880"},
+	{Size: -2048, Human: "-2KiB", JSON: "-2048"},
+}
+
+func TestByteSize(t *testing.T) {
+	for _, test := range byteSizeTests {
+		ctx := NewContext("test").WithValue("bytes", test.Size)
+		value := ctx.Fields()["bytes"]
+
+		human := fmt.Sprint(value)
+		if human != test.Human {
+			t.Errorf("ByteSize human rendering is incorrect.  Size: %d, Expected: %s, Received: %s", int64(test.Size), test.Human, human)
+		}
+
+		marshaled, err := json.Marshal(value)
+		if err != nil {
+			t.Errorf("Encountered unexpected error marshaling ByteSize: %s", err)
+		}
+		if string(marshaled) != test.JSON {
+			t.Errorf("ByteSize JSON rendering is incorrect.  Size: %d, Expected: %s, Received: %s", int64(test.Size), test.JSON, marshaled)
+		}
+	}
+}
@@ -44,13 +44,17 @@ const (
 type worker interface {
 	Send(event *Event)
 	Terminate(flush bool)
+
+	// Flush blocks until events queued prior to the call have been sent to
+	// the collector.  The worker remains running.
+	Flush()
 }
 
-func newWorker(c Collector, bufsize int) worker {
+func newWorker(c Collector, bufsize int, opts AsyncOptions) worker {
 	if bufsize == 0 {
 		return newSyncWorker(c)
 	}
-	return newAsyncWorker(c, bufsize)
+	return newAsyncWorker(c, bufsize, opts)
 }
 
 type syncWorker struct {
@@ -82,6 +86,13 @@ func (w *syncWorker) Terminate(flush bool) {
 	w.terminated = true
 }
 
+func (w *syncWorker) Flush() {
+	// Events are sent synchronously, so we only need to wait for any
+	// in-process send to complete.
+	w.mu.Lock()
+	defer w.mu.Unlock()
+}
+
 func (w *syncWorker) sendEvent(event *Event) {
 	err := sendWithRetries(w.collector, event, sendRetries)
 	if err == nil {
@@ -96,21 +107,40 @@ type asyncWorker struct {
 	// 64-bit alignment.  See the sync/atomic docs for details.
 	drops uint64
 
-	collector Collector
-	queue     chan *Event
-	terminate chan bool
-	finished  chan struct{}
-	lastdrops uint64
+	collector  Collector
+	queue      chan *Event
+	flush      chan chan struct{}
+	terminate  chan bool
+	finished   chan struct{}
+	lastdrops  uint64
+	dropOldest bool
+
+	// If positive, the collector is periodically drained and flushed.
+	flushInterval time.Duration
+
+	// OnDrop is called by a separate notifier goroutine, which is signaled
+	// via dropSignal when the drop counter is incremented.
+	onDrop     func(dropped uint64)
+	dropSignal chan struct{}
 }
 
-func newAsyncWorker(c Collector, bufsize int) worker {
+func newAsyncWorker(c Collector, bufsize int, opts AsyncOptions) worker {
 	w := &asyncWorker{
-		collector: c,
-		queue:     make(chan *Event, bufsize),
-		terminate: make(chan bool, 1),
-		finished:  make(chan struct{}),
+		collector:  c,
+		queue:      make(chan *Event, bufsize),
+		flush:      make(chan chan struct{}),
+		terminate:  make(chan bool, 1),
+		finished:   make(chan struct{}),
+		dropOldest: opts.DropOldest,
+		onDrop:     opts.OnDrop,
+		dropSignal: make(chan struct{}, 1),
+
+		flushInterval: opts.FlushInterval,
 	}
 	go w.run()
+	if w.onDrop != nil {
+		go w.notifyDrops()
+	}
 	return w
 }
 
@@ -119,11 +149,74 @@ func (w *asyncWorker) Send(e *Event) {
 	case w.queue <- e:
 		// No-op...event is queued
 	default:
-		atomic.AddUint64(&w.drops, 1)
+		if w.dropOldest {
+			w.replaceOldest(e)
+			return
+		}
+		w.countDrop()
+	}
+}
+
+// replaceOldest discards the oldest queued event to make room for e.  If
+// another sender claims the freed slot first, e is dropped instead.  Either
+// way, exactly one event is dropped.
+func (w *asyncWorker) replaceOldest(e *Event) {
+	select {
+	case <-w.queue:
+	default:
+		// The worker drained the queue in the meantime
+	}
+	w.countDrop()
+
+	select {
+	case w.queue <- e:
+		// No-op...event is queued
+	default:
+		// Another sender claimed the slot.  We've already counted a drop.
+	}
+}
+
+// countDrop increments the drop counter and signals the drop notifier, if
+// any.  It never blocks: if the notifier hasn't yet handled a previous
+// signal, the signals are coalesced.
+func (w *asyncWorker) countDrop() uint64 {
+	drops := atomic.AddUint64(&w.drops, 1)
+	if w.onDrop != nil {
+		select {
+		case w.dropSignal <- struct{}{}:
+		default:
+		}
+	}
+	return drops
+}
+
+// notifyDrops calls onDrop with the current drop count whenever it's
+// signaled.  It runs on its own goroutine so slow callbacks never block
+// logging calls or the worker.
+func (w *asyncWorker) notifyDrops() {
+	var notified uint64
+	for {
+		select {
+		case <-w.dropSignal:
+			drops := atomic.LoadUint64(&w.drops)
+			if drops > notified {
+				notified = drops
+				w.onDrop(drops)
+			}
+		case <-w.finished:
+			return
+		}
 	}
 }
 
 func (w *asyncWorker) run() {
+	var tick <-chan time.Time
+	if w.flushInterval > 0 {
+		ticker := time.NewTicker(w.flushInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
 	for {
 		select {
 		case event := <-w.queue:
@@ -131,6 +224,12 @@ func (w *asyncWorker) run() {
 			if event != nil {
 				w.sendEvent(event)
 			}
+		case done := <-w.flush:
+			w.drain()
+			close(done)
+		case <-tick:
+			w.drain()
+			flushCollector(w.collector)
 		case flush := <-w.terminate:
 			w.cleanup(flush)
 			close(w.finished)
@@ -146,6 +245,37 @@ func (w *asyncWorker) Terminate(flush bool) {
 	<-w.finished
 }
 
+func (w *asyncWorker) Flush() {
+	done := make(chan struct{})
+	select {
+	case w.flush <- done:
+		<-done
+	case <-w.finished:
+		// The worker has terminated, and its queue was flushed or discarded
+	}
+}
+
+// drain sends the events that are currently queued.  Events queued while
+// draining are left for the run loop, so drain returns even if senders
+// outpace the collector.
+func (w *asyncWorker) drain() {
+	for pending := len(w.queue); pending > 0; pending-- {
+		select {
+		case event, ok := <-w.queue:
+			if !ok {
+				return
+			}
+			w.handleDrops()
+			if event != nil {
+				w.sendEvent(event)
+			}
+		default:
+			// Senders dropping the oldest event may have emptied the queue
+			return
+		}
+	}
+}
+
 func (w *asyncWorker) cleanup(flush bool) {
 	if flush {
 		for event := range w.queue {
@@ -161,7 +291,7 @@ func (w *asyncWorker) sendEvent(event *Event) {
 	if err == nil {
 		return
 	}
-	drops := atomic.AddUint64(&w.drops, 1)
+	drops := w.countDrop()
 	handleDegradation(w.collector, err, drops)
 	w.lastdrops = drops
 }
@@ -220,6 +350,19 @@ func ensureErrorSent(c Collector, err error, drops uint64) {
 	}
 }
 
+// flushCollector calls c's Flush method, if any.  See AsyncOptions for
+// details.
+func flushCollector(c Collector) {
+	defer recoverCollector(c)
+	flusher, ok := c.(interface {
+		Flush() error
+	})
+	if !ok {
+		return
+	}
+	internalLogger.Errorf(flusher.Flush(), "Failed to flush collector %s", c)
+}
+
 func closeCollector(c Collector) {
 	closer, ok := c.(io.Closer)
 	if !ok {
@@ -21,6 +21,7 @@
 package cue
 
 import (
+	"fmt"
 	"reflect"
 	"strings"
 	"testing"
@@ -28,16 +29,16 @@ import (
 )
 
 func TestNewWorker(t *testing.T) {
-	w := newWorker(newCapturingCollector(), 0)
+	w := newWorker(newCapturingCollector(), 0, AsyncOptions{})
 	checkSync(t, w)
 
-	w = newWorker(newCapturingCollector(), 1)
+	w = newWorker(newCapturingCollector(), 1, AsyncOptions{})
 	checkAsync(t, w)
 }
 
 func TestSyncWorkerSend(t *testing.T) {
 	c := newCapturingCollector()
-	w := newWorker(c, 0)
+	w := newWorker(c, 0, AsyncOptions{})
 	checkSync(t, w)
 
 	w.Send(&Event{})
@@ -48,7 +49,7 @@ func TestSyncWorkerSend(t *testing.T) {
 
 func TestSyncWorkerRetry(t *testing.T) {
 	c := newCapturingCollector()
-	w := newWorker(newFailingCollector(c, sendRetries), 0)
+	w := newWorker(newFailingCollector(c, sendRetries), 0, AsyncOptions{})
 	checkSync(t, w)
 
 	e := &Event{}
@@ -99,7 +100,7 @@ func TestSyncWorkerPanic(t *testing.T) {
 	Collect(DEBUG, c1)
 
 	c2 := newCapturingCollector()
-	w := newWorker(newPanickingCollector(c2, 1), 0)
+	w := newWorker(newPanickingCollector(c2, 1), 0, AsyncOptions{})
 	checkSync(t, w)
 
 	e := &Event{}
@@ -117,7 +118,7 @@ func TestSyncWorkerPanic(t *testing.T) {
 func TestSyncWorkerTerminate(t *testing.T) {
 	c := newCapturingCollector()
 	closing := newClosingCollector(c)
-	w := newWorker(closing, 0)
+	w := newWorker(closing, 0, AsyncOptions{})
 	checkSync(t, w)
 
 	w.Send(&Event{})
@@ -131,7 +132,7 @@ func TestSyncWorkerTerminate(t *testing.T) {
 
 func TestAsyncWorkerSend(t *testing.T) {
 	c := newCapturingCollector()
-	w := newWorker(c, 10)
+	w := newWorker(c, 10, AsyncOptions{})
 	checkAsync(t, w)
 
 	w.Send(&Event{})
@@ -148,7 +149,7 @@ func TestAsyncWorkerSendQueueFull(t *testing.T) {
 
 	c2 := newCapturingCollector()
 	blocking := newBlockingCollector(c2)
-	w := newWorker(blocking, 1)
+	w := newWorker(blocking, 1, AsyncOptions{})
 	checkAsync(t, w)
 
 	e1 := &Event{Level: DEBUG, Message: "Original, blocked message"}
@@ -191,9 +192,138 @@ func TestAsyncWorkerSendQueueFull(t *testing.T) {
 	}
 }
 
+var dropPolicyTests = []struct {
+	Name      string
+	Options   AsyncOptions
+	Survivors []string
+}{
+	{Name: "DropNewest", Options: AsyncOptions{}, Survivors: []string{"1", "2", "3"}},
+	{Name: "DropOldest", Options: AsyncOptions{DropOldest: true}, Survivors: []string{"3", "4", "5"}},
+}
+
+func TestAsyncWorkerDropPolicy(t *testing.T) {
+	for _, test := range dropPolicyTests {
+		// The worker goroutine isn't started, so the queue is never drained.
+		w := &asyncWorker{
+			queue:      make(chan *Event, 3),
+			dropOldest: test.Options.DropOldest,
+		}
+		for i := 1; i <= 5; i++ {
+			w.Send(&Event{Message: fmt.Sprint(i)})
+		}
+		close(w.queue)
+
+		var survivors []string
+		for event := range w.queue {
+			survivors = append(survivors, event.Message)
+		}
+		if !reflect.DeepEqual(survivors, test.Survivors) {
+			t.Errorf("Queued events are incorrect.  Test: %s, Expected: %v, Received: %v", test.Name, test.Survivors, survivors)
+		}
+		if w.drops != 2 {
+			t.Errorf("Drop count is incorrect.  Test: %s, Expected: 2, Received: %d", test.Name, w.drops)
+		}
+	}
+}
+
+func TestCollectAsyncWithDropOldest(t *testing.T) {
+	defer resetCue()
+	c := newCapturingCollector()
+	CollectAsyncWith(DEBUG, 10, c, AsyncOptions{DropOldest: true})
+
+	log := NewLogger("test")
+	log.Debug("message")
+	Close(5 * time.Second)
+	if len(c.Captured()) != 1 {
+		t.Errorf("Expected to see 1 event, but saw %d instead", len(c.Captured()))
+	}
+}
+
+func TestAsyncWorkerOnDrop(t *testing.T) {
+	defer resetCue()
+	c := newCapturingCollector()
+	blocking := newBlockingCollector(c)
+
+	notified := make(chan uint64, 100)
+	release := make(chan struct{})
+	w := newWorker(blocking, 1, AsyncOptions{OnDrop: func(dropped uint64) {
+		notified <- dropped
+		<-release
+	}})
+
+	finished := make(chan struct{})
+	go func() {
+		// The worker blocks on the first event and the second fills the
+		// buffer, so the remaining events are dropped.  A blocked OnDrop
+		// callback mustn't block sends.
+		for i := 0; i < 10; i++ {
+			w.Send(&Event{})
+		}
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected sends to return while OnDrop is blocked, but they didn't")
+	}
+
+	select {
+	case dropped := <-notified:
+		if dropped == 0 {
+			t.Error("Expected OnDrop to receive a positive drop count")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected OnDrop to be called, but it wasn't")
+	}
+
+	close(release)
+	blocking.Unblock()
+	w.Terminate(true)
+
+	var last uint64
+	for len(notified) > 0 {
+		dropped := <-notified
+		if dropped <= last {
+			t.Errorf("Expected increasing drop counts, but saw %d after %d", dropped, last)
+		}
+		last = dropped
+	}
+}
+
+type flushingCollector struct {
+	Collector
+	flushed chan struct{}
+}
+
+func (c *flushingCollector) Flush() error {
+	select {
+	case c.flushed <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func TestAsyncWorkerFlushInterval(t *testing.T) {
+	c := newCapturingCollector()
+	flushing := &flushingCollector{Collector: c, flushed: make(chan struct{}, 1)}
+	w := newWorker(flushing, 10, AsyncOptions{FlushInterval: 10 * time.Millisecond})
+	defer w.Terminate(false)
+
+	w.Send(&Event{})
+	select {
+	case <-flushing.flushed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the collector to be flushed periodically, but it wasn't")
+	}
+	if len(c.Captured()) != 1 {
+		t.Errorf("Expected the queued event to be sent prior to flushing, but %d events were captured", len(c.Captured()))
+	}
+}
+
 func TestAsyncWorkerRetry(t *testing.T) {
 	c := newCapturingCollector()
-	w := newWorker(newFailingCollector(c, sendRetries), 10)
+	w := newWorker(newFailingCollector(c, sendRetries), 10, AsyncOptions{})
 	checkAsync(t, w)
 
 	e := &Event{}
@@ -245,7 +375,7 @@ func TestAsyncWorkerPanic(t *testing.T) {
 	Collect(DEBUG, c1)
 
 	c2 := newCapturingCollector()
-	w := newWorker(newPanickingCollector(c2, 1), 10)
+	w := newWorker(newPanickingCollector(c2, 1), 10, AsyncOptions{})
 	checkAsync(t, w)
 
 	e := &Event{}
@@ -267,7 +397,7 @@ func TestAsyncWorkerTerminate(t *testing.T) {
 	c := newCapturingCollector()
 	blocking := newBlockingCollector(c)
 	closing := newClosingCollector(blocking)
-	w := newWorker(closing, 20)
+	w := newWorker(closing, 20, AsyncOptions{})
 	checkAsync(t, w)
 
 	w.Send(&Event{})